@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"golang.org/x/term"
+)
+
+const usage = `usage: admin -s <server> -u <admin> <command> [username]
+
+commands:
+  list               List identities with their usage.
+  disable <username> Disable the identity and reject its tokens.
+  enable <username>  Enable the disabled identity.
+  logout <username>  Invalidate all tokens of the identity.
+  delete <username>  Delete the identity with all of its resources.
+  reset <username>   Set a new password, deleting all resources of the identity.
+
+The admin's password is read from GOPHKEEPER_ADMIN_PASSWORD or prompted.
+Grant the admin role with "gophkeeper-server admin grant <username>".`
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("")
+	var (
+		server    = flag.String("s", "", "Gophkeeper address")
+		username  = flag.String("u", os.Getenv("GOPHKEEPER_ADMIN_USERNAME"), "Admin's username")
+		yes       = flag.Bool("y", false, "Do not ask for confirmation")
+		clientTLS gophkeeper.ClientTLS
+	)
+	flag.StringVar(&clientTLS.CAFile, "ca", "", "PEM CA bundle to trust the server's certificate")
+	flag.StringVar(&clientTLS.CertFile, "cert", "", "PEM client certificate for mutual TLS")
+	flag.StringVar(&clientTLS.KeyFile, "key", "", "PEM private key of the client certificate")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		fmt.Fprintln(flag.CommandLine.Output())
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if *server == "" {
+		log.Fatal("missing -s flag")
+	}
+	if *username == "" {
+		log.Fatal("missing -u flag")
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var client, clientError = clientTLS.Client()
+	if clientError != nil {
+		log.Fatal(clientError)
+	}
+
+	var ctx = context.Background()
+	var keeper = &gophkeeper.RestGophkeeper{
+		Server: *server,
+		Client: client,
+	}
+	var password, passwordError = readPassword("Admin's password: ", "GOPHKEEPER_ADMIN_PASSWORD")
+	if passwordError != nil {
+		log.Fatal(passwordError)
+	}
+	var token, authenticateError = keeper.Authenticate(
+		ctx,
+		gophkeeper.Credential{
+			Username: *username,
+			Password: password,
+		},
+	)
+	if authenticateError != nil {
+		log.Fatal(authenticateError)
+	}
+	var administration, administrationError = keeper.Administration(ctx, token)
+	if administrationError != nil {
+		log.Fatal(administrationError)
+	}
+	if err := run(ctx, administration, flag.Args(), *yes); err != nil {
+		if errors.Is(err, gophkeeper.ErrForbidden) {
+			log.Fatalf("%s is not an admin", *username)
+		}
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, administration gophkeeper.Administration, args []string, yes bool) error {
+	var command = args[0]
+	if command == "list" {
+		if len(args) != 1 {
+			return errors.New("list expects no arguments")
+		}
+		return list(ctx, administration)
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("%s expects a username", command)
+	}
+	var username = args[1]
+	switch command {
+	case "disable":
+		return administration.SetDisabled(ctx, username, true)
+	case "enable":
+		return administration.SetDisabled(ctx, username, false)
+	case "logout":
+		return administration.Logout(ctx, username)
+	case "delete":
+		if !yes {
+			if err := confirm(username, "This deletes the identity and all of its resources."); err != nil {
+				return err
+			}
+		}
+		return administration.Delete(ctx, username)
+	case "reset":
+		if !yes {
+			if err := confirm(username, "This deletes all resources of the identity, as they are encrypted with its password."); err != nil {
+				return err
+			}
+		}
+		var password, passwordError = readNewPassword()
+		if passwordError != nil {
+			return passwordError
+		}
+		return administration.ResetCredential(ctx, username, password)
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+func list(ctx context.Context, administration gophkeeper.Administration) error {
+	var identities, identitiesError = administration.Identities(ctx)
+	if identitiesError != nil {
+		return identitiesError
+	}
+	var out = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(out, "USERNAME\tSTATE\tCREATED\tLAST LOGIN\tPIECES\tBLOBS\tPIECE BYTES\tBLOB BYTES")
+	for _, identity := range identities {
+		var state = "active"
+		if identity.Disabled {
+			state = "disabled"
+		}
+		if identity.Admin {
+			state += ",admin"
+		}
+		var lastLogin = "never"
+		if !identity.LastLogin.IsZero() {
+			lastLogin = identity.LastLogin.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(
+			out, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\n",
+			identity.Username, state,
+			identity.CreatedAt.Local().Format(time.DateTime), lastLogin,
+			identity.Pieces, identity.Blobs,
+			identity.PieceBytes, identity.BlobBytes,
+		)
+	}
+	return out.Flush()
+}
+
+// confirm asks to retype the username to confirm a destructive command.
+func confirm(username, warning string) error {
+	fmt.Println(warning)
+	fmt.Printf("Type %q to confirm: ", username)
+	var answer, answerError = bufio.NewReader(os.Stdin).ReadString('\n')
+	if answerError != nil {
+		return answerError
+	}
+	if strings.TrimSpace(answer) != username {
+		return errors.New("not confirmed")
+	}
+	return nil
+}
+
+// readPassword reads a password from the environment variable,
+// prompting for it if the variable is not set.
+func readPassword(prompt, env string) (string, error) {
+	if password, ok := os.LookupEnv(env); ok {
+		return password, nil
+	}
+	fmt.Print(prompt)
+	var password, passwordError = term.ReadPassword((int)(syscall.Stdin))
+	fmt.Println()
+	if passwordError != nil {
+		return "", passwordError
+	}
+	return (string)(password), nil
+}
+
+func readNewPassword() (string, error) {
+	fmt.Print("Type identity's new password: ")
+	var password1, password1Error = term.ReadPassword((int)(syscall.Stdin))
+	fmt.Println()
+	if password1Error != nil {
+		return "", password1Error
+	}
+	fmt.Print("Retype identity's new password: ")
+	var password2, password2Error = term.ReadPassword((int)(syscall.Stdin))
+	fmt.Println()
+	if password2Error != nil {
+		return "", password2Error
+	}
+	if !bytes.Equal(password1, password2) {
+		return "", errors.New("passwords do not match")
+	}
+	return (string)(password1), nil
+}
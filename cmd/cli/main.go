@@ -2,9 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log"
-	"net/http"
+	"os"
 
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 
@@ -14,21 +15,40 @@ import (
 func main() {
 	log.SetFlags(0)
 	log.SetPrefix("")
-	var server = flag.String("s", "", "Gophkeeper address")
+	var (
+		server    = flag.String("s", "", "Gophkeeper address")
+		clientTLS gophkeeper.ClientTLS
+	)
+	flag.StringVar(&clientTLS.CAFile, "ca", "", "PEM CA bundle to trust the server's certificate")
+	flag.StringVar(&clientTLS.CertFile, "cert", "", "PEM client certificate for mutual TLS")
+	flag.StringVar(&clientTLS.KeyFile, "key", "", "PEM private key of the client certificate")
 	flag.Parse()
 	if *server == "" {
 		log.Fatal("missing -s flag")
 	}
+	var client, clientError = clientTLS.Client()
+	if clientError != nil {
+		log.Fatal(clientError)
+	}
 
 	var application = cli.CLI{
 		Gophkeeper: &gophkeeper.RestGophkeeper{
 			Server: *server,
-			Client: http.Client{},
+			Client: client,
 		},
+		Server:      *server,
 		CommandLine: flag.Args(),
 	}
 	if err := application.Run(context.Background()); err != nil {
-		log.Println()
-		log.Fatal(err)
+		var cliError *cli.Error
+		if !errors.As(err, &cliError) {
+			log.Println()
+			log.Fatal(err)
+		}
+		if !cliError.Reported {
+			log.Println()
+			log.Println(cliError)
+		}
+		os.Exit(cliError.ExitCode)
 	}
 }
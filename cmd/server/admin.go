@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/kerelape/gophkeeper/cmd/server/config"
+	"github.com/kerelape/gophkeeper/internal/server/postgres"
+)
+
+const adminUsage = "usage: admin [grant|revoke] <username> [flags]"
+
+// admin runs the admin subcommand, which grants the admin role
+// to bootstrap the administration API.
+func admin(ctx context.Context, args []string) error {
+	if len(args) < 2 || (args[0] != "grant" && args[0] != "revoke") {
+		return errors.New(adminUsage)
+	}
+	var (
+		grant    = args[0] == "grant"
+		username = args[1]
+	)
+
+	var configuration config.Config
+	if err := config.Load(&configuration, args[2:]); err != nil {
+		return err
+	}
+	var database = configuration.Database
+	if err := database.Validate(); err != nil {
+		return err
+	}
+	var pool, poolError = postgres.Connect(ctx, database.DSN, database.MaxConnections, database.StatementCacheCapacity)
+	if poolError != nil {
+		return poolError
+	}
+	defer pool.Close()
+
+	if err := postgres.SetAdmin(ctx, pool, username, grant); err != nil {
+		return err
+	}
+	if grant {
+		log.Printf("granted admin role to %s\n", username)
+	} else {
+		log.Printf("revoked admin role from %s\n", username)
+	}
+	return nil
+}
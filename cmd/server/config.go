@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/kerelape/gophkeeper/cmd/server/config"
+)
+
+const configUsage = "usage: config print [flags]"
+
+// printConfig runs the config subcommand.
+func printConfig(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New(configUsage)
+	}
+	var configuration config.Config
+	if err := config.Load(&configuration, args[1:]); err != nil {
+		return err
+	}
+	if err := configuration.Print(os.Stdout); err != nil {
+		return err
+	}
+	return configuration.Validate()
+}
@@ -1,41 +1,391 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/kerelape/gophkeeper/internal/server/postgres"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/yaml.v3"
 )
 
+// fileEnv is the environment variable with the config file path.
+const fileEnv = "CONFIG_FILE"
+
 // Config is configuration for gophkeeper's server.
 type Config struct {
 	Rest struct {
-		Address       string   `env:"ADDRESS" env-default:":16355" env-description:"Address that REST api listens on."`
-		UseTLS        bool     `env:"USE_TLS" env-default:"true" env-description:"Use TLS or not"`
-		HostWhilelist []string `env:"HOST_WHITELIST" env-default:"" env-description:""`
-	} `env-prefix:"REST_"`
+		Address       string   `yaml:"address" toml:"address" env:"ADDRESS" env-default:":16355" env-description:"Address that REST api listens on."`
+		UseTLS        bool     `yaml:"use_tls" toml:"use_tls" env:"USE_TLS" env-default:"true" env-description:"Use TLS or not"`
+		HostWhilelist []string `yaml:"host_whitelist" toml:"host_whitelist" env:"HOST_WHITELIST" env-default:"" env-description:"Hosts to obtain Let's Encrypt certificates for"`
+		TLS           struct {
+			CertFile     string `yaml:"cert_file" toml:"cert_file" env:"CERT_FILE" env-default:"" env-description:"PEM certificate file, reloaded on change; empty uses Let's Encrypt"`
+			KeyFile      string `yaml:"key_file" toml:"key_file" env:"KEY_FILE" env-default:"" env-description:"PEM private key file of the certificate"`
+			SelfSigned   bool   `yaml:"self_signed" toml:"self_signed" env:"SELF_SIGNED" env-default:"false" env-description:"Generate a self-signed certificate, written to the certificate files if they do not exist"`
+			CacheDir     string `yaml:"cache_dir" toml:"cache_dir" env:"CACHE_DIR" env-default:"cache" env-description:"Directory Let's Encrypt certificates are cached in"`
+			ClientCAFile string `yaml:"client_ca_file" toml:"client_ca_file" env:"CLIENT_CA_FILE" env-default:"" env-description:"PEM CA bundle to require and verify client certificates against, empty disables mTLS"`
+		} `yaml:"tls" toml:"tls" env-prefix:"TLS_"`
+
+		ReadHeaderTimeout   time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"READ_HEADER_TIMEOUT" env-default:"10s" env-description:"Time allowed to read request headers"`
+		ReadTimeout         time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"READ_TIMEOUT" env-default:"1m" env-description:"Time allowed to read an entire request"`
+		WriteTimeout        time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"WRITE_TIMEOUT" env-default:"1m" env-description:"Time allowed to write a response"`
+		IdleTimeout         time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"IDLE_TIMEOUT" env-default:"2m" env-description:"Time a keep-alive connection may stay idle"`
+		MaxHeaderBytes      int           `yaml:"max_header_bytes" toml:"max_header_bytes" env:"MAX_HEADER_BYTES" env-default:"1048576" env-description:"Maximum size of request headers in bytes"`
+		BlobTransferTimeout time.Duration `yaml:"blob_transfer_timeout" toml:"blob_transfer_timeout" env:"BLOB_TRANSFER_TIMEOUT" env-default:"1h" env-description:"Time allowed for a blob upload or download, 0 means no limit"`
+		ShutdownTimeout     time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s" env-description:"Time in-flight requests may take to complete on shutdown"`
+	} `yaml:"rest" toml:"rest" env-prefix:"REST_"`
+	Log struct {
+		Level  string `yaml:"level" toml:"level" env:"LEVEL" env-default:"info" env-description:"Minimum log level: debug, info, warn or error"`
+		Format string `yaml:"format" toml:"format" env:"FORMAT" env-default:"text" env-description:"Log format: text or json"`
+	} `yaml:"log" toml:"log" env-prefix:"LOG_"`
+	Admin struct {
+		Address string `yaml:"address" toml:"address" env:"ADDRESS" env-default:"" env-description:"Address of a separate listener for health and metrics endpoints, empty to serve them with REST api."`
+	} `yaml:"admin" toml:"admin" env-prefix:"ADMIN_"`
 	Token struct {
-		Lifespan time.Duration `env:"LIFESPAN" env-description:"JWT Token lifespan in milliseconds" env-default:"15m"`
-		Secret   string        `env:"SECRET" env-description:"Base64 encoded JWT Token secret" env-required:"true"`
-	} `env-prefix:"TOKEN_"`
-	UsernameMinLength uint   `env:"USERNAME_MIN_LENGTH" env-description:"Username minimum length" env-default:"0"`
-	PasswordMinLength uint   `env:"PASSWORD_MIN_LENGTH" env-description:"Password minimum length" env-default:"0"`
-	DatabaseDSN       string `env:"DATABASE_DSN" env-description:"Database connection URL" env-required:"true"`
+		Lifespan time.Duration `yaml:"lifespan" toml:"lifespan" env:"LIFESPAN" env-description:"JWT Token lifespan" env-default:"15m"`
+		Secret   string        `yaml:"secret" toml:"secret" env:"SECRET" env-description:"Base64 encoded JWT Token secret (required)" secret:"true"`
+	} `yaml:"token" toml:"token" env-prefix:"TOKEN_"`
+	UsernameMinLength uint `yaml:"username_min_length" toml:"username_min_length" env:"USERNAME_MIN_LENGTH" env-description:"Username minimum length" env-default:"0"`
+	Password          struct {
+		MinLength     int     `yaml:"min_length" toml:"min_length" env:"MIN_LENGTH" env-description:"Password minimum length in characters" env-default:"0"`
+		MaxLength     int     `yaml:"max_length" toml:"max_length" env:"MAX_LENGTH" env-description:"Password maximum length in characters, 0 means no limit" env-default:"0"`
+		MinEntropy    float64 `yaml:"min_entropy" toml:"min_entropy" env:"MIN_ENTROPY" env-description:"Minimum estimated password entropy in bits" env-default:"0"`
+		RequireLower  bool    `yaml:"require_lower" toml:"require_lower" env:"REQUIRE_LOWER" env-description:"Require a lowercase letter in passwords" env-default:"false"`
+		RequireUpper  bool    `yaml:"require_upper" toml:"require_upper" env:"REQUIRE_UPPER" env-description:"Require an uppercase letter in passwords" env-default:"false"`
+		RequireDigit  bool    `yaml:"require_digit" toml:"require_digit" env:"REQUIRE_DIGIT" env-description:"Require a digit in passwords" env-default:"false"`
+		RequireSymbol bool    `yaml:"require_symbol" toml:"require_symbol" env:"REQUIRE_SYMBOL" env-description:"Require a symbol in passwords" env-default:"false"`
+		NoUsername    bool    `yaml:"no_username" toml:"no_username" env:"NO_USERNAME" env-description:"Reject passwords containing the username" env-default:"false"`
+		DenylistFile  string  `yaml:"denylist_file" toml:"denylist_file" env:"DENYLIST_FILE" env-description:"File of common passwords to reject, one per line"`
+	} `yaml:"password" toml:"password" env-prefix:"PASSWORD_"`
+	Registration struct {
+		Mode            string        `yaml:"mode" toml:"mode" env:"MODE" env-description:"Registration mode: open, invite or closed" env-default:"open"`
+		UsernamePattern string        `yaml:"username_pattern" toml:"username_pattern" env:"USERNAME_PATTERN" env-description:"Regular expression whole usernames must match, empty allows any"`
+		InviteLifespan  time.Duration `yaml:"invite_lifespan" toml:"invite_lifespan" env:"INVITE_LIFESPAN" env-description:"How long invites are valid" env-default:"72h"`
+		UsersInvite     bool          `yaml:"users_invite" toml:"users_invite" env:"USERS_INVITE" env-description:"Let identities that are not admins create invites" env-default:"false"`
+	} `yaml:"registration" toml:"registration" env-prefix:"REGISTRATION_"`
+	Database Database `yaml:"database" toml:"database" env-prefix:"DATABASE_"`
+	Blobs    struct {
+		Dir string `yaml:"dir" toml:"dir" env:"DIR" env-description:"Directory blob files are stored in" env-default:"blobs"`
+		GC  struct {
+			Interval    time.Duration `yaml:"interval" toml:"interval" env:"INTERVAL" env-description:"Interval between orphaned blob file collections, 0 disables it" env-default:"1h"`
+			GracePeriod time.Duration `yaml:"grace_period" toml:"grace_period" env:"GRACE_PERIOD" env-description:"Age after which unreferenced blob files are collected" env-default:"24h"`
+			Remove      bool          `yaml:"remove" toml:"remove" env:"REMOVE" env-description:"Remove unreferenced blob files instead of only reporting them" env-default:"true"`
+		} `yaml:"gc" toml:"gc" env-prefix:"GC_"`
+	} `yaml:"blobs" toml:"blobs" env-prefix:"BLOBS_"`
+	KDF struct {
+		BcryptCost    int `yaml:"bcrypt_cost" toml:"bcrypt_cost" env:"BCRYPT_COST" env-description:"Cost of password hashes of new identities" env-default:"10"`
+		KeyIterations int `yaml:"key_iterations" toml:"key_iterations" env:"KEY_ITERATIONS" env-description:"PBKDF2 iterations deriving keys of new resources" env-default:"4096"`
+	} `yaml:"kdf" toml:"kdf" env-prefix:"KDF_"`
+}
+
+// Database is configuration for gophkeeper's database.
+type Database struct {
+	DSN                    string `yaml:"dsn" toml:"dsn" env:"DSN" env-description:"Database connection URL (required)" secret:"true"`
+	MaxConnections         int32  `yaml:"max_connections" toml:"max_connections" env:"MAX_CONNECTIONS" env-description:"Maximum size of the database connection pool, 0 means default" env-default:"0"`
+	StatementCacheCapacity int    `yaml:"statement_cache_capacity" toml:"statement_cache_capacity" env:"STATEMENT_CACHE_CAPACITY" env-description:"Prepared statements cached per connection, 0 disables caching" env-default:"512"`
+}
+
+// Read reads the config and validates it.
+//
+// Values are taken from defaults, the config file, environment
+// and flags in args, each overriding the previous one. The config
+// file is set with the -config flag or the CONFIG_FILE variable.
+func Read(config *Config, args []string) error {
+	if err := Load(config, args); err != nil {
+		return err
+	}
+	return config.Validate()
 }
 
-// Read reads the config.
-func Read(config *Config) error {
-	if err := cleanenv.ReadEnv(config); err != nil {
+// Load reads the config like Read but does not validate it.
+func Load(config *Config, args []string) error {
+	var flags = flag.NewFlagSet("server", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return LoadFlags(config, flags, args)
+}
+
+// LoadFlags reads the config like Load, its flags are added to flags
+// so that a subcommand's own flags are parsed with them.
+func LoadFlags(config *Config, flags *flag.FlagSet, args []string) error {
+	var fields, fieldsError = fieldsOf(config)
+	if fieldsError != nil {
+		return fieldsError
+	}
+
+	var file = flags.String("config", os.Getenv(fileEnv), "Config file, YAML or TOML")
+	for _, f := range fields {
+		flags.Var(&flagValue{isBool: f.value.Kind() == reflect.Bool}, f.flag, f.description)
+	}
+	if err := flags.Parse(args); err != nil {
 		return fmt.Errorf("read config: %w", err)
 	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("read config: unexpected argument %q", flags.Arg(0))
+	}
+
+	for _, f := range fields {
+		if f.defaultValue == "" {
+			continue
+		}
+		if err := f.set(f.defaultValue); err != nil {
+			return fmt.Errorf("read config: default of %s: %w", f.path, err)
+		}
+	}
+	if *file != "" {
+		if err := readFile(*file, config); err != nil {
+			return fmt.Errorf("read config: %w", err)
+		}
+	}
+	for _, f := range fields {
+		if value, ok := os.LookupEnv(f.env); ok {
+			if err := f.set(value); err != nil {
+				return fmt.Errorf("read config: %s: %w", f.env, err)
+			}
+		}
+	}
+	var flagError error
+	flags.Visit(func(set *flag.Flag) {
+		for _, f := range fields {
+			if f.flag == set.Name {
+				if err := f.set(set.Value.String()); err != nil {
+					flagError = errors.Join(flagError, fmt.Errorf("-%s: %w", f.flag, err))
+				}
+			}
+		}
+	})
+	if flagError != nil {
+		return fmt.Errorf("read config: %w", flagError)
+	}
+	return nil
+}
+
+func readFile(file string, config *Config) error {
+	var content, openError = os.Open(file)
+	if openError != nil {
+		return openError
+	}
+	defer content.Close()
+	var parseError error
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		var decoder = yaml.NewDecoder(content)
+		decoder.KnownFields(true)
+		parseError = decoder.Decode(config)
+		if errors.Is(parseError, io.EOF) {
+			parseError = nil
+		}
+	case ".toml":
+		parseError = cleanenv.ParseTOML(content, config)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", file)
+	}
+	if parseError != nil {
+		return fmt.Errorf("parse %s: %w", file, parseError)
+	}
+	return nil
+}
+
+// Validate validates the config, reporting every invalid value.
+func (c *Config) Validate() error {
+	var errs []error
+	var fail = func(path, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	if c.Rest.Address == "" {
+		fail("rest.address", "must not be empty")
+	}
+	if (c.Rest.TLS.CertFile == "") != (c.Rest.TLS.KeyFile == "") {
+		fail("rest.tls", "cert_file and key_file must be set together")
+	}
+	if c.Rest.UseTLS && c.Rest.TLS.CertFile == "" && !c.Rest.TLS.SelfSigned && len(c.Rest.HostWhilelist) == 0 {
+		fail("rest.host_whitelist", "must not be empty to obtain Let's Encrypt certificates")
+	}
+	for path, value := range map[string]time.Duration{
+		"rest.read_header_timeout":   c.Rest.ReadHeaderTimeout,
+		"rest.read_timeout":          c.Rest.ReadTimeout,
+		"rest.write_timeout":         c.Rest.WriteTimeout,
+		"rest.idle_timeout":          c.Rest.IdleTimeout,
+		"rest.blob_transfer_timeout": c.Rest.BlobTransferTimeout,
+		"rest.shutdown_timeout":      c.Rest.ShutdownTimeout,
+		"blobs.gc.interval":          c.Blobs.GC.Interval,
+		"blobs.gc.grace_period":      c.Blobs.GC.GracePeriod,
+	} {
+		if value < 0 {
+			fail(path, "must not be negative, got %s", value)
+		}
+	}
+	if c.Rest.MaxHeaderBytes < 0 {
+		fail("rest.max_header_bytes", "must not be negative, got %d", c.Rest.MaxHeaderBytes)
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText(([]byte)(c.Log.Level)); err != nil {
+		fail("log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		fail("log.format", "must be text or json, got %q", c.Log.Format)
+	}
+
+	if c.Token.Lifespan <= 0 {
+		fail("token.lifespan", "must be positive, got %s", c.Token.Lifespan)
+	}
+	if c.Token.Secret == "" {
+		fail("token.secret", "is required")
+	} else if _, err := base64.RawStdEncoding.DecodeString(c.Token.Secret); err != nil {
+		fail("token.secret", "must be base64 without padding: %s", err.Error())
+	}
+
+	if _, err := postgres.ParseRegistrationMode(c.Registration.Mode); err != nil {
+		fail("registration.mode", "must be open, invite or closed, got %q", c.Registration.Mode)
+	}
+	if _, err := c.UsernamePattern(); err != nil {
+		fail("registration.username_pattern", "%s", err.Error())
+	}
+	if c.Password.MinLength < 0 {
+		fail("password.min_length", "must not be negative, got %d", c.Password.MinLength)
+	}
+	if c.Password.MaxLength < 0 {
+		fail("password.max_length", "must not be negative, got %d", c.Password.MaxLength)
+	} else if c.Password.MaxLength > 0 && c.Password.MaxLength < c.Password.MinLength {
+		fail("password.max_length", "must not be less than min_length, got %d", c.Password.MaxLength)
+	}
+	if c.Password.MinEntropy < 0 {
+		fail("password.min_entropy", "must not be negative, got %g", c.Password.MinEntropy)
+	}
+	if c.Password.DenylistFile != "" {
+		if _, err := os.Stat(c.Password.DenylistFile); err != nil {
+			fail("password.denylist_file", "%s", err.Error())
+		}
+	}
+
+	if c.Registration.InviteLifespan <= 0 {
+		fail("registration.invite_lifespan", "must be positive, got %s", c.Registration.InviteLifespan)
+	}
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Blobs.Dir == "" {
+		fail("blobs.dir", "must not be empty")
+	}
+
+	if c.KDF.BcryptCost < bcrypt.MinCost || c.KDF.BcryptCost > bcrypt.MaxCost {
+		fail("kdf.bcrypt_cost", "must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.KDF.BcryptCost)
+	}
+	if c.KDF.KeyIterations < minKeyIterations {
+		fail("kdf.key_iterations", "must be at least %d, got %d", minKeyIterations, c.KDF.KeyIterations)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
 	return nil
 }
 
-// Description returns config description.
-func (c *Config) Description() string {
-	var description, err = cleanenv.GetDescription(c, nil)
-	if err != nil {
-		panic(err)
+// UsernamePattern returns the compiled pattern
+// of usernames, nil if any username is allowed.
+func (c *Config) UsernamePattern() (*regexp.Regexp, error) {
+	if c.Registration.UsernamePattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("^(?:" + c.Registration.UsernamePattern + ")$")
+}
+
+// PasswordPolicy returns the password policy
+// with the denylist read from its file.
+func (c *Config) PasswordPolicy() (gophkeeper.PasswordPolicy, error) {
+	var policy = gophkeeper.PasswordPolicy{
+		MinLength:     c.Password.MinLength,
+		MaxLength:     c.Password.MaxLength,
+		MinEntropy:    c.Password.MinEntropy,
+		RequireLower:  c.Password.RequireLower,
+		RequireUpper:  c.Password.RequireUpper,
+		RequireDigit:  c.Password.RequireDigit,
+		RequireSymbol: c.Password.RequireSymbol,
+		NoUsername:    c.Password.NoUsername,
+	}
+	if c.Password.DenylistFile == "" {
+		return policy, nil
+	}
+	var file, openError = os.Open(c.Password.DenylistFile)
+	if openError != nil {
+		return policy, openError
+	}
+	defer file.Close()
+	var denylist, readError = gophkeeper.ReadPasswordDenylist(file)
+	if readError != nil {
+		return policy, fmt.Errorf("read %s: %w", c.Password.DenylistFile, readError)
+	}
+	policy.Denylist = denylist
+	return policy, nil
+}
+
+// minKeyIterations is the least number of PBKDF2 iterations accepted.
+const minKeyIterations = 1000
+
+// Validate validates the database config.
+func (d *Database) Validate() error {
+	var errs []error
+	if d.DSN == "" {
+		errs = append(errs, errors.New("database.dsn: is required"))
+	}
+	if d.MaxConnections < 0 {
+		errs = append(errs, fmt.Errorf("database.max_connections: must not be negative, got %d", d.MaxConnections))
+	}
+	if d.StatementCacheCapacity < 0 {
+		errs = append(errs, fmt.Errorf("database.statement_cache_capacity: must not be negative, got %d", d.StatementCacheCapacity))
+	}
+	return errors.Join(errs...)
+}
+
+// Print writes the config as YAML with secrets redacted.
+func (c *Config) Print(out io.Writer) error {
+	var redacted = *c
+	var fields, fieldsError = fieldsOf(&redacted)
+	if fieldsError != nil {
+		return fieldsError
+	}
+	for _, f := range fields {
+		if f.secret {
+			f.redact()
+		}
+	}
+	var encoder = yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&redacted); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// Usage writes every configuration value with its flag,
+// environment variable, config file key and default.
+func Usage(out io.Writer) error {
+	var fields, fieldsError = fieldsOf(new(Config))
+	if fieldsError != nil {
+		return fieldsError
 	}
-	return description
+	fmt.Fprintf(out, "  -config string\n    \tConfig file, YAML or TOML (env %s)\n", fileEnv)
+	for _, f := range fields {
+		fmt.Fprintf(out, "  -%s value\n    \t%s (env %s, file %s", f.flag, f.description, f.env, f.path)
+		if f.defaultValue != "" {
+			fmt.Fprintf(out, ", default %q", f.defaultValue)
+		}
+		fmt.Fprintln(out, ")")
+	}
+	return nil
 }
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redacted replaces secret values in printed config.
+const redacted = "REDACTED"
+
+// dsnPassword matches the password of a key/value connection string.
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// field is a single configuration value.
+type field struct {
+	path         string // path is the field's key path in the config file.
+	env          string
+	flag         string
+	defaultValue string
+	description  string
+	secret       bool
+
+	value reflect.Value
+}
+
+// fieldsOf returns every value of config, which must be a pointer to a struct.
+func fieldsOf(config any) ([]field, error) {
+	var value = reflect.ValueOf(config)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be a pointer to a struct, got %T", config)
+	}
+	return collectFields(value.Elem(), "", ""), nil
+}
+
+func collectFields(value reflect.Value, path, envPrefix string) []field {
+	var fields []field
+	for i := 0; i < value.NumField(); i++ {
+		var (
+			structField = value.Type().Field(i)
+			key         = structField.Tag.Get("yaml")
+			fieldPath   = key
+		)
+		if path != "" {
+			fieldPath = path + "." + key
+		}
+		if structField.Type.Kind() == reflect.Struct && structField.Type != reflect.TypeOf(time.Time{}) {
+			fields = append(fields, collectFields(value.Field(i), fieldPath, envPrefix+structField.Tag.Get("env-prefix"))...)
+			continue
+		}
+		var env = envPrefix + structField.Tag.Get("env")
+		fields = append(fields, field{
+			path:         fieldPath,
+			env:          env,
+			flag:         strings.ToLower(strings.ReplaceAll(env, "_", "-")),
+			defaultValue: structField.Tag.Get("env-default"),
+			description:  structField.Tag.Get("env-description"),
+			secret:       structField.Tag.Get("secret") == "true",
+			value:        value.Field(i),
+		})
+	}
+	return fields
+}
+
+// set parses raw into the field.
+func (f *field) set(raw string) error {
+	switch f.value.Interface().(type) {
+	case time.Duration:
+		var duration, err = time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt((int64)(duration))
+		return nil
+	}
+	switch f.value.Kind() {
+	case reflect.String:
+		f.value.SetString(raw)
+	case reflect.Bool:
+		var b, err = strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i, err = strconv.ParseInt(raw, 10, f.value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u, err = strconv.ParseUint(raw, 10, f.value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var x, err = strconv.ParseFloat(raw, f.value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(x)
+	case reflect.Slice:
+		if f.value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", f.value.Type())
+		}
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		f.value.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// redact hides the secret in the field, keeping
+// the rest of a connection string readable.
+func (f *field) redact() {
+	var value = f.value.String()
+	if value == "" {
+		return
+	}
+	if f.env != "DATABASE_DSN" {
+		f.value.SetString(redacted)
+		return
+	}
+	if dsn, err := url.Parse(value); err == nil && dsn.Scheme != "" {
+		if _, ok := dsn.User.Password(); ok {
+			dsn.User = url.UserPassword(dsn.User.Username(), redacted)
+		}
+		var query = dsn.Query()
+		if query.Has("password") {
+			query.Set("password", redacted)
+			dsn.RawQuery = query.Encode()
+		}
+		f.value.SetString(dsn.String())
+		return
+	}
+	f.value.SetString(dsnPassword.ReplaceAllString(value, "${1}"+redacted))
+}
+
+// flagValue is a raw flag value parsed into its field
+// after the lower precedence sources are applied.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+// String implements flag.Value.
+func (v *flagValue) String() string {
+	return v.value
+}
+
+// Set implements flag.Value.
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be set without a value.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/kerelape/gophkeeper/cmd/server/config"
+	"github.com/kerelape/gophkeeper/internal/server/postgres"
+)
+
+// collectBlobs runs the gc subcommand.
+func collectBlobs(ctx context.Context, args []string) error {
+	var flags = flag.NewFlagSet("gc", flag.ContinueOnError)
+	var (
+		gracePeriod = flags.Duration("grace", 24*time.Hour, "Age after which unreferenced blob files are collected")
+		remove      = flags.Bool("delete", false, "Delete unreferenced blob files instead of only reporting them")
+	)
+
+	var configuration config.Config
+	if err := config.LoadFlags(&configuration, flags, args); err != nil {
+		return err
+	}
+	var database = configuration.Database
+	if err := database.Validate(); err != nil {
+		return err
+	}
+	var blobsDir, blobsDirError = filepath.Abs(configuration.Blobs.Dir)
+	if blobsDirError != nil {
+		return blobsDirError
+	}
+	var pool, poolError = postgres.Connect(ctx, database.DSN, database.MaxConnections, database.StatementCacheCapacity)
+	if poolError != nil {
+		return poolError
+	}
+	defer pool.Close()
+
+	var report, reportError = postgres.CollectBlobs(ctx, pool, blobsDir, *gracePeriod, *remove)
+	if reportError != nil {
+		return reportError
+	}
+	report.Log()
+	log.Printf(
+		"%d unreferenced, %d removed, %d missing\n",
+		len(report.Unreferenced), len(report.Removed), len(report.Missing),
+	)
+	return nil
+}
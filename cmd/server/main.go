@@ -1,44 +1,127 @@
 package main
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
-	"path"
+	"path/filepath"
 
 	"github.com/kerelape/gophkeeper/cmd/server/config"
 	"github.com/kerelape/gophkeeper/internal/server"
+	"github.com/kerelape/gophkeeper/internal/server/postgres"
 	"github.com/pior/runnable"
 )
 
 func main() {
 	log.SetPrefix("[GOPHKEEPER] ")
+	if len(os.Args) > 1 {
+		var subcommands = map[string]func(context.Context, []string) error{
+			"migrate": migrate,
+			"gc":      collectBlobs,
+			"config":  printConfig,
+			"admin":   admin,
+		}
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			if err := subcommand(context.Background(), os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
 	var configuration config.Config
-	if err := config.Read(&configuration); err != nil {
-		log.Println(configuration.Description())
-		os.Exit(1)
+	if err := config.Read(&configuration, os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			config.Usage(os.Stderr)
+			os.Exit(2)
+		}
+		log.Fatal(err)
+	}
+	if err := setupLogging(configuration); err != nil {
+		log.Fatalf("failed to set up logging: %s", err.Error())
 	}
 	var secret, decodeSecretError = base64.RawStdEncoding.DecodeString(configuration.Token.Secret)
 	if decodeSecretError != nil {
 		log.Fatalf("failed to parse token secret: %s", decodeSecretError.Error())
 	}
-	var wd, wdError = os.Getwd()
-	if wdError != nil {
-		log.Fatalf(wdError.Error())
+	var blobsDir, blobsDirError = filepath.Abs(configuration.Blobs.Dir)
+	if blobsDirError != nil {
+		log.Fatalf(blobsDirError.Error())
+	}
+	var usernamePattern, usernamePatternError = configuration.UsernamePattern()
+	if usernamePatternError != nil {
+		log.Fatalf("failed to parse username pattern: %s", usernamePatternError.Error())
+	}
+	var passwordPolicy, passwordPolicyError = configuration.PasswordPolicy()
+	if passwordPolicyError != nil {
+		log.Fatalf("failed to read password policy: %s", passwordPolicyError.Error())
+	}
+	var registrationMode, registrationModeError = postgres.ParseRegistrationMode(configuration.Registration.Mode)
+	if registrationModeError != nil {
+		log.Fatal(registrationModeError)
 	}
 	var gophkeeper = server.Server{
 		RestAddress:       configuration.Rest.Address,
 		RestUseTLS:        configuration.Rest.UseTLS,
 		RestHostWhilelist: configuration.Rest.HostWhilelist,
+		RestTLSCertFile:   configuration.Rest.TLS.CertFile,
+		RestTLSKeyFile:    configuration.Rest.TLS.KeyFile,
+		RestTLSSelfSigned: configuration.Rest.TLS.SelfSigned,
+		RestTLSCacheDir:   configuration.Rest.TLS.CacheDir,
+		RestTLSClientCA:   configuration.Rest.TLS.ClientCAFile,
+
+		RestReadHeaderTimeout:   configuration.Rest.ReadHeaderTimeout,
+		RestReadTimeout:         configuration.Rest.ReadTimeout,
+		RestWriteTimeout:        configuration.Rest.WriteTimeout,
+		RestIdleTimeout:         configuration.Rest.IdleTimeout,
+		RestMaxHeaderBytes:      configuration.Rest.MaxHeaderBytes,
+		RestBlobTransferTimeout: configuration.Rest.BlobTransferTimeout,
+		RestShutdownTimeout:     configuration.Rest.ShutdownTimeout,
+
+		AdminAddress: configuration.Admin.Address,
 
-		DatabaseDSN: configuration.DatabaseDSN,
-		BlobsDir:    path.Join(wd, "blobs"),
+		DatabaseDSN:                    configuration.Database.DSN,
+		DatabaseMaxConnections:         configuration.Database.MaxConnections,
+		DatabaseStatementCacheCapacity: configuration.Database.StatementCacheCapacity,
+		BlobsDir:                       blobsDir,
+		BlobsGCInterval:                configuration.Blobs.GC.Interval,
+		BlobsGCGracePeriod:             configuration.Blobs.GC.GracePeriod,
+		BlobsGCRemove:                  configuration.Blobs.GC.Remove,
 
 		TokenSecret:   secret,
 		TokenLifespan: configuration.Token.Lifespan,
 
+		BcryptCost:    configuration.KDF.BcryptCost,
+		KeyIterations: configuration.KDF.KeyIterations,
+
 		UsernameMinLength: configuration.UsernameMinLength,
-		PasswordMinLength: configuration.PasswordMinLength,
+		PasswordPolicy:    passwordPolicy,
+
+		RegistrationMode:            registrationMode,
+		RegistrationUsernamePattern: usernamePattern,
+		RegistrationInviteLifespan:  configuration.Registration.InviteLifespan,
+		RegistrationUsersInvite:     configuration.Registration.UsersInvite,
 	}
 	runnable.Run(&gophkeeper)
 }
+
+func setupLogging(configuration config.Config) error {
+	var level slog.Level
+	if err := level.UnmarshalText(([]byte)(configuration.Log.Level)); err != nil {
+		return err
+	}
+	var options = &slog.HandlerOptions{Level: level}
+	switch configuration.Log.Format {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, options)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, options)))
+	default:
+		return fmt.Errorf("unknown log format: %s", configuration.Log.Format)
+	}
+	return nil
+}
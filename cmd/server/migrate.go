@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/kerelape/gophkeeper/cmd/server/config"
+	"github.com/kerelape/gophkeeper/internal/server/postgres"
+)
+
+const migrateUsage = "usage: migrate [up|status|dry-run] [flags]"
+
+// migrate runs the migrate subcommand.
+func migrate(ctx context.Context, args []string) error {
+	var action = "up"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	var configuration config.Config
+	if err := config.Load(&configuration, args); err != nil {
+		return err
+	}
+	var database = configuration.Database
+	if err := database.Validate(); err != nil {
+		return err
+	}
+	var pool, poolError = postgres.Connect(ctx, database.DSN, database.MaxConnections, database.StatementCacheCapacity)
+	if poolError != nil {
+		return poolError
+	}
+	defer pool.Close()
+
+	switch action {
+	case "up", "dry-run":
+		var dryRun = action == "dry-run"
+		var migrations, migrateError = postgres.Migrate(ctx, pool, dryRun)
+		if migrateError != nil {
+			return migrateError
+		}
+		if len(migrations) == 0 {
+			log.Println("database schema is up to date")
+			return nil
+		}
+		for _, migration := range migrations {
+			if dryRun {
+				log.Printf("would apply migration %04d_%s\n", migration.Version, migration.Name)
+			} else {
+				log.Printf("applied migration %04d_%s\n", migration.Version, migration.Name)
+			}
+		}
+		return nil
+	case "status":
+		var statuses, statusError = postgres.MigrationsStatus(ctx, pool)
+		if statusError != nil {
+			return statusError
+		}
+		for _, status := range statuses {
+			var state = "pending"
+			if status.Applied {
+				state = fmt.Sprintf("applied at %s", status.AppliedAt.Format("2006-01-02 15:04:05 MST"))
+			}
+			log.Printf("%04d_%s: %s\n", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
@@ -0,0 +1,266 @@
+// Package agent holds an unlocked gophkeeper session in memory and
+// serves it to local clients over a Unix socket, like ssh-agent.
+//
+// A client writes a request as a line of JSON and reads a response line,
+// a connection may carry any number of requests:
+//
+//	{"op": "status"}
+//	{"op": "unlock", "session": {"server": ..., "username": ..., "token": ..., "expires_at": ..., "vault_password": ...}}
+//	{"op": "session"}
+//	{"op": "lock"}
+//
+// Every response has "locked", the session is returned by "session" and
+// by "status" without the token and the vault password, an error is
+// returned as "error". The session is locked when it is idle for
+// the timeout, when its token expires and on "lock".
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the default idle timeout of the agent.
+const DefaultTimeout = 15 * time.Minute
+
+// maxRequestSize limits a request line.
+const maxRequestSize = 64 * 1024
+
+// Op is an operation requested from the agent.
+type Op string
+
+// Operations of the agent.
+const (
+	OpStatus  Op = "status"
+	OpUnlock  Op = "unlock"
+	OpSession Op = "session"
+	OpLock    Op = "lock"
+)
+
+// Session is an unlocked session.
+type Session struct {
+	Server        string    `json:"server"`
+	Username      string    `json:"username"`
+	Token         string    `json:"token,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	VaultPassword string    `json:"vault_password,omitempty"`
+}
+
+// Request is a request to the agent.
+type Request struct {
+	Op      Op       `json:"op"`
+	Session *Session `json:"session,omitempty"`
+}
+
+// Response is a response of the agent.
+type Response struct {
+	Locked  bool     `json:"locked"`
+	Session *Session `json:"session,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// Agent holds a session.
+type Agent struct {
+	// Timeout is how long the session is held without being used.
+	Timeout time.Duration
+
+	mu      sync.Mutex
+	session *lockedSession
+	timer   *time.Timer
+	touches uint64
+}
+
+// lockedSession is a session with its secrets in locked memory.
+type lockedSession struct {
+	server    string
+	username  string
+	expiresAt time.Time
+
+	memory *memory
+	token  int // token is the length of the token at the beginning of the memory.
+}
+
+// Serve serves requests from the listener until the context is done,
+// the session is locked when it returns.
+func (a *Agent) Serve(ctx context.Context, listener net.Listener) error {
+	defer a.lock()
+	var stop = context.AfterFunc(ctx, func() {
+		listener.Close()
+	})
+	defer stop()
+	var connections sync.WaitGroup
+	defer connections.Wait()
+	for {
+		var connection, acceptError = listener.Accept()
+		if acceptError != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return acceptError
+		}
+		connections.Add(1)
+		go func() {
+			defer connections.Done()
+			a.serve(ctx, connection)
+		}()
+	}
+}
+
+func (a *Agent) serve(ctx context.Context, connection net.Conn) {
+	defer connection.Close()
+	var stop = context.AfterFunc(ctx, func() {
+		connection.Close()
+	})
+	defer stop()
+	var (
+		scanner = bufio.NewScanner(connection)
+		encoder = json.NewEncoder(connection)
+	)
+	scanner.Buffer(make([]byte, 0, 4096), maxRequestSize)
+	for scanner.Scan() {
+		var request Request
+		var response Response
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			response = Response{Locked: a.locked(), Error: "malformed request"}
+		} else {
+			response = a.Handle(request)
+		}
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+// Handle handles the request.
+func (a *Agent) Handle(request Request) Response {
+	switch request.Op {
+	case OpStatus:
+		var session, unlocked = a.get(false)
+		if !unlocked {
+			return Response{Locked: true}
+		}
+		session.Token, session.VaultPassword = "", ""
+		return Response{Session: &session}
+	case OpUnlock:
+		if request.Session == nil {
+			return Response{Locked: a.locked(), Error: "session is missing"}
+		}
+		if err := a.unlock(*request.Session); err != nil {
+			return Response{Locked: a.locked(), Error: err.Error()}
+		}
+		return Response{}
+	case OpSession:
+		var session, unlocked = a.get(true)
+		if !unlocked {
+			return Response{Locked: true}
+		}
+		return Response{Session: &session}
+	case OpLock:
+		a.lock()
+		return Response{Locked: true}
+	default:
+		return Response{Locked: a.locked(), Error: "unknown operation"}
+	}
+}
+
+func (a *Agent) unlock(session Session) error {
+	if session.Token == "" || !time.Now().Before(session.ExpiresAt) {
+		return errors.New("session is expired")
+	}
+	var memory, memoryError = allocate(len(session.Token) + len(session.VaultPassword))
+	if memoryError != nil {
+		return memoryError
+	}
+	copy(memory.bytes, session.Token)
+	copy(memory.bytes[len(session.Token):], session.VaultPassword)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.release()
+	a.session = &lockedSession{
+		server:    session.Server,
+		username:  session.Username,
+		expiresAt: session.ExpiresAt,
+		memory:    memory,
+		token:     len(session.Token),
+	}
+	a.touch()
+	return nil
+}
+
+// get returns the session, use resets the idle timeout.
+func (a *Agent) get(use bool) (Session, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.session == nil {
+		return Session{}, false
+	}
+	if !time.Now().Before(a.session.expiresAt) {
+		a.release()
+		return Session{}, false
+	}
+	if use {
+		a.touch()
+	}
+	var session = Session{
+		Server:        a.session.server,
+		Username:      a.session.username,
+		Token:         (string)(a.session.memory.bytes[:a.session.token]),
+		ExpiresAt:     a.session.expiresAt,
+		VaultPassword: (string)(a.session.memory.bytes[a.session.token:]),
+	}
+	return session, true
+}
+
+func (a *Agent) locked() bool {
+	var _, unlocked = a.get(false)
+	return !unlocked
+}
+
+func (a *Agent) lock() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.release()
+}
+
+// touch restarts the timer locking the session
+// after the idle timeout or when the token expires.
+func (a *Agent) touch() {
+	var timeout = a.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	if untilExpiry := time.Until(a.session.expiresAt); untilExpiry < timeout {
+		timeout = untilExpiry
+	}
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.touches++
+	var touch = a.touches
+	a.timer = time.AfterFunc(timeout, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		// The session may be used while the timer fires.
+		if a.touches == touch {
+			a.release()
+		}
+	})
+}
+
+// release wipes the session, the caller must hold the mutex.
+func (a *Agent) release() {
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	if a.session != nil {
+		a.session.memory.release()
+		a.session = nil
+	}
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serve(t *testing.T, agent *Agent) *Client {
+	var path = filepath.Join(t.TempDir(), "agent", "agent.sock")
+	var listener, listenError = Listen(path)
+	if !assert.Nil(t, listenError) {
+		t.FailNow()
+	}
+	var info, statError = os.Stat(path)
+	assert.Nil(t, statError)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	var done = make(chan error)
+	go func() {
+		done <- agent.Serve(ctx, listener)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.Nil(t, <-done)
+	})
+	return &Client{Socket: path}
+}
+
+func TestAgent(t *testing.T) {
+	var session = Session{
+		Server:        "https://localhost:16355",
+		Username:      "alice",
+		Token:         "token",
+		ExpiresAt:     time.Now().Add(time.Hour),
+		VaultPassword: "hunter2",
+	}
+	t.Run("UnlockAndLock", func(t *testing.T) {
+		var client = serve(t, &Agent{})
+		var _, lockedError = client.Session(context.Background())
+		assert.ErrorIs(t, lockedError, ErrLocked)
+
+		assert.Nil(t, client.Unlock(context.Background(), session))
+		var held, heldError = client.Session(context.Background())
+		assert.Nil(t, heldError)
+		assert.Equal(t, session.Token, held.Token)
+		assert.Equal(t, session.VaultPassword, held.VaultPassword)
+
+		var status, statusError = client.Status(context.Background())
+		assert.Nil(t, statusError)
+		assert.Equal(t, "alice", status.Username)
+		assert.Empty(t, status.Token)
+		assert.Empty(t, status.VaultPassword)
+
+		assert.Nil(t, client.Lock(context.Background()))
+		_, lockedError = client.Session(context.Background())
+		assert.ErrorIs(t, lockedError, ErrLocked)
+	})
+	t.Run("IdleTimeout", func(t *testing.T) {
+		var client = serve(t, &Agent{Timeout: 50 * time.Millisecond})
+		assert.Nil(t, client.Unlock(context.Background(), session))
+		time.Sleep(30 * time.Millisecond)
+		var _, usedError = client.Session(context.Background())
+		assert.Nil(t, usedError)
+		time.Sleep(30 * time.Millisecond)
+		_, usedError = client.Session(context.Background())
+		assert.Nil(t, usedError, "use must reset the timeout")
+		time.Sleep(100 * time.Millisecond)
+		var _, lockedError = client.Session(context.Background())
+		assert.ErrorIs(t, lockedError, ErrLocked)
+	})
+	t.Run("ExpiredSession", func(t *testing.T) {
+		var client = serve(t, &Agent{})
+		var expired = session
+		expired.ExpiresAt = time.Now().Add(-time.Second)
+		assert.NotNil(t, client.Unlock(context.Background(), expired))
+	})
+	t.Run("LinkedDirectory", func(t *testing.T) {
+		var target = filepath.Join(t.TempDir(), "target")
+		assert.Nil(t, os.Mkdir(target, 0o700))
+		var link = filepath.Join(t.TempDir(), "link")
+		assert.Nil(t, os.Symlink(target, link))
+		var _, listenError = Listen(filepath.Join(link, "agent.sock"))
+		assert.ErrorContains(t, listenError, "not a directory")
+	})
+	t.Run("AlreadyRunning", func(t *testing.T) {
+		var client = serve(t, &Agent{})
+		var _, listenError = Listen(client.Socket)
+		assert.NotNil(t, listenError)
+	})
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+)
+
+// ErrLocked is returned when the agent holds no session.
+var ErrLocked = errors.New("agent is locked")
+
+// requestTimeout limits a request to the agent.
+const requestTimeout = 5 * time.Second
+
+// Client is a client of the agent.
+type Client struct {
+	Socket string
+}
+
+// Status returns the session of the agent without secrets.
+func (c *Client) Status(ctx context.Context) (Session, error) {
+	return c.session(ctx, OpStatus)
+}
+
+// Session returns the session of the agent.
+func (c *Client) Session(ctx context.Context) (Session, error) {
+	return c.session(ctx, OpSession)
+}
+
+// Unlock makes the agent hold the session.
+func (c *Client) Unlock(ctx context.Context, session Session) error {
+	var _, err = c.do(ctx, Request{Op: OpUnlock, Session: &session})
+	return err
+}
+
+// Lock makes the agent forget the session.
+func (c *Client) Lock(ctx context.Context) error {
+	var _, err = c.do(ctx, Request{Op: OpLock})
+	return err
+}
+
+func (c *Client) session(ctx context.Context, op Op) (Session, error) {
+	var response, err = c.do(ctx, Request{Op: op})
+	if err != nil {
+		return Session{}, err
+	}
+	if response.Locked || response.Session == nil {
+		return Session{}, ErrLocked
+	}
+	return *response.Session, nil
+}
+
+func (c *Client) do(ctx context.Context, request Request) (Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	var connection, dialError = dialer.DialContext(ctx, "unix", c.Socket)
+	if dialError != nil {
+		return Response{}, dialError
+	}
+	defer connection.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		connection.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(connection).Encode(request); err != nil {
+		return Response{}, err
+	}
+	var response Response
+	if err := json.NewDecoder(bufio.NewReader(connection)).Decode(&response); err != nil {
+		return Response{}, err
+	}
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package agent
+
+// memory is memory of secrets, it cannot be locked on this platform.
+type memory struct {
+	bytes []byte
+}
+
+func allocate(size int) (*memory, error) {
+	return &memory{bytes: make([]byte, size)}, nil
+}
+
+// release wipes the memory.
+func (m *memory) release() {
+	clear(m.bytes)
+	m.bytes = nil
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package agent
+
+import "golang.org/x/sys/unix"
+
+// memory is memory locked in RAM, so that it is never swapped out.
+type memory struct {
+	bytes []byte
+}
+
+func allocate(size int) (*memory, error) {
+	if size == 0 {
+		return &memory{}, nil
+	}
+	var bytes, mmapError = unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if mmapError != nil {
+		return nil, mmapError
+	}
+	if err := unix.Mlock(bytes); err != nil {
+		unix.Munmap(bytes)
+		return nil, err
+	}
+	return &memory{bytes: bytes}, nil
+}
+
+// release wipes and frees the memory.
+func (m *memory) release() {
+	if len(m.bytes) == 0 {
+		return
+	}
+	clear(m.bytes)
+	unix.Munlock(m.bytes)
+	unix.Munmap(m.bytes)
+	m.bytes = nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package agent
+
+import "io/fs"
+
+// ownedByUser tells whether the file is owned by the user,
+// owners are not checked on this platform.
+func ownedByUser(fs.FileInfo) bool {
+	return true
+}
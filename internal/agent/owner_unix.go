@@ -0,0 +1,15 @@
+//go:build unix
+
+package agent
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// ownedByUser tells whether the file is owned by the user running the agent.
+func ownedByUser(info fs.FileInfo) bool {
+	var stat, ok = info.Sys().(*syscall.Stat_t)
+	return ok && (int)(stat.Uid) == os.Getuid()
+}
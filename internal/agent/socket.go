@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SocketEnv is the environment variable with the path of the agent's socket.
+const SocketEnv = "GOPHKEEPER_AGENT_SOCK"
+
+// DefaultSocket returns the default path of the socket, in the user's
+// runtime directory or in a directory of the user in the temporary one.
+func DefaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gophkeeper", "agent.sock")
+	}
+	return filepath.Join(os.TempDir(), "gophkeeper-"+strconv.Itoa(os.Getuid()), "agent.sock")
+}
+
+// Listen listens on the socket, only the user can connect to it. The directory
+// of the socket is created if needed, it must be the user's own directory,
+// not a link, and must not be accessible by others.
+func Listen(path string) (net.Listener, error) {
+	var dir = filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	// Another user could have created the directory first, or a link to theirs.
+	var info, statError = os.Lstat(dir)
+	if statError != nil {
+		return nil, statError
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	if !ownedByUser(info) {
+		return nil, fmt.Errorf("%s is owned by another user", dir)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%s is accessible by other users", dir)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		var connection, dialError = net.DialTimeout("unix", path, time.Second)
+		if dialError == nil {
+			connection.Close()
+			return nil, fmt.Errorf("agent is already running on %s", path)
+		}
+		// The socket is left by an agent that is not running anymore.
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	var listener, listenError = net.Listen("unix", path)
+	if listenError != nil {
+		return nil, listenError
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
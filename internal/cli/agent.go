@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/kerelape/gophkeeper/internal/agent"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// agentSession returns the session held by the agent for the server
+// and the username, if any is set, if the agent is running and unlocked.
+func agentSession(ctx context.Context) (agent.Session, bool) {
+	var s = settingsOf(ctx)
+	if s.agent == nil || s.noSession {
+		return agent.Session{}, false
+	}
+	var held, heldError = s.agent.Session(ctx)
+	if heldError != nil || held.Server != s.server || (s.username != "" && s.username != held.Username) {
+		return agent.Session{}, false
+	}
+	return held, true
+}
+
+// unlockAgent makes the agent hold the session of the credential
+// just authenticated with, its password is the vault password.
+func unlockAgent(ctx context.Context, credential gophkeeper.Credential, token gophkeeper.Token) error {
+	var s = settingsOf(ctx)
+	if s.agent == nil || s.noSession {
+		return nil
+	}
+	var started, sessionError = newSession(s.server, credential.Username, token)
+	if sessionError != nil {
+		return sessionError
+	}
+	var held = agent.Session{
+		Server:        started.Server,
+		Username:      started.Username,
+		Token:         (string)(started.Token),
+		ExpiresAt:     started.ExpiresAt,
+		VaultPassword: credential.Password,
+	}
+	return s.agent.Unlock(ctx, held)
+}
+
+// lockAgent makes the agent forget the session of the server and reports
+// whether it held one, a stopped or locked agent holds nothing.
+func lockAgent(ctx context.Context) (bool, error) {
+	var s = settingsOf(ctx)
+	if s.agent == nil {
+		return false, nil
+	}
+	var held, heldError = s.agent.Status(ctx)
+	if heldError != nil || held.Server != s.server {
+		return false, nil
+	}
+	return true, s.agent.Lock(ctx)
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/agent"
+	"github.com/kerelape/gophkeeper/internal/stack"
+)
+
+type agentCommand struct {
+	socket  string
+	timeout time.Duration
+}
+
+var (
+	_ command = (*agentCommand)(nil)
+	_ flagged = (*agentCommand)(nil)
+)
+
+// Flags implements flagged.
+func (a *agentCommand) Flags(set *flag.FlagSet) {
+	set.StringVar(&a.socket, "socket", agent.DefaultSocket(), "Path of the agent's socket")
+	set.DurationVar(&a.timeout, "timeout", agent.DefaultTimeout, "Lock the agent when it is not used for this long")
+}
+
+// Description implements command.
+func (a *agentCommand) Description() string {
+	return "Run an agent holding the session and the vault password, used by commands when " + agent.SocketEnv + " is set."
+}
+
+// Help implements command.
+func (a *agentCommand) Help() string {
+	return "[--socket: string] [--timeout: duration]"
+}
+
+// Execute implements command.
+func (a *agentCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	if a.timeout <= 0 {
+		return false, errors.New("timeout must be positive")
+	}
+	var listener, listenError = agent.Listen(a.socket)
+	if listenError != nil {
+		return true, listenError
+	}
+	defer os.Remove(a.socket)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	// Printed like ssh-agent does, to be evaluated by the shell.
+	printf(ctx, "%s=%s; export %s;\n", agent.SocketEnv, a.socket, agent.SocketEnv)
+	result(ctx, map[string]any{"socket": a.socket})
+	var server = agent.Agent{Timeout: a.timeout}
+	return true, server.Serve(ctx, listener)
+}
@@ -2,7 +2,7 @@ package cli
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -11,27 +11,111 @@ import (
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
 
+// authenticate returns the identity of the saved session, logging in
+// again if it is expired, or asks for the credential if there is none.
 func authenticate(ctx context.Context, g gophkeeper.Gophkeeper) (gophkeeper.Identity, error) {
+	var s = settingsOf(ctx)
+	if held, ok := agentSession(ctx); ok {
+		return g.Identity(ctx, (gophkeeper.Token)(held.Token))
+	}
+	var (
+		saved session
+		found bool
+	)
+	if !s.noSession {
+		var loadError error
+		if saved, found, loadError = loadSession(); loadError != nil {
+			return nil, loadError
+		}
+	}
+	if !found || saved.Server != s.server || (s.username != "" && s.username != saved.Username) {
+		var _, token, loginError = login(ctx, g, "")
+		if loginError != nil {
+			return nil, loginError
+		}
+		return g.Identity(ctx, token)
+	}
+
+	var identity = &sessionIdentity{
+		gophkeeper: g,
+		session:    saved,
+	}
+	if saved.expired() {
+		if err := identity.relogin(ctx); err != nil {
+			return nil, err
+		}
+		return identity, nil
+	}
+	var origin, originError = g.Identity(ctx, saved.Token)
+	if originError != nil {
+		return nil, originError
+	}
+	identity.origin = origin
+	return identity, nil
+}
+
+// login asks for the credential, the username is not asked if it is known,
+// and returns the username with the token.
+func login(ctx context.Context, g gophkeeper.Gophkeeper, username string) (string, gophkeeper.Token, error) {
+	var credential, credentialError = authenticationCredential(ctx, username)
+	if credentialError != nil {
+		return "", "", credentialError
+	}
+	var token, tokenError = g.Authenticate(ctx, credential)
+	if tokenError != nil {
+		return "", "", tokenError
+	}
+	if err := unlockAgent(ctx, credential, token); err != nil {
+		var s = settingsOf(ctx)
+		fmt.Fprintf(s.stderr, "The agent is not unlocked: %v\n", err)
+	}
+	return credential.Username, token, nil
+}
+
+// authenticationCredential returns the credential set with flags
+// or environment, asking for what is missing.
+func authenticationCredential(ctx context.Context, username string) (gophkeeper.Credential, error) {
+	var s = settingsOf(ctx)
+	var credential = gophkeeper.Credential{
+		Username: s.username,
+		Password: s.password,
+	}
+	if credential.Username == "" {
+		credential.Username = username
+	}
+	if credential.Username != "" && credential.Password != "" {
+		return credential, nil
+	}
+	if !s.interactive {
+		var usernameError, passwordError error
+		if credential.Username, usernameError = s.value(credential.Username, "Username: "); usernameError != nil {
+			return gophkeeper.Credential{}, usernameError
+		}
+		if credential.Password, passwordError = s.value(credential.Password, "Password: "); passwordError != nil {
+			return gophkeeper.Credential{}, passwordError
+		}
+		return credential, nil
+	}
+	var model = newAuthenticationModel()
+	if credential.Username != "" {
+		model.username.SetValue(credential.Username)
+		model.username.Blur()
+		model.password.Focus()
+	}
 	var m, err = tea.NewProgram(
-		newAuthenticationModel(),
+		model,
 		tea.WithAltScreen(),
 		tea.WithContext(ctx),
 	).Run()
 	if err != nil {
-		return nil, err
+		return gophkeeper.Credential{}, err
 	}
 	if m.(authenticationModel).cancelled {
-		return nil, errors.New("authentiation cancelled by user")
-	}
-	var credential = gophkeeper.Credential{
-		Username: m.(authenticationModel).username.Value(),
-		Password: m.(authenticationModel).password.Value(),
-	}
-	var token, tokenError = g.Authenticate(ctx, credential)
-	if tokenError != nil {
-		return nil, tokenError
+		return gophkeeper.Credential{}, fmt.Errorf("authentication %w", errCancelled)
 	}
-	return g.Identity(ctx, token)
+	credential.Username = m.(authenticationModel).username.Value()
+	credential.Password = m.(authenticationModel).password.Value()
+	return credential, nil
 }
 
 type authenticationModel struct {
@@ -53,7 +137,7 @@ func newAuthenticationModel() authenticationModel {
 	m.username.Prompt = "Username: "
 	m.username.Placeholder = "type your username..."
 
-	m.password.CharLimit = 32
+	m.password.CharLimit = gophkeeper.MaxPasswordBytes
 	m.password.Prompt = "Password: "
 	m.password.EchoMode = textinput.EchoPassword
 	m.password.Placeholder = "type your password..."
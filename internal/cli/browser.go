@@ -0,0 +1,689 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+var (
+	paneStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderForeground(lipgloss.Color("#0088AA")).
+			Padding(0, 1)
+	labelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#0088AA"))
+)
+
+// reveal decrypts fields of the resource, files have none.
+func reveal(ctx context.Context, i identity, r resource, vaultPassword string) ([]field, error) {
+	switch r.Type {
+	case resourceTypeCredential:
+		var credential, err = i.RestoreCredential(ctx, r.RID, vaultPassword)
+		return credential.fields(), err
+	case resourceTypeText:
+		var text, err = i.RestoreText(ctx, r.RID, vaultPassword)
+		return text.fields(), err
+	case resourceTypeCard:
+		var card, err = i.RestoreCard(ctx, r.RID, vaultPassword)
+		return card.fields(), err
+	default:
+		return nil, nil
+	}
+}
+
+// browserItem is a resource in the list of the browser.
+type browserItem struct {
+	resource resource
+}
+
+var _ list.DefaultItem = (*browserItem)(nil)
+
+// Title implements list.DefaultItem.
+func (i browserItem) Title() string {
+	var title, _, _ = strings.Cut(i.resource.Description, "\n")
+	if title == "" {
+		return "(no description)"
+	}
+	return title
+}
+
+// Description implements list.DefaultItem.
+func (i browserItem) Description() string {
+	return fmt.Sprintf("%s (RID: %d)", i.resource.Type.String(), i.resource.RID)
+}
+
+// FilterValue implements list.Item.
+func (i browserItem) FilterValue() string {
+	return i.resource.Type.String() + " " + i.resource.Description
+}
+
+type browserMode int
+
+const (
+	browsing browserMode = iota
+	choosingType
+	editing
+	confirmingDelete
+	downloading
+	locked
+)
+
+type (
+	listedMsg struct {
+		resources []resource
+		err       error
+	}
+	revealedMsg struct {
+		rid    gophkeeper.ResourceID
+		fields []field
+		edit   bool
+		err    error
+	}
+	doneMsg struct {
+		status string
+		err    error
+	}
+	unlockedMsg struct {
+		identity gophkeeper.Identity
+		password string
+		err      error
+	}
+	browserTickMsg time.Time
+)
+
+// copiedField is a field in the clipboard, cleared at clearAt.
+type copiedField struct {
+	name    string
+	value   string
+	clearAt time.Time
+}
+
+// details are decrypted fields of the selected resource.
+type details struct {
+	rid    gophkeeper.ResourceID
+	fields []field
+}
+
+// browserModel is a vault browser, it is locked after lockAfter
+// without input, forgetting the vault password and what is decrypted.
+type browserModel struct {
+	ctx        context.Context
+	gophkeeper gophkeeper.Gophkeeper
+	identity   identity
+	username   string
+	password   string
+
+	lockAfter  time.Duration
+	lastActive time.Time
+
+	width, height int
+
+	mode        browserMode
+	list        list.Model
+	details     *details
+	showSecrets bool
+	editor      editorModel
+	input       textinput.Model
+	copied      *copiedField
+
+	status string
+	failed bool
+}
+
+func newBrowserModel(
+	ctx context.Context,
+	g gophkeeper.Gophkeeper,
+	origin gophkeeper.Identity,
+	username, vaultPassword string,
+	lockAfter time.Duration,
+) browserModel {
+	var m = browserModel{
+		ctx:        ctx,
+		gophkeeper: g,
+		identity:   identity{origin: origin},
+		username:   username,
+		password:   vaultPassword,
+		lockAfter:  lockAfter,
+		lastActive: time.Now(),
+		list:       list.New(nil, list.NewDefaultDelegate(), 0, 0),
+		input:      textinput.New(),
+	}
+	m.list.Title = "Vault"
+	m.list.SetShowHelp(false)
+	m.list.DisableQuitKeybindings()
+	return m
+}
+
+var _ tea.Model = (*browserModel)(nil)
+
+// Init implements tea.Model.
+func (m browserModel) Init() tea.Cmd {
+	return tea.Batch(m.load(), m.tick())
+}
+
+func (m browserModel) tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return browserTickMsg(t)
+	})
+}
+
+func (m browserModel) load() tea.Cmd {
+	var ctx, identity = m.ctx, m.identity
+	return func() tea.Msg {
+		var resources, err = identity.List(ctx)
+		return listedMsg{resources: resources, err: err}
+	}
+}
+
+func (m browserModel) reveal(r resource, edit bool) tea.Cmd {
+	var ctx, identity, password = m.ctx, m.identity, m.password
+	return func() tea.Msg {
+		var fields, err = reveal(ctx, identity, r, password)
+		return revealedMsg{rid: r.RID, fields: fields, edit: edit, err: err}
+	}
+}
+
+func (m browserModel) save(e editorModel) tea.Cmd {
+	var ctx, identity, password = m.ctx, m.identity, m.password
+	return func() tea.Msg {
+		var (
+			values = e.fields()
+			rid    gophkeeper.ResourceID
+			err    error
+		)
+		switch e.resourceType {
+		case resourceTypeCredential:
+			var resource = credentialResource{
+				description: e.description(),
+				username:    values[0],
+				password:    values[1],
+			}
+			if e.replaces >= 0 && resource.password == e.password {
+				resource.storedAt = &e.storedAt
+			}
+			rid, err = identity.StoreCredential(ctx, resource, password)
+		case resourceTypeText:
+			var resource = textResource{
+				description: e.description(),
+				content:     values[0],
+			}
+			rid, err = identity.StoreText(ctx, resource, password)
+		case resourceTypeCard:
+			var resource = cardResource{
+				description: e.description(),
+				cardInfo: cardInfo{
+					ccn:    values[0],
+					exp:    values[1],
+					cvv:    values[2],
+					holder: values[3],
+				},
+			}
+			rid, err = identity.StoreCard(ctx, resource, password)
+		case resourceTypeFile:
+			if e.replaces < 0 {
+				var resource = fileResource{
+					description: e.description(),
+					path:        values[0],
+				}
+				rid, err = identity.StoreFile(ctx, resource, password)
+				break
+			}
+			// The content is copied to a new resource with the new description.
+			var blob, blobError = identity.origin.RestoreBlob(ctx, e.replaces, password)
+			if blobError != nil {
+				return doneMsg{err: blobError}
+			}
+			rid, err = identity.StoreFileContent(ctx, e.description(), blob.Content, password)
+		}
+		if err != nil {
+			return doneMsg{err: err}
+		}
+		// Resources cannot be changed, an edited one is stored anew.
+		if e.replaces >= 0 {
+			if err := identity.origin.Delete(ctx, e.replaces); err != nil {
+				return doneMsg{err: fmt.Errorf("stored as RID %d, but the old resource is not deleted: %w", rid, err)}
+			}
+		}
+		return doneMsg{status: fmt.Sprintf("Stored %s (RID: %d)", e.resourceType.String(), rid)}
+	}
+}
+
+func (m browserModel) delete(r resource) tea.Cmd {
+	var ctx, identity = m.ctx, m.identity
+	return func() tea.Msg {
+		if err := identity.origin.Delete(ctx, r.RID); err != nil {
+			return doneMsg{err: err}
+		}
+		return doneMsg{status: fmt.Sprintf("Deleted %s (RID: %d)", r.Type.String(), r.RID)}
+	}
+}
+
+func (m browserModel) download(r resource, path string) tea.Cmd {
+	var ctx, identity, password = m.ctx, m.identity, m.password
+	return func() tea.Msg {
+		var file, err = identity.RestoreFile(ctx, r.RID, path, password)
+		if err != nil {
+			return doneMsg{err: err}
+		}
+		return doneMsg{status: fmt.Sprintf("Saved RID %d to %s", r.RID, file.path)}
+	}
+}
+
+// unlock checks the password by logging in again, which also renews the token.
+func (m browserModel) unlock(password string) tea.Cmd {
+	var ctx, g, username = m.ctx, m.gophkeeper, m.username
+	return func() tea.Msg {
+		if username == "" {
+			// The username is unknown, the password is checked when it is used.
+			return unlockedMsg{password: password}
+		}
+		var token, tokenError = g.Authenticate(ctx, gophkeeper.Credential{Username: username, Password: password})
+		if tokenError != nil {
+			return unlockedMsg{err: tokenError}
+		}
+		var origin, originError = g.Identity(ctx, token)
+		return unlockedMsg{identity: origin, password: password, err: originError}
+	}
+}
+
+// lock forgets the vault password and everything decrypted.
+func (m *browserModel) lock(status string) tea.Cmd {
+	m.clearCopied()
+	m.mode = locked
+	m.password = ""
+	m.details = nil
+	m.showSecrets = false
+	m.editor = editorModel{}
+	m.status, m.failed = status, false
+	m.input = textinput.New()
+	m.input.Prompt = "Vault password: "
+	m.input.EchoMode = textinput.EchoPassword
+	m.input.CharLimit = 72
+	m.input.Placeholder = "enter your vault password..."
+	return m.input.Focus()
+}
+
+func (m browserModel) selected() (resource, bool) {
+	var item, ok = m.list.SelectedItem().(browserItem)
+	return item.resource, ok
+}
+
+// copy puts the field in the clipboard to be cleared after clearAfter.
+func (m *browserModel) copy(f field) {
+	var s = settingsOf(m.ctx)
+	if err := s.clipboard.WriteAll(f.value); err != nil {
+		m.fail(err)
+		return
+	}
+	var name = strings.ToLower(f.name)
+	m.status, m.failed = fmt.Sprintf("Copied %s", name), false
+	m.copied = nil
+	if s.clearAfter > 0 {
+		m.copied = &copiedField{name: name, value: f.value, clearAt: time.Now().Add(s.clearAfter)}
+	}
+}
+
+// clearCopied clears the clipboard if it still holds the copied field.
+func (m *browserModel) clearCopied() {
+	if m.copied == nil {
+		return
+	}
+	var cleared, err = clearClipboard(settingsOf(m.ctx).clipboard, m.copied.value)
+	m.copied = nil
+	switch {
+	case err != nil:
+		m.fail(err)
+	case cleared:
+		m.status, m.failed = "The clipboard is cleared", false
+	}
+}
+
+func (m *browserModel) fail(err error) {
+	m.status, m.failed = err.Error(), true
+}
+
+// Update implements tea.Model.
+func (m browserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.list.SetSize(m.width/2-paneStyle.GetHorizontalFrameSize(), m.height-paneStyle.GetVerticalFrameSize()-2)
+		return m, nil
+	case browserTickMsg:
+		if m.copied != nil && !time.Time(msg).Before(m.copied.clearAt) {
+			m.clearCopied()
+		}
+		if m.mode != locked && time.Since(m.lastActive) >= m.lockAfter {
+			return m, tea.Batch(m.lock("Locked after inactivity"), m.tick())
+		}
+		return m, m.tick()
+	case listedMsg:
+		if msg.err != nil {
+			return m.failure(msg.err)
+		}
+		var items = make([]list.Item, 0, len(msg.resources))
+		for _, r := range msg.resources {
+			items = append(items, browserItem{resource: r})
+		}
+		return m, m.list.SetItems(items)
+	case revealedMsg:
+		if m.mode == locked {
+			return m, nil
+		}
+		if msg.err != nil {
+			return m.failure(msg.err)
+		}
+		m.details = &details{rid: msg.rid, fields: msg.fields}
+		if r, ok := m.selected(); ok && msg.edit && r.RID == msg.rid {
+			m.mode = editing
+			m.editor = newEditorModel(r, msg.fields)
+		}
+		return m, nil
+	case doneMsg:
+		if msg.err != nil {
+			return m.failure(msg.err)
+		}
+		m.status, m.failed = msg.status, false
+		m.details = nil
+		return m, m.load()
+	case unlockedMsg:
+		if msg.err != nil {
+			m.input.SetValue("")
+			m.fail(msg.err)
+			return m, nil
+		}
+		if msg.identity != nil {
+			m.identity = identity{origin: msg.identity}
+		}
+		m.password = msg.password
+		m.mode = browsing
+		m.lastActive = time.Now()
+		m.status, m.failed = "Unlocked", false
+		return m, m.load()
+	case tea.KeyMsg:
+		m.lastActive = time.Now()
+		if msg.String() == "ctrl+c" {
+			m.clearCopied()
+			m.password = ""
+			return m, tea.Quit
+		}
+		return m.key(msg)
+	}
+	var cmd tea.Cmd
+	switch m.mode {
+	case editing:
+		m.editor, cmd = m.editor.Update(msg)
+	case downloading, locked:
+		m.input, cmd = m.input.Update(msg)
+	default:
+		m.list, cmd = m.list.Update(msg)
+	}
+	return m, cmd
+}
+
+// failure shows the error, a rejected token or vault password locks the browser.
+func (m browserModel) failure(err error) (tea.Model, tea.Cmd) {
+	if errors.Is(err, gophkeeper.ErrBadCredential) {
+		var cmd = m.lock("The session is expired or the vault password is wrong")
+		m.failed = true
+		return m, cmd
+	}
+	m.fail(err)
+	return m, nil
+}
+
+func (m browserModel) key(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.mode {
+	case locked:
+		switch msg.String() {
+		case "enter":
+			if m.input.Value() == "" {
+				return m, nil
+			}
+			m.status, m.failed = "Unlocking...", false
+			return m, m.unlock(m.input.Value())
+		case "esc":
+			return m, tea.Quit
+		}
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	case choosingType:
+		m.mode = browsing
+		var types = map[string]resourceType{
+			"c": resourceTypeCredential,
+			"t": resourceTypeText,
+			"k": resourceTypeCard,
+			"f": resourceTypeFile,
+		}
+		if t, ok := types[msg.String()]; ok {
+			m.mode = editing
+			m.editor = newEditorModel(resource{RID: -1, Type: t}, nil)
+		}
+		return m, nil
+	case editing:
+		switch msg.String() {
+		case "esc":
+			m.mode = browsing
+			return m, nil
+		case "ctrl+s":
+			m.mode = browsing
+			m.status, m.failed = "Saving...", false
+			return m, m.save(m.editor)
+		}
+		m.editor, cmd = m.editor.Update(msg)
+		return m, cmd
+	case confirmingDelete:
+		m.mode = browsing
+		if r, ok := m.selected(); ok && msg.String() == "y" {
+			m.status, m.failed = "Deleting...", false
+			return m, m.delete(r)
+		}
+		m.status, m.failed = "", false
+		return m, nil
+	case downloading:
+		switch msg.String() {
+		case "esc":
+			m.mode = browsing
+			return m, nil
+		case "enter":
+			m.mode = browsing
+			if r, ok := m.selected(); ok && m.input.Value() != "" {
+				m.status, m.failed = "Saving...", false
+				return m, m.download(r, m.input.Value())
+			}
+			return m, nil
+		}
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	if m.list.SettingFilter() {
+		m.list, cmd = m.list.Update(msg)
+		return m, cmd
+	}
+	var r, selected = m.selected()
+	switch key := msg.String(); key {
+	case "q":
+		m.clearCopied()
+		m.password = ""
+		return m, tea.Quit
+	case "ctrl+l":
+		return m, m.lock("Locked")
+	case "r":
+		return m, m.load()
+	case "n":
+		m.mode = choosingType
+		return m, nil
+	case "x":
+		m.showSecrets = !m.showSecrets
+		return m, nil
+	case "enter":
+		if selected && r.Type != resourceTypeFile {
+			return m, m.reveal(r, false)
+		}
+		return m, nil
+	case "e":
+		if !selected {
+			return m, nil
+		}
+		if m.details != nil && m.details.rid == r.RID || r.Type == resourceTypeFile {
+			m.mode = editing
+			m.editor = newEditorModel(r, m.fields(r))
+			return m, nil
+		}
+		return m, m.reveal(r, true)
+	case "d":
+		if selected {
+			m.mode = confirmingDelete
+		}
+		return m, nil
+	case "s":
+		if selected && r.Type == resourceTypeFile {
+			m.mode = downloading
+			m.input = textinput.New()
+			m.input.Prompt = "Save to: "
+			m.input.Placeholder = "path of the file..."
+			m.input.Width = 48
+			return m, m.input.Focus()
+		}
+		return m, nil
+	case "1", "2", "3", "4":
+		var fields = m.fields(r)
+		var n = (int)(key[0] - '1')
+		if n < len(fields) {
+			m.copy(fields[n])
+		}
+		return m, nil
+	}
+	m.list, cmd = m.list.Update(msg)
+	if r, ok := m.selected(); !ok || m.details != nil && m.details.rid != r.RID {
+		m.details = nil
+		m.showSecrets = false
+	}
+	return m, cmd
+}
+
+// fields returns the decrypted fields of the resource, if it is decrypted.
+func (m browserModel) fields(r resource) []field {
+	if m.details == nil || m.details.rid != r.RID {
+		return nil
+	}
+	return m.details.fields
+}
+
+// View implements tea.Model.
+func (m browserModel) View() string {
+	switch m.mode {
+	case locked:
+		return form(
+			m.width, m.height,
+			"Gophkeeper is locked",
+			lipgloss.JoinVertical(
+				lipgloss.Left,
+				lipgloss.NewStyle().Width(64).Render(m.input.View()),
+				m.statusView(),
+			),
+		)
+	case editing:
+		return m.editor.View(m.width, m.height)
+	}
+
+	var (
+		paneWidth  = m.width/2 - paneStyle.GetHorizontalFrameSize()
+		paneHeight = m.height - paneStyle.GetVerticalFrameSize() - 2
+	)
+	var panes = lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		paneStyle.Width(paneWidth).Height(paneHeight).Render(m.list.View()),
+		paneStyle.Width(paneWidth).Height(paneHeight).Render(m.detailsView(paneWidth)),
+	)
+	var bottom string
+	switch m.mode {
+	case choosingType:
+		bottom = "New: [c] credential  [t] text  [k] card  [f] file"
+	case confirmingDelete:
+		if r, ok := m.selected(); ok {
+			bottom = fmt.Sprintf("Delete %s (RID: %d)? [y/N]", r.Type.String(), r.RID)
+		}
+	case downloading:
+		bottom = m.input.View()
+	default:
+		bottom = m.statusView()
+	}
+	var help = help.New()
+	help.Width = m.width
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		panes,
+		bottom,
+		help.ShortHelpView(
+			[]key.Binding{
+				key.NewBinding(key.WithKeys("/"), key.WithHelp("[/]", "filter")),
+				key.NewBinding(key.WithKeys("enter"), key.WithHelp("[enter]", "decrypt")),
+				key.NewBinding(key.WithKeys("x"), key.WithHelp("[x]", "show secrets")),
+				key.NewBinding(key.WithKeys("1", "2", "3", "4"), key.WithHelp("[1-4]", "copy field")),
+				key.NewBinding(key.WithKeys("n"), key.WithHelp("[n]", "new")),
+				key.NewBinding(key.WithKeys("e"), key.WithHelp("[e]", "edit")),
+				key.NewBinding(key.WithKeys("d"), key.WithHelp("[d]", "delete")),
+				key.NewBinding(key.WithKeys("s"), key.WithHelp("[s]", "save file")),
+				key.NewBinding(key.WithKeys("ctrl+l"), key.WithHelp("[ctrl+l]", "lock")),
+				key.NewBinding(key.WithKeys("q"), key.WithHelp("[q]", "quit")),
+			},
+		),
+	)
+}
+
+func (m browserModel) detailsView(width int) string {
+	var r, ok = m.selected()
+	if !ok {
+		return "The vault is empty, press [n] to create a resource."
+	}
+	var rows = []string{
+		labelStyle.Render(r.Type.String()) + fmt.Sprintf(" (RID: %d)", r.RID),
+		"",
+		lipgloss.NewStyle().Width(width).Render(r.Description),
+		"",
+	}
+	switch fields := m.fields(r); {
+	case r.Type == resourceTypeFile:
+		rows = append(rows, "Press [s] to save the file.")
+	case fields == nil:
+		rows = append(rows, "Press [enter] to decrypt.")
+	default:
+		for n, f := range fields {
+			var value = f.value
+			if f.secret && !m.showSecrets {
+				value = strings.Repeat("•", 8)
+			}
+			rows = append(rows, fmt.Sprintf("[%d] %s: %s", n+1, labelStyle.Render(f.name), value))
+		}
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (m browserModel) statusView() string {
+	var status = m.status
+	if m.failed {
+		status = violationStyle.Render(m.status)
+	}
+	if m.copied != nil {
+		var left = time.Until(m.copied.clearAt).Round(time.Second)
+		status = lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			status,
+			labelStyle.Render(fmt.Sprintf("  The %s is cleared from the clipboard in %s", m.copied.name, left)),
+		)
+	}
+	return status
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// editorModel is a form of a resource created or edited in the browser.
+type editorModel struct {
+	resourceType resourceType
+	// replaces is the resource replaced on save, -1 if a new one is created.
+	replaces gophkeeper.ResourceID
+	// storedAt and password are of the replaced credential,
+	// its age is kept if the password is not changed.
+	storedAt time.Time
+	password string
+
+	inputs []textinput.Model
+	// note is the content of a text note, it is focused after the inputs.
+	note    textarea.Model
+	focused int
+}
+
+// newEditorModel returns an editor of a new resource of the type if its RID
+// is negative, or of the resource with the fields if it replaces one.
+func newEditorModel(r resource, fields []field) editorModel {
+	var m = editorModel{
+		resourceType: r.Type,
+		replaces:     r.RID,
+		storedAt:     r.StoredAt,
+	}
+	if r.Type == resourceTypeCredential && len(fields) > 1 {
+		m.password = fields[1].value
+	}
+	var names = []string{"Description"}
+	switch r.Type {
+	case resourceTypeCredential:
+		names = append(names, "Username", "Password")
+	case resourceTypeCard:
+		names = append(names, "Number", "Expiry", "CVV", "Holder")
+	case resourceTypeFile:
+		if r.RID < 0 {
+			names = append(names, "Path")
+		}
+	}
+	for n, name := range names {
+		var input = textinput.New()
+		input.Prompt = name + ": "
+		input.CharLimit = 256
+		input.Width = 48
+		if n == 0 {
+			input.SetValue(strings.ReplaceAll(r.Description, "\n", " "))
+		} else if n-1 < len(fields) {
+			input.SetValue(fields[n-1].value)
+		}
+		if name == "Password" || name == "CVV" {
+			input.EchoMode = textinput.EchoPassword
+		}
+		m.inputs = append(m.inputs, input)
+	}
+	m.note = textarea.New()
+	m.note.ShowLineNumbers = false
+	m.note.Placeholder = "type your note..."
+	m.note.CharLimit = 1024
+	m.note.SetWidth(64)
+	m.note.SetHeight(8)
+	if r.Type == resourceTypeText && len(fields) > 0 {
+		m.note.SetValue(fields[0].value)
+	}
+	m.focus(0)
+	return m
+}
+
+// fields returns the values typed, not counting the description.
+func (m editorModel) fields() []string {
+	var values = make([]string, 0, len(m.inputs))
+	for _, input := range m.inputs[1:] {
+		values = append(values, input.Value())
+	}
+	if m.resourceType == resourceTypeText {
+		values = append(values, m.note.Value())
+	}
+	return values
+}
+
+func (m editorModel) description() string {
+	return m.inputs[0].Value()
+}
+
+func (m editorModel) count() int {
+	if m.resourceType == resourceTypeText {
+		return len(m.inputs) + 1
+	}
+	return len(m.inputs)
+}
+
+func (m *editorModel) focus(n int) {
+	m.focused = (n + m.count()) % m.count()
+	for i := range m.inputs {
+		if i == m.focused {
+			m.inputs[i].Focus()
+		} else {
+			m.inputs[i].Blur()
+		}
+	}
+	if m.focused == len(m.inputs) {
+		m.note.Focus()
+	} else {
+		m.note.Blur()
+	}
+}
+
+// Update updates the editor, keys saving and cancelling are handled by the browser.
+func (m editorModel) Update(msg tea.Msg) (editorModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "tab":
+			m.focus(m.focused + 1)
+			return m, nil
+		case "shift+tab":
+			m.focus(m.focused - 1)
+			return m, nil
+		case "enter", "down":
+			// The note takes its own newlines and arrows.
+			if m.focused < len(m.inputs) {
+				m.focus(m.focused + 1)
+				return m, nil
+			}
+		case "up":
+			if m.focused < len(m.inputs) {
+				m.focus(m.focused - 1)
+				return m, nil
+			}
+		}
+	}
+	var cmds = make([]tea.Cmd, 0, len(m.inputs)+1)
+	for i := range m.inputs {
+		var cmd tea.Cmd
+		m.inputs[i], cmd = m.inputs[i].Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	var noteCmd tea.Cmd
+	m.note, noteCmd = m.note.Update(msg)
+	return m, tea.Batch(append(cmds, noteCmd)...)
+}
+
+// View renders the editor.
+func (m editorModel) View(width, height int) string {
+	var title = "New " + m.resourceType.String()
+	if m.replaces >= 0 {
+		title = "Edit " + m.resourceType.String()
+	}
+	var rows = make([]string, 0, 2*len(m.inputs)+2)
+	for _, input := range m.inputs {
+		rows = append(rows, input.View(), strings.Repeat(" ", 64))
+	}
+	if m.resourceType == resourceTypeText {
+		rows = append(rows, m.note.View())
+	}
+	var help = help.New()
+	help.Width = 64
+	rows = append(rows, help.ShortHelpView(
+		[]key.Binding{
+			key.NewBinding(
+				key.WithKeys("esc"),
+				key.WithHelp("[esc]", "cancel"),
+			),
+			key.NewBinding(
+				key.WithKeys("tab"),
+				key.WithHelp("[tab]", "next field"),
+			),
+			key.NewBinding(
+				key.WithKeys("ctrl+s"),
+				key.WithHelp("[ctrl+s]", "save"),
+			),
+		},
+	))
+	return form(width, height, title, lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStoringIdentity struct {
+	gophkeeper.Identity
+
+	pieces []gophkeeper.Piece
+}
+
+// StorePiece implements gophkeeper.Identity.
+func (i *fakeStoringIdentity) StorePiece(_ context.Context, piece gophkeeper.Piece, _ string) (gophkeeper.ResourceID, error) {
+	i.pieces = append(i.pieces, piece)
+	return (gophkeeper.ResourceID)(len(i.pieces) + 1), nil
+}
+
+// Delete implements gophkeeper.Identity.
+func (i *fakeStoringIdentity) Delete(context.Context, gophkeeper.ResourceID) error {
+	return nil
+}
+
+func TestBrowser(t *testing.T) {
+	var browser = func() browserModel {
+		var m = newBrowserModel(
+			context.Background(),
+			&fakeSessionGophkeeper{token: "token"},
+			&fakeSessionIdentity{valid: true},
+			"alice", "hunter2",
+			time.Minute,
+		)
+		var updated, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+		updated, _ = updated.Update(listedMsg{resources: []resource{
+			{RID: 1, Description: "GitHub\nwork account", Type: resourceTypeCredential},
+			{RID: 2, Description: "Passport scan", Type: resourceTypeFile},
+		}})
+		return updated.(browserModel)
+	}
+	t.Run("Item", func(t *testing.T) {
+		var item = browserItem{resource: resource{RID: 1, Description: "GitHub\nwork account", Type: resourceTypeCredential}}
+		assert.Equal(t, "GitHub", item.Title())
+		assert.Equal(t, "Credential (RID: 1)", item.Description())
+		assert.Contains(t, item.FilterValue(), "work account")
+	})
+	t.Run("SecretsAreMasked", func(t *testing.T) {
+		var m = browser()
+		var updated, _ = m.Update(revealedMsg{
+			rid: 1,
+			fields: []field{
+				{name: "Username", value: "alice"},
+				{name: "Password", value: "hunter2", secret: true},
+			},
+		})
+		assert.Contains(t, updated.View(), "alice")
+		assert.NotContains(t, updated.View(), "hunter2")
+		updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+		assert.Contains(t, updated.View(), "hunter2")
+	})
+	t.Run("LockedAfterInactivity", func(t *testing.T) {
+		var m = browser()
+		m.details = &details{rid: 1, fields: []field{{name: "Password", value: "hunter2", secret: true}}}
+		var updated, _ = m.Update(browserTickMsg(time.Now()))
+		assert.Equal(t, browsing, updated.(browserModel).mode)
+
+		m.lastActive = time.Now().Add(-2 * time.Minute)
+		updated, _ = m.Update(browserTickMsg(time.Now()))
+		var lockedModel = updated.(browserModel)
+		assert.Equal(t, locked, lockedModel.mode)
+		assert.Empty(t, lockedModel.password)
+		assert.Nil(t, lockedModel.details)
+	})
+	t.Run("Unlock", func(t *testing.T) {
+		var m = browser()
+		m.lock("Locked")
+		var rejected = m.unlock("wrong")().(unlockedMsg)
+		assert.ErrorIs(t, rejected.err, gophkeeper.ErrBadCredential)
+
+		var updated, _ = m.Update(m.unlock("hunter2")())
+		assert.Equal(t, browsing, updated.(browserModel).mode)
+		assert.Equal(t, "hunter2", updated.(browserModel).password)
+	})
+	t.Run("EditKeepsAge", func(t *testing.T) {
+		var origin = &fakeStoringIdentity{}
+		var m = newBrowserModel(context.Background(), &fakeSessionGophkeeper{token: "token"}, origin, "alice", "hunter2", time.Minute)
+		var storedAt = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+		var storedAtOf = func(piece gophkeeper.Piece) time.Time {
+			var meta struct {
+				StoredAt time.Time `json:"stored_at"`
+			}
+			assert.Nil(t, json.Unmarshal(([]byte)(piece.Meta), &meta))
+			return meta.StoredAt
+		}
+		var editor = newEditorModel(
+			resource{RID: 1, Description: "GitHub", Type: resourceTypeCredential, StoredAt: storedAt},
+			[]field{{name: "Username", value: "alice"}, {name: "Password", value: "hunter2", secret: true}},
+		)
+		editor.inputs[1].SetValue("bob")
+		assert.Nil(t, m.save(editor)().(doneMsg).err)
+		assert.True(t, storedAt.Equal(storedAtOf(origin.pieces[0])), "expected the age to be kept")
+
+		editor.inputs[2].SetValue("correct horse battery staple")
+		assert.Nil(t, m.save(editor)().(doneMsg).err)
+		assert.True(t, storedAtOf(origin.pieces[1]).After(storedAt), "expected a changed password to be fresh")
+
+		var legacy = newEditorModel(
+			resource{RID: 1, Description: "GitHub", Type: resourceTypeCredential},
+			[]field{{name: "Username", value: "alice"}, {name: "Password", value: "hunter2", secret: true}},
+		)
+		legacy.inputs[1].SetValue("bob")
+		assert.Nil(t, m.save(legacy)().(doneMsg).err)
+		assert.True(t, storedAtOf(origin.pieces[2]).IsZero(), "expected an unknown age to stay unknown")
+	})
+}
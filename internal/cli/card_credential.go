@@ -20,7 +20,32 @@ type cardInfo struct {
 	holder string
 }
 
+// cardFields are names of card fields set with --field and their prompts.
+var cardFields = [...][2]string{
+	{"number", "Card number: "},
+	{"expiry", "Expiration date (MM/YY): "},
+	{"cvv", "CVV: "},
+	{"holder", "Card holder: "},
+}
+
 func cardCredential(ctx context.Context) (cardInfo, error) {
+	var s = settingsOf(ctx)
+	var values [len(cardFields)]string
+	var complete = true
+	for n, field := range cardFields {
+		values[n] = s.fields[field[0]]
+		complete = complete && values[n] != ""
+	}
+	if complete || !s.interactive {
+		for n, field := range cardFields {
+			var value, valueError = s.value(values[n], field[1])
+			if valueError != nil {
+				return cardInfo{}, valueError
+			}
+			values[n] = value
+		}
+		return cardInfo{ccn: values[0], exp: values[1], cvv: values[2], holder: values[3]}, nil
+	}
 	var m, err = tea.NewProgram(
 		newCardCredentialModel(),
 		tea.WithAltScreen(),
@@ -30,6 +55,9 @@ func cardCredential(ctx context.Context) (cardInfo, error) {
 		return cardInfo{}, err
 	}
 	var cm = m.(cardCredentialModel)
+	if cm.cancelled {
+		return cardInfo{}, fmt.Errorf("card form %w", errCancelled)
+	}
 	var info = cardInfo{
 		ccn:    cm.ccn.Value(),
 		exp:    cm.exp.Value(),
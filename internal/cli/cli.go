@@ -2,8 +2,14 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -13,6 +19,7 @@ import (
 // CLI is gophkeeper command-line interface.
 type CLI struct {
 	Gophkeeper  gophkeeper.Gophkeeper
+	Server      string // Server is the address of Gophkeeper, login sessions are saved for it.
 	CommandLine []string
 }
 
@@ -20,6 +27,9 @@ var _ runnable.Runnable = (*CLI)(nil)
 
 // Run implements runnable.Runnable.
 func (c *CLI) Run(ctx context.Context) error {
+	var whoami = &whoamiCommand{
+		gophkeeper: c.Gophkeeper,
+	}
 	var commands = map[string]command{
 		"register": &registerCommand{
 			gophkeeper: c.Gophkeeper,
@@ -51,35 +61,153 @@ func (c *CLI) Run(ctx context.Context) error {
 		"restore-card": &restoreCardCommand{
 			gophkeeper: c.Gophkeeper,
 		},
+		"copy": &copyCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"generate": &generateCommand{},
 		"delete": &deleteCommand{
 			gophkeeper: c.Gophkeeper,
 		},
+		"delete-account": &deleteAccountCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"invite": &inviteCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"export": &exportCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"import": &importCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"login": &loginCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"logout": &logoutCommand{},
+		"agent":  &agentCommand{},
+		"lock":   &lockCommand{},
+		"unlock": &unlockCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"report": &reportCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"tui": &tuiCommand{
+			gophkeeper: c.Gophkeeper,
+		},
+		"whoami": whoami,
+		"status": whoami,
 	}
+	var settings = newSettings()
+	settings.server = c.Server
 	if len(c.CommandLine) < 1 {
-		return errors.New("command not specified")
+		return c.fail(settings, usageError{errors.New("command not specified")})
 	}
 	if c.CommandLine[0] == "help" {
-		for n, c := range commands {
-			fmt.Printf("%s %s - %s\n", n, c.Help(), c.Description())
+		var names = make([]string, 0, len(commands))
+		for n := range commands {
+			names = append(names, n)
 		}
+		sort.Strings(names)
+		for _, n := range names {
+			fmt.Printf("%s %s - %s\n", n, commands[n].Help(), commands[n].Description())
+		}
+		fmt.Println("\nFlags of every command:")
+		var set = flag.NewFlagSet("help", flag.ContinueOnError)
+		set.SetOutput(os.Stdout)
+		settings.flags(set)
+		set.PrintDefaults()
 		return nil
 	}
-	if command, ok := commands[c.CommandLine[0]]; ok {
-		var (
-			input = (stack.Stack[string])(c.CommandLine[1:])
-			args  = make(stack.Stack[string], 0, len(input))
-		)
-		for len(input) > 0 {
-			args.Push(input.Pop())
+	var name = c.CommandLine[0]
+	var command, ok = commands[name]
+	if !ok {
+		return c.fail(settings, usageError{fmt.Errorf("command %s not found", name)})
+	}
+	return c.execute(ctx, settings, name, command, c.CommandLine[1:])
+}
+
+// execute parses flags of the command and executes it with the rest of the arguments.
+func (c *CLI) execute(ctx context.Context, settings *settings, name string, command command, arguments []string) error {
+	var set = flag.NewFlagSet(name, flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	settings.flags(set)
+	if f, ok := command.(flagged); ok {
+		f.Flags(set)
+	}
+	var positional, parseError = parse(set, arguments)
+	if parseError != nil {
+		return c.fail(settings, usageError{fmt.Errorf("%s: %w", usage(name, command), parseError)})
+	}
+	var args = make(stack.Stack[string], 0, len(positional))
+	for n := len(positional) - 1; n >= 0; n-- {
+		args.Push(positional[n])
+	}
+	correct, err := command.Execute(withSettings(ctx, settings), args)
+	if !correct {
+		if err == nil {
+			err = errors.New("wrong arguments")
 		}
-		correct, err := command.Execute(ctx, args)
-		if !correct {
-			fmt.Printf("%s %s\n", c.CommandLine[0], command.Help())
+		return c.fail(settings, usageError{fmt.Errorf("%s: %w", usage(name, command), err)})
+	}
+	if err != nil {
+		return c.fail(settings, fmt.Errorf("failed to execute command %s: %w", name, err))
+	}
+	if settings.json {
+		return json.NewEncoder(settings.stdout).Encode(map[string]any{
+			"ok":     true,
+			"result": settings.result,
+		})
+	}
+	return nil
+}
+
+func usage(name string, c command) string {
+	return strings.TrimSpace(name + " " + c.Help())
+}
+
+// fail returns the error of the command as *Error,
+// writing it as JSON with --json.
+func (c *CLI) fail(s *settings, err error) error {
+	var code, exitCode = classify(err)
+	var failure = &Error{
+		Code:     code,
+		ExitCode: exitCode,
+		Err:      err,
+	}
+	if !s.json {
+		return failure
+	}
+	var body = map[string]any{
+		"code":    code,
+		"message": err.Error(),
+	}
+	var policyError *gophkeeper.PasswordPolicyError
+	if errors.As(err, &policyError) {
+		body["violations"] = policyError.Violations
+	}
+	if encodeError := json.NewEncoder(s.stdout).Encode(map[string]any{"ok": false, "error": body}); encodeError == nil {
+		failure.Reported = true
+	}
+	return failure
+}
+
+// parse parses flags placed anywhere among the arguments
+// and returns the positional arguments, "--" ends the flags.
+func parse(set *flag.FlagSet, arguments []string) ([]string, error) {
+	var positional = make([]string, 0, len(arguments))
+	for {
+		if err := set.Parse(arguments); err != nil {
+			return nil, err
 		}
-		if err != nil {
-			return fmt.Errorf("failed to execute command %s: %w", c.CommandLine[0], err)
+		var rest = set.Args()
+		if consumed := len(arguments) - len(rest); consumed > 0 && arguments[consumed-1] == "--" {
+			return append(positional, rest...), nil
 		}
-		return nil
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		arguments = rest[1:]
 	}
-	return errors.New("command not found")
 }
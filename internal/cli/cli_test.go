@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	var (
+		s   = newSettings()
+		set = flag.NewFlagSet("test", flag.ContinueOnError)
+	)
+	set.SetOutput(io.Discard)
+	s.flags(set)
+	var positional, parseError = parse(set, []string{
+		"1", "--json", "--field", "username=alice", "2",
+		"--field=password=a=b", "--", "--yes",
+	})
+	assert.Nil(t, parseError)
+	assert.Equal(t, []string{"1", "2", "--yes"}, positional)
+	assert.True(t, s.json)
+	assert.False(t, s.yes)
+	assert.Equal(t, fieldValues{"username": "alice", "password": "a=b"}, s.fields)
+
+	var _, badError = parse(set, []string{"--field", "username"})
+	assert.NotNil(t, badError)
+}
+
+func TestSettings(t *testing.T) {
+	t.Run("Line", func(t *testing.T) {
+		var s = newSettings()
+		s.stdin = bufio.NewReader(strings.NewReader("alice\r\nbob"))
+		s.stderr = io.Discard
+		var first, firstError = s.line("Username: ")
+		assert.Nil(t, firstError)
+		assert.Equal(t, "alice", first)
+		var second, secondError = s.line("Username: ")
+		assert.Nil(t, secondError)
+		assert.Equal(t, "bob", second)
+		var _, eofError = s.line("Username: ")
+		assert.ErrorIs(t, eofError, io.ErrUnexpectedEOF)
+	})
+	t.Run("VaultFallsBackToPassword", func(t *testing.T) {
+		t.Setenv(vaultPasswordEnv, "")
+		var s = newSettings()
+		s.password = "hunter2"
+		var password, set, vaultError = s.vault()
+		assert.Nil(t, vaultError)
+		assert.True(t, set)
+		assert.Equal(t, "hunter2", password)
+	})
+	t.Run("Confirm", func(t *testing.T) {
+		var s = newSettings()
+		s.stdin = bufio.NewReader(strings.NewReader("YES\nno\n"))
+		s.stderr = io.Discard
+		var confirmed, _ = s.confirm("? ", "y", "yes")
+		assert.True(t, confirmed)
+		confirmed, _ = s.confirm("? ", "y", "yes")
+		assert.False(t, confirmed)
+		s.yes = true
+		confirmed, _ = s.confirm("? ", "y")
+		assert.True(t, confirmed)
+	})
+}
+
+func TestClassify(t *testing.T) {
+	for err, code := range map[error]int{
+		usageError{errors.New("expected 1 argument")}:                    ExitUsage,
+		errors.Join(errors.New("login"), gophkeeper.ErrBadCredential):    ExitBadCredential,
+		&gophkeeper.PasswordPolicyError{}:                                ExitWeakPassword,
+		errCancelled:                                                     ExitCancelled,
+		errors.Join(errors.New("server"), gophkeeper.ErrIncompatibleAPI): ExitIncompatible,
+		errors.New("unexpected"):                                         ExitError,
+	} {
+		var _, exitCode = classify(err)
+		assert.Equal(t, code, exitCode, err.Error())
+	}
+}
+
+type echoCommand struct {
+	err error
+}
+
+// Description implements command.
+func (*echoCommand) Description() string {
+	return ""
+}
+
+// Help implements command.
+func (*echoCommand) Help() string {
+	return "<word: string>"
+}
+
+// Execute implements command.
+func (e *echoCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) != 1 {
+		return false, errors.New("expected 1 argument")
+	}
+	result(ctx, args.Pop())
+	return true, e.err
+}
+
+func TestJSON(t *testing.T) {
+	t.Run("Result", func(t *testing.T) {
+		var s = newSettings()
+		var out bytes.Buffer
+		s.stdout = &out
+		var c = &CLI{}
+		assert.Nil(t, c.execute(context.Background(), s, "echo", &echoCommand{}, []string{"--json", "hello"}))
+		var envelope struct {
+			OK     bool   `json:"ok"`
+			Result string `json:"result"`
+		}
+		assert.Nil(t, json.Unmarshal(out.Bytes(), &envelope))
+		assert.True(t, envelope.OK)
+		assert.Equal(t, "hello", envelope.Result)
+	})
+	t.Run("Error", func(t *testing.T) {
+		var s = newSettings()
+		var out bytes.Buffer
+		s.stdout = &out
+		var c = &CLI{}
+		var err = c.execute(context.Background(), s, "echo", &echoCommand{err: gophkeeper.ErrResourceNotFound}, []string{"hello", "--json"})
+		var cliError *Error
+		assert.ErrorAs(t, err, &cliError)
+		assert.Equal(t, ExitNotFound, cliError.ExitCode)
+		assert.True(t, cliError.Reported)
+		var envelope struct {
+			OK    bool `json:"ok"`
+			Error struct {
+				Code string `json:"code"`
+			} `json:"error"`
+		}
+		assert.Nil(t, json.Unmarshal(out.Bytes(), &envelope))
+		assert.False(t, envelope.OK)
+		assert.Equal(t, "not_found", envelope.Error.Code)
+	})
+}
+
+func TestPasswordForms(t *testing.T) {
+	var password = strings.Repeat("x", gophkeeper.MaxPasswordBytes)
+	var authentication = newAuthenticationModel()
+	authentication.password.SetValue(password)
+	assert.Equal(t, password, authentication.password.Value())
+	var vault = newVaultPasswordModel()
+	vault.password.SetValue(password)
+	assert.Equal(t, password, vault.password.Value())
+}
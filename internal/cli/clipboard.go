@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/atotto/clipboard"
+)
+
+// defaultClearAfter is how long a copied secret stays in the clipboard.
+const defaultClearAfter = 45 * time.Second
+
+// clipboardBackend is the clipboard secrets are copied to.
+type clipboardBackend interface {
+	// ReadAll returns the text in the clipboard.
+	ReadAll() (string, error)
+
+	// WriteAll puts the text in the clipboard.
+	WriteAll(text string) error
+}
+
+// systemClipboard is the clipboard of the desktop.
+type systemClipboard struct{}
+
+var _ clipboardBackend = (*systemClipboard)(nil)
+
+// ReadAll implements clipboardBackend.
+func (systemClipboard) ReadAll() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// WriteAll implements clipboardBackend.
+func (systemClipboard) WriteAll(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+// clearClipboard empties the clipboard if it still holds the value,
+// so what was copied after it is kept.
+func clearClipboard(c clipboardBackend, value string) (bool, error) {
+	var current, readError = c.ReadAll()
+	if readError != nil {
+		return false, readError
+	}
+	if current != value {
+		return false, nil
+	}
+	return true, c.WriteAll("")
+}
+
+// copyField puts the value of the field in the clipboard and waits
+// to clear it, it is cleared earlier when the command is interrupted.
+func copyField(ctx context.Context, f field) (bool, error) {
+	var s = settingsOf(ctx)
+	if err := s.clipboard.WriteAll(f.value); err != nil {
+		return false, err
+	}
+	var name = strings.ToLower(f.name)
+	if s.clearAfter <= 0 {
+		printf(ctx, "Copied %s to the clipboard.\n", name)
+		return false, nil
+	}
+	printf(ctx, "Copied %s to the clipboard, it is cleared in %s.\n", name, s.clearAfter)
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+	var timer = time.NewTimer(s.clearAfter)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	var cleared, clearError = clearClipboard(s.clipboard, f.value)
+	if clearError != nil {
+		return false, clearError
+	}
+	if cleared {
+		printf(ctx, "The clipboard is cleared.\n")
+	} else {
+		printf(ctx, "The clipboard has changed, it is left as is.\n")
+	}
+	return cleared, nil
+}
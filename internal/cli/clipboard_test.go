@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClipboard struct {
+	text   string
+	writes int
+}
+
+var _ clipboardBackend = (*fakeClipboard)(nil)
+
+// ReadAll implements clipboardBackend.
+func (c *fakeClipboard) ReadAll() (string, error) {
+	return c.text, nil
+}
+
+// WriteAll implements clipboardBackend.
+func (c *fakeClipboard) WriteAll(text string) error {
+	c.text = text
+	c.writes++
+	return nil
+}
+
+func clipboardContext(clearAfter time.Duration) (context.Context, *fakeClipboard) {
+	var s = newSettings()
+	var clipboard = &fakeClipboard{}
+	s.clipboard = clipboard
+	s.clearAfter = clearAfter
+	s.stdout = io.Discard
+	return withSettings(context.Background(), s), clipboard
+}
+
+func TestClipboard(t *testing.T) {
+	var password = field{name: "Password", value: "hunter2", secret: true}
+	t.Run("Cleared", func(t *testing.T) {
+		var ctx, clipboard = clipboardContext(10 * time.Millisecond)
+		var cleared, copyError = copyField(ctx, password)
+		assert.Nil(t, copyError)
+		assert.True(t, cleared)
+		assert.Empty(t, clipboard.text)
+		assert.Equal(t, 2, clipboard.writes)
+	})
+	t.Run("ChangedIsKept", func(t *testing.T) {
+		var clipboard = &fakeClipboard{text: "copied after"}
+		var cleared, clearError = clearClipboard(clipboard, "hunter2")
+		assert.Nil(t, clearError)
+		assert.False(t, cleared)
+		assert.Equal(t, "copied after", clipboard.text)
+	})
+	t.Run("NeverCleared", func(t *testing.T) {
+		var ctx, clipboard = clipboardContext(0)
+		var cleared, copyError = copyField(ctx, password)
+		assert.Nil(t, copyError)
+		assert.False(t, cleared)
+		assert.Equal(t, "hunter2", clipboard.text)
+	})
+	t.Run("LookupField", func(t *testing.T) {
+		var card = cardResource{cardInfo: cardInfo{ccn: "4111111111111111", cvv: "123"}}
+		var cvv, lookupError = lookupField(card.fields(), "cvv")
+		assert.Nil(t, lookupError)
+		assert.Equal(t, "123", cvv.value)
+		var _, unknownError = lookupField(card.fields(), "password")
+		assert.ErrorContains(t, unknownError, "number, expiry, cvv, holder")
+	})
+	t.Run("Browser", func(t *testing.T) {
+		var ctx, clipboard = clipboardContext(time.Minute)
+		var m = newBrowserModel(ctx, &fakeSessionGophkeeper{}, &fakeSessionIdentity{valid: true}, "alice", "hunter2", time.Hour)
+		var updated, _ = m.Update(listedMsg{resources: []resource{{RID: 1, Type: resourceTypeCredential}}})
+		updated, _ = updated.Update(revealedMsg{rid: 1, fields: credentialResource{username: "alice", password: "hunter2"}.fields()})
+		updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+		assert.Equal(t, "hunter2", clipboard.text)
+		assert.Contains(t, updated.(browserModel).statusView(), "cleared from the clipboard in 1m0s")
+
+		updated, _ = updated.Update(browserTickMsg(time.Now().Add(2 * time.Minute)))
+		assert.Empty(t, clipboard.text)
+		assert.Nil(t, updated.(browserModel).copied)
+	})
+}
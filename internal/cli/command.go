@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"flag"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 )
@@ -11,3 +12,8 @@ type command interface {
 	Description() string
 	Execute(ctx context.Context, args stack.Stack[string]) (bool, error)
 }
+
+// flagged is a command with flags of its own.
+type flagged interface {
+	Flags(set *flag.FlagSet)
+}
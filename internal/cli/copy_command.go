@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type copyCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*copyCommand)(nil)
+
+// Description implements command.
+func (c *copyCommand) Description() string {
+	return "Copy a field of a resource to the clipboard and clear it after --clear-after."
+}
+
+// Help implements command.
+func (c *copyCommand) Help() string {
+	return "<RID: int> <field: username|password|text|number|expiry|cvv|holder>"
+}
+
+// Execute implements command.
+func (c *copyCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) != 2 {
+		return false, errors.New("expected 2 arguments")
+	}
+
+	var rid, ridError = strconv.Atoi(args.Pop())
+	if ridError != nil {
+		return false, ridError
+	}
+	var name = args.Pop()
+
+	var gophkeeperIdentity, gophkeeperIdentityError = authenticate(ctx, c.gophkeeper)
+	if gophkeeperIdentityError != nil {
+		return true, gophkeeperIdentityError
+	}
+
+	var vaultPassword, vaultPasswordError = vaultPassword(ctx)
+	if vaultPasswordError != nil {
+		return true, vaultPasswordError
+	}
+
+	var identity = identity{
+		origin: gophkeeperIdentity,
+	}
+	var resources, resourcesError = identity.List(ctx)
+	if resourcesError != nil {
+		return true, resourcesError
+	}
+	for _, r := range resources {
+		if r.RID != (gophkeeper.ResourceID)(rid) {
+			continue
+		}
+		var fields, fieldsError = reveal(ctx, identity, r, vaultPassword)
+		if fieldsError != nil {
+			return true, fieldsError
+		}
+		return true, copyResult(ctx, rid, fields, name)
+	}
+	return true, fmt.Errorf("resource (RID: %d) %w", rid, gophkeeper.ErrResourceNotFound)
+}
+
+// copyResult copies the field and reports it without the value.
+func copyResult(ctx context.Context, rid int, fields []field, name string) error {
+	var f, fieldError = lookupField(fields, name)
+	if fieldError != nil {
+		return fieldError
+	}
+	var cleared, copyError = copyField(ctx, f)
+	if copyError != nil {
+		return copyError
+	}
+	result(ctx, map[string]any{
+		"rid":     rid,
+		"field":   strings.ToLower(f.name),
+		"cleared": cleared,
+	})
+	return nil
+}
@@ -2,7 +2,7 @@ package cli
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -10,6 +10,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kerelape/gophkeeper/internal/generator"
 )
 
 type credentialModel struct {
@@ -18,19 +19,24 @@ type credentialModel struct {
 
 	username textinput.Model
 	password textinput.Model
+
+	// policy is what the password is generated with.
+	policy    generator.Policy
+	violation string
 }
 
-func newCredentialModel() credentialModel {
+func newCredentialModel(policy generator.Policy) credentialModel {
 	var m = credentialModel{
 		username: textinput.New(),
 		password: textinput.New(),
+		policy:   policy,
 	}
 	m.username.CharLimit = 32
 	m.username.Prompt = "Username: "
 	m.username.Placeholder = "type username..."
 	m.username.Focus()
 
-	m.password.CharLimit = 32
+	m.password.CharLimit = 256
 	m.password.Prompt = "Password: "
 	m.password.Placeholder = "type password..."
 	m.password.EchoMode = textinput.EchoPassword
@@ -38,8 +44,27 @@ func newCredentialModel() credentialModel {
 }
 
 func credential(ctx context.Context) (string, string, error) {
+	var s = settingsOf(ctx)
+	var username, password = s.fields["username"], s.fields["password"]
+	if username != "" && password != "" {
+		return username, password, nil
+	}
+	if !s.interactive {
+		var usernameError, passwordError error
+		if username, usernameError = s.value(username, "Username: "); usernameError != nil {
+			return "", "", usernameError
+		}
+		if password, passwordError = s.value(password, "Password: "); passwordError != nil {
+			return "", "", passwordError
+		}
+		return username, password, nil
+	}
+	var policy, policyError = generatorPolicy(s.profile)
+	if policyError != nil {
+		return "", "", policyError
+	}
 	var m, err = tea.NewProgram(
-		newCredentialModel(),
+		newCredentialModel(policy),
 		tea.WithAltScreen(),
 		tea.WithContext(ctx),
 	).Run()
@@ -47,7 +72,7 @@ func credential(ctx context.Context) (string, string, error) {
 		return "", "", err
 	}
 	if m.(credentialModel).cancelled {
-		return "", "", errors.New("credential form cancelled by user")
+		return "", "", fmt.Errorf("credential form %w", errCancelled)
 	}
 	var credential = m.(credentialModel)
 	return credential.username.Value(), credential.password.Value(), nil
@@ -95,6 +120,8 @@ func (m credentialModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			} else {
 				m.password.EchoMode = textinput.EchoPassword
 			}
+		case "ctrl+g":
+			m.generate()
 		case "ctrl+c", "esc":
 			m.cancelled = true
 			return m, tea.Quit
@@ -103,6 +130,21 @@ func (m credentialModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(usernameCmd, passwordCmd)
 }
 
+// generate fills the password with one generated with the policy.
+func (m *credentialModel) generate() {
+	var password, err = m.policy.Generate()
+	switch {
+	case err != nil:
+		m.violation = err.Error()
+	case len(password) > m.password.CharLimit:
+		m.violation = fmt.Sprintf("generated password is longer than %d characters", m.password.CharLimit)
+	default:
+		m.violation = ""
+		m.password.SetValue(password)
+		m.password.CursorEnd()
+	}
+}
+
 // View implements tea.Model.
 func (m credentialModel) View() string {
 	var help = help.New()
@@ -115,7 +157,7 @@ func (m credentialModel) View() string {
 			m.username.View(),
 			strings.Repeat(" ", 64),
 			m.password.View(),
-			strings.Repeat(" ", 64),
+			violationStyle.Width(64).Render(m.violation),
 			help.ShortHelpView(
 				[]key.Binding{
 					key.NewBinding(
@@ -126,6 +168,10 @@ func (m credentialModel) View() string {
 						key.WithHelp("[tab]", "view password"),
 						key.WithKeys("tab"),
 					),
+					key.NewBinding(
+						key.WithHelp("[ctrl+g]", "generate password"),
+						key.WithKeys("ctrl+g"),
+					),
 				},
 			),
 		),
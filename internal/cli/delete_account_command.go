@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// deleteAccountConfirmation must be typed to confirm account deletion.
+const deleteAccountConfirmation = "delete my account"
+
+type deleteAccountCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*deleteAccountCommand)(nil)
+
+// Description implements command.
+func (d *deleteAccountCommand) Description() string {
+	return "Delete your identity with all of its resources."
+}
+
+// Help implements command.
+func (d *deleteAccountCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (d *deleteAccountCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+
+	var identity, identityError = authenticate(ctx, d.gophkeeper)
+	if identityError != nil {
+		return true, identityError
+	}
+	var resources, resourcesError = identity.List(ctx)
+	if resourcesError != nil {
+		return true, resourcesError
+	}
+
+	var s = settingsOf(ctx)
+	printf(ctx, "This permanently deletes your identity and all of its %d resources.\n", len(resources))
+	var confirmed, confirmError = s.confirm(fmt.Sprintf("Type %q to confirm: ", deleteAccountConfirmation), deleteAccountConfirmation)
+	if confirmError != nil {
+		return true, confirmError
+	}
+	if !confirmed {
+		return true, fmt.Errorf("account deletion is not confirmed: %w", errCancelled)
+	}
+
+	var password, passwordError = vaultPassword(ctx)
+	if passwordError != nil {
+		return true, passwordError
+	}
+	if err := identity.DeleteIdentity(ctx, password); err != nil {
+		return true, err
+	}
+	// The identity may be used through the agent, which the session does not cover.
+	if _, err := removeSession(); err != nil {
+		return true, err
+	}
+	if _, err := lockAgent(ctx); err != nil {
+		return true, err
+	}
+
+	printf(ctx, "Your identity has been deleted.\n")
+	result(ctx, map[string]any{"deleted_resources": len(resources)})
+	return true, nil
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strconv"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
@@ -45,7 +44,8 @@ func (d *deleteCommand) Execute(ctx context.Context, args stack.Stack[string]) (
 		return true, err
 	}
 
-	fmt.Printf("Successfully deleted resource (RID: %d),\n", rid)
+	printf(ctx, "Successfully deleted resource (RID: %d),\n", rid)
+	result(ctx, map[string]any{"rid": rid})
 
 	return true, nil
 }
@@ -2,7 +2,7 @@ package cli
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
@@ -31,6 +31,13 @@ func newDescriptionModel() descriptionModel {
 }
 
 func description(ctx context.Context) (string, error) {
+	var s = settingsOf(ctx)
+	if s.description.set {
+		return s.description.value, nil
+	}
+	if !s.interactive {
+		return s.line("Description: ")
+	}
 	var m, err = tea.NewProgram(
 		newDescriptionModel(),
 		tea.WithAltScreen(),
@@ -40,7 +47,7 @@ func description(ctx context.Context) (string, error) {
 		return "", err
 	}
 	if m.(descriptionModel).cancelled {
-		return "", errors.New("user cancelled typing description")
+		return "", fmt.Errorf("typing description %w", errCancelled)
 	}
 	return m.(descriptionModel).description.Value(), nil
 }
@@ -65,7 +72,7 @@ func (m descriptionModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+s":
 			return m, tea.Quit
 		case "ctrl+c", "esc":
-			m.cancelled = false
+			m.cancelled = true
 			return m, tea.Quit
 		}
 	}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"net"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// Exit codes of the CLI.
+const (
+	ExitError         = 1
+	ExitUsage         = 2
+	ExitBadCredential = 3
+	ExitNotFound      = 4
+	ExitConflict      = 5
+	ExitWeakPassword  = 6
+	ExitForbidden     = 7
+	ExitUnavailable   = 8
+	ExitIncompatible  = 9
+	ExitCancelled     = 130
+)
+
+// Error is a failure of a command.
+type Error struct {
+	Code     string // Code is a machine-readable code of the failure.
+	ExitCode int
+	Reported bool // Reported tells whether the error is already written as JSON.
+	Err      error
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the failure.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// usageError is an error of wrong arguments or flags.
+type usageError struct {
+	err error
+}
+
+// Error implements error.
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the cause.
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// classify returns the code and the exit code of err.
+func classify(err error) (string, int) {
+	var (
+		usage    usageError
+		netError net.Error
+	)
+	switch {
+	case errors.As(err, &usage):
+		return "usage", ExitUsage
+	case errors.Is(err, errCancelled):
+		return "cancelled", ExitCancelled
+	case errors.Is(err, gophkeeper.ErrWeakPassword):
+		return "weak_password", ExitWeakPassword
+	case errors.Is(err, gophkeeper.ErrBadCredential):
+		return "bad_credential", ExitBadCredential
+	case errors.Is(err, gophkeeper.ErrResourceNotFound), errors.Is(err, gophkeeper.ErrIdentityNotFound):
+		return "not_found", ExitNotFound
+	case errors.Is(err, gophkeeper.ErrIdentityDuplicate):
+		return "conflict", ExitConflict
+	case errors.Is(err, gophkeeper.ErrForbidden),
+		errors.Is(err, gophkeeper.ErrRegistrationClosed),
+		errors.Is(err, gophkeeper.ErrBadInvite):
+		return "forbidden", ExitForbidden
+	case errors.Is(err, gophkeeper.ErrUnavailable), errors.As(err, &netError):
+		return "unavailable", ExitUnavailable
+	case errors.Is(err, gophkeeper.ErrIncompatibleAPI):
+		return "incompatible_api", ExitIncompatible
+	default:
+		return "error", ExitError
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	vaultarchive "github.com/kerelape/gophkeeper/internal/vault_archive"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type exportCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*exportCommand)(nil)
+
+// Description implements command.
+func (e *exportCommand) Description() string {
+	return "Export all resources to an archive encrypted with a passphrase."
+}
+
+// Help implements command.
+func (e *exportCommand) Help() string {
+	return "<path: string>"
+}
+
+// Execute implements command.
+func (e *exportCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) != 1 {
+		return false, errors.New("expected 1 argument")
+	}
+	var path = args.Pop()
+
+	var identity, identityError = authenticate(ctx, e.gophkeeper)
+	if identityError != nil {
+		return true, identityError
+	}
+	var vaultPassword, vaultPasswordError = vaultPassword(ctx)
+	if vaultPasswordError != nil {
+		return true, vaultPasswordError
+	}
+	var passphrase, passphraseError = passphrase(ctx, true)
+	if passphraseError != nil {
+		return true, passphraseError
+	}
+
+	var profile, profileError = identity.Profile(ctx)
+	if profileError != nil {
+		return true, profileError
+	}
+	var resources, resourcesError = identity.List(ctx)
+	if resourcesError != nil {
+		return true, resourcesError
+	}
+	var manifest = vaultarchive.Manifest{
+		CreatedAt: time.Now().UTC(),
+		Username:  profile.Username,
+		Entries:   make([]vaultarchive.Entry, 0, len(resources)),
+	}
+	for _, r := range resources {
+		manifest.Entries = append(
+			manifest.Entries,
+			vaultarchive.Entry{ID: r.ID, Type: r.Type, Meta: r.Meta},
+		)
+	}
+
+	var file, fileError = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if fileError != nil {
+		return true, fileError
+	}
+	if err := export(ctx, file, identity, manifest, vaultPassword, passphrase); err != nil {
+		file.Close()
+		os.Remove(path)
+		return true, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return true, err
+	}
+
+	printf(ctx, "Exported %d resources to %s\n", len(manifest.Entries), path)
+	result(ctx, map[string]any{"path": path, "resources": len(manifest.Entries)})
+	return true, nil
+}
+
+func export(
+	ctx context.Context,
+	file *os.File,
+	identity gophkeeper.Identity,
+	manifest vaultarchive.Manifest,
+	vaultPassword, passphrase string,
+) error {
+	var buffer = bufio.NewWriter(file)
+	var writer, writerError = vaultarchive.NewWriter(buffer, passphrase, manifest)
+	if writerError != nil {
+		return writerError
+	}
+	for _, entry := range manifest.Entries {
+		switch entry.Type {
+		case gophkeeper.ResourceTypePiece:
+			var piece, pieceError = identity.RestorePiece(ctx, entry.ID, vaultPassword)
+			if pieceError != nil {
+				return fmt.Errorf("restore resource (RID: %d): %w", entry.ID, pieceError)
+			}
+			if err := writer.WriteEntry(bytes.NewReader(piece.Content)); err != nil {
+				return err
+			}
+		case gophkeeper.ResourceTypeBlob:
+			var blob, blobError = identity.RestoreBlob(ctx, entry.ID, vaultPassword)
+			if blobError != nil {
+				return fmt.Errorf("restore resource (RID: %d): %w", entry.ID, blobError)
+			}
+			var writeError = writer.WriteEntry(blob.Content)
+			blob.Content.Close()
+			if writeError != nil {
+				return writeError
+			}
+		default:
+			return fmt.Errorf("resource (RID: %d) is of unknown type %d", entry.ID, entry.Type)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return buffer.Flush()
+}
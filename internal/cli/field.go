@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// field is a decrypted field of a resource.
+type field struct {
+	name   string
+	value  string
+	secret bool
+}
+
+func (c credentialResource) fields() []field {
+	return []field{
+		{name: "Username", value: c.username},
+		{name: "Password", value: c.password, secret: true},
+	}
+}
+
+func (t textResource) fields() []field {
+	return []field{{name: "Text", value: t.content}}
+}
+
+func (c cardResource) fields() []field {
+	return []field{
+		{name: "Number", value: c.ccn, secret: true},
+		{name: "Expiry", value: c.exp},
+		{name: "CVV", value: c.cvv, secret: true},
+		{name: "Holder", value: c.holder},
+	}
+}
+
+// lookupField finds the field by its name, in any case.
+func lookupField(fields []field, name string) (field, error) {
+	var names = make([]string, 0, len(fields))
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, nil
+		}
+		names = append(names, strings.ToLower(f.name))
+	}
+	if len(names) == 0 {
+		return field{}, errors.New("the resource has no fields")
+	}
+	return field{}, fmt.Errorf("unknown field %q, expected one of: %s", name, strings.Join(names, ", "))
+}
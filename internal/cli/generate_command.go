@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+
+	"github.com/kerelape/gophkeeper/internal/generator"
+	"github.com/kerelape/gophkeeper/internal/stack"
+)
+
+type generateCommand struct {
+	// set is kept to tell flags overriding the profile's policy.
+	set    *flag.FlagSet
+	policy generator.Policy
+	save   bool
+	copy   bool
+}
+
+var (
+	_ command = (*generateCommand)(nil)
+	_ flagged = (*generateCommand)(nil)
+)
+
+// Flags implements flagged.
+func (g *generateCommand) Flags(set *flag.FlagSet) {
+	g.set = set
+	var defaults = generator.DefaultPolicy
+	set.IntVar(&g.policy.Length, "length", defaults.Length, "Number of characters of a password")
+	set.BoolVar(&g.policy.Lower, "lower", defaults.Lower, "Use lowercase letters")
+	set.BoolVar(&g.policy.Upper, "upper", defaults.Upper, "Use uppercase letters")
+	set.BoolVar(&g.policy.Digits, "digits", defaults.Digits, "Use digits")
+	set.BoolVar(&g.policy.Symbols, "symbols", defaults.Symbols, "Use symbols")
+	set.BoolVar(&g.policy.ExcludeAmbiguous, "exclude-ambiguous", defaults.ExcludeAmbiguous, "Exclude characters like 0, O, l and 1")
+	set.IntVar(&g.policy.Words, "words", defaults.Words, "Generate a passphrase of this many words instead")
+	set.StringVar(&g.policy.Separator, "separator", defaults.Separator, "Separator of the passphrase's words")
+	set.BoolVar(&g.save, "save", false, "Save the policy to the profile")
+	set.BoolVar(&g.copy, "copy", false, "Copy the password to the clipboard instead of printing it")
+}
+
+// Description implements command.
+func (g *generateCommand) Description() string {
+	return "Generate a password or a passphrase with the profile's policy, flags override it."
+}
+
+// Help implements command.
+func (g *generateCommand) Help() string {
+	return "[--length: int] [--lower] [--upper] [--digits] [--symbols] [--exclude-ambiguous] [--words: int] [--separator: string] [--save] [--copy]"
+}
+
+// Execute implements command.
+func (g *generateCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	var s = settingsOf(ctx)
+	var policy, policyError = generatorPolicy(s.profile)
+	if policyError != nil {
+		return true, policyError
+	}
+	g.override(&policy)
+	if err := policy.Validate(); err != nil {
+		return false, err
+	}
+	if g.save {
+		if err := saveGeneratorPolicy(s.profile, policy); err != nil {
+			return true, err
+		}
+	}
+
+	var password, passwordError = policy.Generate()
+	if passwordError != nil {
+		return true, passwordError
+	}
+	if g.copy {
+		var cleared, copyError = copyField(ctx, field{name: "Password", value: password, secret: true})
+		if copyError != nil {
+			return true, copyError
+		}
+		result(ctx, map[string]any{
+			"entropy": policy.Entropy(),
+			"cleared": cleared,
+		})
+		return true, nil
+	}
+	printf(ctx, "%s\n", password)
+	result(ctx, map[string]any{
+		"password": password,
+		"entropy":  policy.Entropy(),
+	})
+	return true, nil
+}
+
+// override sets fields of the policy given with flags.
+func (g *generateCommand) override(policy *generator.Policy) {
+	if g.set == nil {
+		return
+	}
+	g.set.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "length":
+			policy.Length = g.policy.Length
+		case "lower":
+			policy.Lower = g.policy.Lower
+		case "upper":
+			policy.Upper = g.policy.Upper
+		case "digits":
+			policy.Digits = g.policy.Digits
+		case "symbols":
+			policy.Symbols = g.policy.Symbols
+		case "exclude-ambiguous":
+			policy.ExcludeAmbiguous = g.policy.ExcludeAmbiguous
+		case "words":
+			policy.Words = g.policy.Words
+		case "separator":
+			policy.Separator = g.policy.Separator
+		}
+	})
+}
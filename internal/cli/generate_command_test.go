@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kerelape/gophkeeper/internal/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	var generate = func(t *testing.T, args ...string) (string, float64) {
+		var s = newSettings()
+		var out bytes.Buffer
+		s.stdout = &out
+		var c = &CLI{}
+		assert.Nil(t, c.execute(context.Background(), s, "generate", &generateCommand{}, append(args, "--json")))
+		var envelope struct {
+			Result struct {
+				Password string  `json:"password"`
+				Entropy  float64 `json:"entropy"`
+			} `json:"result"`
+		}
+		assert.Nil(t, json.Unmarshal(out.Bytes(), &envelope))
+		return envelope.Result.Password, envelope.Result.Entropy
+	}
+	t.Run("Default", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		var password, entropy = generate(t)
+		assert.Len(t, password, generator.DefaultPolicy.Length)
+		assert.Equal(t, generator.DefaultPolicy.Entropy(), entropy)
+	})
+	t.Run("Flags", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		var password, _ = generate(t, "--length", "12", "--symbols=false", "--exclude-ambiguous")
+		assert.Len(t, password, 12)
+		assert.False(t, strings.ContainsAny(password, generator.Symbols+generator.Ambiguous))
+	})
+	t.Run("Profile", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		generate(t, "--profile", "pin", "--length", "6", "--lower=false", "--upper=false", "--symbols=false", "--save")
+
+		var pin, _ = generate(t, "--profile", "pin")
+		assert.Len(t, pin, 6)
+		assert.Equal(t, "", strings.Trim(pin, generator.Digits))
+		var passphrase, _ = generate(t, "--profile", "pin", "--words", "4", "--separator", " ")
+		assert.Len(t, strings.Fields(passphrase), 4)
+		var password, _ = generate(t)
+		assert.Len(t, password, generator.DefaultPolicy.Length, "other profiles must keep the default policy")
+	})
+	t.Run("Form", func(t *testing.T) {
+		var m = newCredentialModel(generator.Policy{Words: 3, Separator: "-"})
+		var updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+		assert.Len(t, strings.Split(updated.(credentialModel).password.Value(), "-"), 3)
+
+		m = newCredentialModel(generator.Policy{Length: 20})
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+		assert.Empty(t, updated.(credentialModel).password.Value())
+		assert.NotEmpty(t, updated.(credentialModel).violation)
+	})
+}
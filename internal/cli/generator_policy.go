@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+
+	"github.com/kerelape/gophkeeper/internal/generator"
+)
+
+// defaultProfile is the profile used unless --profile is set.
+const defaultProfile = "default"
+
+// generatorPoliciesPath returns the path of the file with policies by profile.
+func generatorPoliciesPath() (string, error) {
+	return configPath("generator.json")
+}
+
+func loadGeneratorPolicies() (map[string]generator.Policy, error) {
+	var path, pathError = generatorPoliciesPath()
+	if pathError != nil {
+		return nil, pathError
+	}
+	var policies = make(map[string]generator.Policy)
+	var content, readError = os.ReadFile(path)
+	if errors.Is(readError, fs.ErrNotExist) {
+		return policies, nil
+	}
+	if readError != nil {
+		return nil, readError
+	}
+	if err := json.Unmarshal(content, &policies); err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// generatorPolicy returns the policy of the profile,
+// generator.DefaultPolicy if it has none.
+func generatorPolicy(profile string) (generator.Policy, error) {
+	var policies, policiesError = loadGeneratorPolicies()
+	if policiesError != nil {
+		return generator.Policy{}, policiesError
+	}
+	if policy, ok := policies[profile]; ok {
+		return policy, nil
+	}
+	return generator.DefaultPolicy, nil
+}
+
+// saveGeneratorPolicy sets the policy of the profile, keeping other profiles.
+func saveGeneratorPolicy(profile string, policy generator.Policy) error {
+	var policies, policiesError = loadGeneratorPolicies()
+	if policiesError != nil {
+		return policiesError
+	}
+	policies[profile] = policy
+	var content, marshalError = json.MarshalIndent(policies, "", "  ")
+	if marshalError != nil {
+		return marshalError
+	}
+	var path, pathError = generatorPoliciesPath()
+	if pathError != nil {
+		return pathError
+	}
+	return writeConfig(path, content)
+}
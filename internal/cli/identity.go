@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
+	"time"
 
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
@@ -42,6 +44,8 @@ type resource struct {
 	RID         gophkeeper.ResourceID
 	Description string
 	Type        resourceType
+	// StoredAt is zero for resources stored before it was recorded.
+	StoredAt time.Time
 }
 
 type (
@@ -49,6 +53,9 @@ type (
 		description string
 		username    string
 		password    string
+		// storedAt is when the password was stored, nil means now
+		// and a zero time means unknown, it is left out of the meta.
+		storedAt *time.Time
 	}
 	textResource struct {
 		description string
@@ -76,24 +83,31 @@ func (i identity) List(ctx context.Context) ([]resource, error) {
 		var meta struct {
 			Type        resourceType `json:"type"`
 			Description string       `json:"description"`
+			StoredAt    time.Time    `json:"stored_at"`
 		}
 		if err := json.Unmarshal(([]byte)(r.Meta), &meta); err != nil {
 			continue
 		}
 		resource.Type = meta.Type
 		resource.Description = meta.Description
+		resource.StoredAt = meta.StoredAt
 		result = append(result, resource)
 	}
 	return result, nil
 }
 
 func (i identity) StoreCredential(ctx context.Context, cred credentialResource, vaultPassword string) (gophkeeper.ResourceID, error) {
-	var meta, metaError = json.Marshal(
-		map[string]any{
-			"type":        (int)(resourceTypeCredential),
-			"description": cred.description,
-		},
-	)
+	var fields = map[string]any{
+		"type":        (int)(resourceTypeCredential),
+		"description": cred.description,
+	}
+	switch {
+	case cred.storedAt == nil:
+		fields["stored_at"] = time.Now().UTC()
+	case !cred.storedAt.IsZero():
+		fields["stored_at"] = cred.storedAt.UTC()
+	}
+	var meta, metaError = json.Marshal(fields)
 	if metaError != nil {
 		return -1, metaError
 	}
@@ -151,6 +165,7 @@ func (i identity) StoreText(ctx context.Context, resource textResource, vaultPas
 		map[string]any{
 			"type":        (int)(resourceTypeText),
 			"description": resource.description,
+			"stored_at":   time.Now().UTC(),
 		},
 	)
 	if metaError != nil {
@@ -188,24 +203,30 @@ func (i identity) RestoreText(ctx context.Context, rid gophkeeper.ResourceID, va
 }
 
 func (i identity) StoreFile(ctx context.Context, resource fileResource, vaultPassword string) (gophkeeper.ResourceID, error) {
+	var file, fileError = os.Open(resource.path)
+	if fileError != nil {
+		return -1, fileError
+	}
+	return i.StoreFileContent(ctx, resource.description, file, vaultPassword)
+}
+
+// StoreFileContent stores content of a file, closing it.
+func (i identity) StoreFileContent(ctx context.Context, description string, content io.ReadCloser, vaultPassword string) (gophkeeper.ResourceID, error) {
 	var meta, metaError = json.Marshal(
 		map[string]any{
 			"type":        (int)(resourceTypeFile),
-			"description": resource.description,
+			"description": description,
+			"stored_at":   time.Now().UTC(),
 		},
 	)
 	if metaError != nil {
+		content.Close()
 		return -1, metaError
 	}
 
-	var file, fileError = os.Open(resource.path)
-	if fileError != nil {
-		return -1, fileError
-	}
-
 	var blob = gophkeeper.Blob{
 		Meta:    (string)(meta),
-		Content: file,
+		Content: content,
 	}
 	var rid, ridError = i.origin.StoreBlob(ctx, blob, vaultPassword)
 	if ridError != nil {
@@ -256,6 +277,7 @@ func (i identity) StoreCard(ctx context.Context, resource cardResource, vaultPas
 		map[string]any{
 			"type":        (int)(resourceTypeCard),
 			"description": resource.description,
+			"stored_at":   time.Now().UTC(),
 		},
 	)
 	if metaError != nil {
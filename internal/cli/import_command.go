@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/importer"
+	"github.com/kerelape/gophkeeper/internal/stack"
+	vaultarchive "github.com/kerelape/gophkeeper/internal/vault_archive"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type importCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+
+	format string
+	dryRun bool
+}
+
+var (
+	_ command = (*importCommand)(nil)
+	_ flagged = (*importCommand)(nil)
+)
+
+// Flags implements flagged.
+func (i *importCommand) Flags(set *flag.FlagSet) {
+	set.StringVar(&i.format, "format", "", "Format of the file: archive, keepass, bitwarden, 1password or browser")
+	set.BoolVar(&i.dryRun, "dry-run", false, "Show what would be imported without storing anything")
+	set.BoolVar(&i.dryRun, "n", false, "Shorthand for --dry-run")
+}
+
+// Description implements command.
+func (i *importCommand) Description() string {
+	return "Import resources from an exported archive, skipping duplicates, or from an export of another password manager."
+}
+
+// Help implements command.
+func (i *importCommand) Help() string {
+	return "<path: string> [--format: archive|keepass|bitwarden|1password|browser] [--dry-run]"
+}
+
+// Execute implements command.
+func (i *importCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) != 1 {
+		return false, errors.New("expected 1 path")
+	}
+	var (
+		path   = args.Pop()
+		format = i.format
+		dryRun = i.dryRun
+	)
+
+	if format == "" {
+		var detected, detectError = detectFormat(path)
+		if detectError != nil {
+			return true, detectError
+		}
+		format = detected
+	}
+	if format != archiveFormat {
+		return true, i.importExport(ctx, path, (importer.Format)(format), dryRun)
+	}
+	return true, i.importArchive(ctx, path, dryRun)
+}
+
+// archiveFormat is the format of archives written by export.
+const archiveFormat = "archive"
+
+// detectFormat guesses the format of the file to import.
+func detectFormat(path string) (string, error) {
+	var file, fileError = os.Open(path)
+	if fileError != nil {
+		return "", fileError
+	}
+	defer file.Close()
+	var head = make([]byte, 512)
+	var n, readError = io.ReadFull(file, head)
+	if readError != nil && !errors.Is(readError, io.ErrUnexpectedEOF) && !errors.Is(readError, io.EOF) {
+		return "", readError
+	}
+	if vaultarchive.IsArchive(head[:n]) {
+		return archiveFormat, nil
+	}
+	var format, formatError = importer.Detect(path, head[:n])
+	if formatError != nil {
+		return "", fmt.Errorf("%w, set it with --format", formatError)
+	}
+	return (string)(format), nil
+}
+
+func (i *importCommand) importArchive(ctx context.Context, path string, dryRun bool) error {
+	var passphrase, passphraseError = passphrase(ctx, false)
+	if passphraseError != nil {
+		return passphraseError
+	}
+	// The whole archive is verified before anything is stored.
+	var manifest, digests, verifyError = verifyArchive(path, passphrase)
+	if verifyError != nil {
+		return verifyError
+	}
+
+	var identity, identityError = authenticate(ctx, i.gophkeeper)
+	if identityError != nil {
+		return identityError
+	}
+	var vaultPassword, vaultPasswordError = vaultPassword(ctx)
+	if vaultPasswordError != nil {
+		return vaultPasswordError
+	}
+	var resources, resourcesError = identity.List(ctx)
+	if resourcesError != nil {
+		return resourcesError
+	}
+	var duplicates = duplicateFinder{
+		identity:   identity,
+		password:   vaultPassword,
+		candidates: make(map[duplicateKey][]gophkeeper.ResourceID),
+		digests:    make(map[gophkeeper.ResourceID]string),
+		archived:   make(map[duplicateKey]map[string]gophkeeper.ResourceID),
+	}
+	for _, r := range resources {
+		var key = duplicateKey{resourceType: r.Type, meta: r.Meta}
+		duplicates.candidates[key] = append(duplicates.candidates[key], r.ID)
+	}
+
+	printf(ctx, "Archive of %s created at %s\n", manifest.Username, manifest.CreatedAt.Local().Format(time.DateTime))
+	var skip = make([]bool, len(manifest.Entries))
+	for n, entry := range manifest.Entries {
+		var duplicate, found, findError = duplicates.find(ctx, entry, digests[n])
+		if findError != nil {
+			return findError
+		}
+		skip[n] = found
+		if found {
+			printf(ctx, "Skip %s: duplicate of %s\n", describe(entry), duplicate)
+		} else if dryRun {
+			printf(ctx, "Import %s\n", describe(entry))
+		}
+	}
+	var skipped = 0
+	for _, s := range skip {
+		if s {
+			skipped++
+		}
+	}
+	if dryRun {
+		printf(ctx, "Dry run: %d resources would be imported, %d duplicates skipped\n", len(skip)-skipped, skipped)
+		result(ctx, map[string]any{"imported": len(skip) - skipped, "skipped": skipped, "dry_run": true})
+		return nil
+	}
+
+	var imported, importError = storeArchive(ctx, path, passphrase, identity, vaultPassword, skip)
+	if importError != nil {
+		return fmt.Errorf("imported %d resources: %w", imported, importError)
+	}
+	printf(ctx, "Imported %d resources, %d duplicates skipped\n", imported, skipped)
+	result(ctx, map[string]any{"imported": imported, "skipped": skipped, "dry_run": false})
+	return nil
+}
+
+// verifyArchive reads the whole archive and returns
+// its manifest and the digest of every entry's content.
+func verifyArchive(path, passphrase string) (vaultarchive.Manifest, []string, error) {
+	var file, fileError = os.Open(path)
+	if fileError != nil {
+		return vaultarchive.Manifest{}, nil, fileError
+	}
+	defer file.Close()
+	var reader, readerError = vaultarchive.NewReader(bufio.NewReader(file), passphrase)
+	if readerError != nil {
+		return vaultarchive.Manifest{}, nil, readerError
+	}
+	var digests = make([]string, 0, len(reader.Manifest().Entries))
+	for {
+		var _, content, nextError = reader.Next()
+		if errors.Is(nextError, io.EOF) {
+			break
+		}
+		if nextError != nil {
+			return vaultarchive.Manifest{}, nil, nextError
+		}
+		if _, err := io.Copy(io.Discard, content); err != nil {
+			return vaultarchive.Manifest{}, nil, err
+		}
+		digests = append(digests, (string)(content.Sum()))
+	}
+	return reader.Manifest(), digests, nil
+}
+
+// storeArchive stores every entry of the archive that is not skipped
+// and returns the number of resources stored.
+func storeArchive(
+	ctx context.Context,
+	path, passphrase string,
+	identity gophkeeper.Identity,
+	vaultPassword string,
+	skip []bool,
+) (int, error) {
+	var file, fileError = os.Open(path)
+	if fileError != nil {
+		return 0, fileError
+	}
+	defer file.Close()
+	var reader, readerError = vaultarchive.NewReader(bufio.NewReader(file), passphrase)
+	if readerError != nil {
+		return 0, readerError
+	}
+	var imported = 0
+	for n := 0; ; n++ {
+		var entry, content, nextError = reader.Next()
+		if errors.Is(nextError, io.EOF) {
+			return imported, nil
+		}
+		if nextError != nil {
+			return imported, nextError
+		}
+		if n >= len(skip) {
+			return imported, errors.New("archive changed while importing")
+		}
+		if skip[n] {
+			continue
+		}
+		switch entry.Type {
+		case gophkeeper.ResourceTypePiece:
+			var data, readError = io.ReadAll(content)
+			if readError != nil {
+				return imported, readError
+			}
+			var piece = gophkeeper.Piece{Content: data, Meta: entry.Meta}
+			if _, err := identity.StorePiece(ctx, piece, vaultPassword); err != nil {
+				return imported, fmt.Errorf("store %s: %w", describe(entry), err)
+			}
+		case gophkeeper.ResourceTypeBlob:
+			var blob = gophkeeper.Blob{Content: io.NopCloser(content), Meta: entry.Meta}
+			if _, err := identity.StoreBlob(ctx, blob, vaultPassword); err != nil {
+				return imported, fmt.Errorf("store %s: %w", describe(entry), err)
+			}
+		default:
+			return imported, fmt.Errorf("%s is of unknown type %d", describe(entry), entry.Type)
+		}
+		imported++
+	}
+}
+
+type duplicateKey struct {
+	resourceType gophkeeper.ResourceType
+	meta         string
+}
+
+// duplicateFinder finds resources with the same type, meta and content
+// among stored resources and entries of the archive imported before.
+type duplicateFinder struct {
+	identity gophkeeper.Identity
+	password string
+
+	candidates map[duplicateKey][]gophkeeper.ResourceID
+	digests    map[gophkeeper.ResourceID]string
+	archived   map[duplicateKey]map[string]gophkeeper.ResourceID
+}
+
+// find returns a description of the duplicate of the entry.
+func (d *duplicateFinder) find(ctx context.Context, entry vaultarchive.Entry, digest string) (string, bool, error) {
+	var key = duplicateKey{resourceType: entry.Type, meta: entry.Meta}
+	for _, rid := range d.candidates[key] {
+		var stored, digestError = d.digest(ctx, rid, entry.Type)
+		if digestError != nil {
+			return "", false, digestError
+		}
+		if stored == digest {
+			return fmt.Sprintf("stored resource (RID: %d)", rid), true, nil
+		}
+	}
+	if rid, ok := d.archived[key][digest]; ok {
+		return fmt.Sprintf("archived resource (RID: %d)", rid), true, nil
+	}
+	if d.archived[key] == nil {
+		d.archived[key] = make(map[string]gophkeeper.ResourceID)
+	}
+	d.archived[key][digest] = entry.ID
+	return "", false, nil
+}
+
+func (d *duplicateFinder) digest(ctx context.Context, rid gophkeeper.ResourceID, resourceType gophkeeper.ResourceType) (string, error) {
+	if digest, ok := d.digests[rid]; ok {
+		return digest, nil
+	}
+	var hash = sha256.New()
+	switch resourceType {
+	case gophkeeper.ResourceTypePiece:
+		var piece, pieceError = d.identity.RestorePiece(ctx, rid, d.password)
+		if pieceError != nil {
+			return "", pieceError
+		}
+		hash.Write(piece.Content)
+	case gophkeeper.ResourceTypeBlob:
+		var blob, blobError = d.identity.RestoreBlob(ctx, rid, d.password)
+		if blobError != nil {
+			return "", blobError
+		}
+		var _, copyError = io.Copy(hash, blob.Content)
+		blob.Content.Close()
+		if copyError != nil {
+			return "", copyError
+		}
+	}
+	d.digests[rid] = (string)(hash.Sum(nil))
+	return d.digests[rid], nil
+}
+
+// describe describes the archived resource by its meta.
+func describe(entry vaultarchive.Entry) string {
+	var meta struct {
+		Type        resourceType `json:"type"`
+		Description string       `json:"description"`
+	}
+	if err := json.Unmarshal(([]byte)(entry.Meta), &meta); err != nil || meta.Type < resourceTypeCredential || meta.Type > resourceTypeCard {
+		return fmt.Sprintf("resource (RID: %d)", entry.ID)
+	}
+	return fmt.Sprintf(
+		"%s (RID: %d) %q",
+		meta.Type.String(), entry.ID,
+		strings.ReplaceAll(meta.Description, "\n", " "),
+	)
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kerelape/gophkeeper/internal/importer"
+)
+
+// importExport imports an export of another password manager.
+func (i *importCommand) importExport(ctx context.Context, path string, format importer.Format, dryRun bool) error {
+	var file, fileError = os.Open(path)
+	if fileError != nil {
+		return fileError
+	}
+	var parsed, parseError = importer.Parse(format, bufio.NewReader(file))
+	file.Close()
+	if parseError != nil {
+		return parseError
+	}
+
+	preview(ctx, parsed)
+	if dryRun || len(parsed.Records) == 0 {
+		result(ctx, map[string]any{"imported": len(parsed.Records), "skipped": len(parsed.Skipped), "dry_run": true})
+		return nil
+	}
+	var confirmed, confirmError = settingsOf(ctx).confirm(fmt.Sprintf("Create %d resources? [y/N]: ", len(parsed.Records)), "y", "yes")
+	if confirmError != nil {
+		return confirmError
+	}
+	if !confirmed {
+		return fmt.Errorf("import is not confirmed: %w", errCancelled)
+	}
+
+	var gophkeeperIdentity, identityError = authenticate(ctx, i.gophkeeper)
+	if identityError != nil {
+		return identityError
+	}
+	var vaultPassword, vaultPasswordError = vaultPassword(ctx)
+	if vaultPasswordError != nil {
+		return vaultPasswordError
+	}
+	var identity = identity{
+		origin: gophkeeperIdentity,
+	}
+	for n, record := range parsed.Records {
+		if err := storeRecord(ctx, identity, record, vaultPassword); err != nil {
+			return fmt.Errorf("imported %d resources: store %s: %w", n, recordTitle(record), err)
+		}
+	}
+	printf(ctx, "Imported %d resources\n", len(parsed.Records))
+	result(ctx, map[string]any{"imported": len(parsed.Records), "skipped": len(parsed.Skipped), "dry_run": false})
+	return nil
+}
+
+// preview prints resources to be created and what is skipped,
+// without any secrets.
+func preview(ctx context.Context, result importer.Result) {
+	var skippedFields = make(map[string]int)
+	for _, record := range result.Records {
+		printf(ctx, "Create %s\n", recordTitle(record))
+		for _, field := range record.Skipped {
+			skippedFields[field]++
+		}
+	}
+	for _, skipped := range result.Skipped {
+		printf(ctx, "Skip entry %q: %s\n", skipped.Entry, skipped.Reason)
+	}
+	var fields = make([]string, 0, len(skippedFields))
+	for field := range skippedFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		printf(ctx, "Skip field %q of %d entries: it cannot be mapped\n", field, skippedFields[field])
+	}
+	printf(ctx,
+		"%d resources to create, %d entries and %d fields skipped\n",
+		len(result.Records), len(result.Skipped), len(fields),
+	)
+}
+
+func recordTitle(record importer.Record) string {
+	var kind = map[importer.Kind]resourceType{
+		importer.KindCredential: resourceTypeCredential,
+		importer.KindText:       resourceTypeText,
+		importer.KindCard:       resourceTypeCard,
+		importer.KindFile:       resourceTypeFile,
+	}[record.Kind]
+	return fmt.Sprintf("%s %q", kind.String(), strings.ReplaceAll(record.Description, "\n", " "))
+}
+
+func storeRecord(ctx context.Context, identity identity, record importer.Record, vaultPassword string) error {
+	var err error
+	switch record.Kind {
+	case importer.KindCredential:
+		var resource = credentialResource{
+			description: record.Description,
+			username:    record.Username,
+			password:    record.Password,
+		}
+		_, err = identity.StoreCredential(ctx, resource, vaultPassword)
+	case importer.KindText:
+		var resource = textResource{
+			description: record.Description,
+			content:     record.Text,
+		}
+		_, err = identity.StoreText(ctx, resource, vaultPassword)
+	case importer.KindCard:
+		var resource = cardResource{
+			description: record.Description,
+			cardInfo: cardInfo{
+				ccn:    record.Card.Number,
+				exp:    record.Card.Expiry,
+				cvv:    record.Card.CVV,
+				holder: record.Card.Holder,
+			},
+		}
+		_, err = identity.StoreCard(ctx, resource, vaultPassword)
+	case importer.KindFile:
+		var content = io.NopCloser(bytes.NewReader(record.File.Content))
+		_, err = identity.StoreFileContent(ctx, record.Description, content, vaultPassword)
+	default:
+		err = fmt.Errorf("unknown kind %d", record.Kind)
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type inviteCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*inviteCommand)(nil)
+
+// Description implements command.
+func (i *inviteCommand) Description() string {
+	return "Create a single-use invite to register a new identity."
+}
+
+// Help implements command.
+func (i *inviteCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (i *inviteCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+
+	var identity, identityError = authenticate(ctx, i.gophkeeper)
+	if identityError != nil {
+		return true, identityError
+	}
+	var invite, inviteError = identity.Invite(ctx)
+	if inviteError != nil {
+		if errors.Is(inviteError, gophkeeper.ErrForbidden) {
+			return true, fmt.Errorf("you are not allowed to invite: %w", inviteError)
+		}
+		return true, inviteError
+	}
+
+	printf(ctx, "Invite: %s\n", invite.Token)
+	result(ctx, map[string]any{"token": invite.Token, "expires_at": invite.ExpiresAt})
+	printf(ctx, "Expires at %s. Register with: register %s\n", invite.ExpiresAt.Local().Format(time.DateTime), invite.Token)
+	return true, nil
+}
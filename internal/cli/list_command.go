@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strings"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
@@ -42,14 +41,21 @@ func (l *listCommand) Execute(ctx context.Context, args stack.Stack[string]) (bo
 	if resourcesError != nil {
 		return true, resourcesError
 	}
-	fmt.Printf("%d resources found\n", len(resources))
+	printf(ctx, "%d resources found\n", len(resources))
+	var listed = make([]map[string]any, 0, len(resources))
 	for _, r := range resources {
-		fmt.Printf(
+		listed = append(listed, map[string]any{
+			"rid":         r.RID,
+			"type":        r.Type.String(),
+			"description": r.Description,
+		})
+		printf(ctx,
 			"(RID: %d)\n\tType: %s\n\tDescription: %s\n",
 			r.RID,
 			r.Type.String(),
 			strings.ReplaceAll(r.Description, "\n", " "),
 		)
 	}
+	result(ctx, listed)
 	return true, nil
 }
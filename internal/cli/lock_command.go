@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kerelape/gophkeeper/internal/agent"
+	"github.com/kerelape/gophkeeper/internal/stack"
+)
+
+// errNoAgent is returned when a command needs the agent but it is not running.
+var errNoAgent = errors.New("agent is not running, start it with agent and set " + agent.SocketEnv)
+
+type lockCommand struct{}
+
+var _ command = (*lockCommand)(nil)
+
+// Description implements command.
+func (*lockCommand) Description() string {
+	return "Make the agent forget the session and the vault password."
+}
+
+// Help implements command.
+func (*lockCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (*lockCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	var s = settingsOf(ctx)
+	if s.agent == nil {
+		return true, errNoAgent
+	}
+	if err := s.agent.Lock(ctx); err != nil {
+		return true, err
+	}
+	printf(ctx, "The agent is locked.\n")
+	result(ctx, map[string]any{"locked": true})
+	return true, nil
+}
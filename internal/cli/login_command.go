@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type loginCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*loginCommand)(nil)
+
+// Description implements command.
+func (l *loginCommand) Description() string {
+	return "Log in and save the session, so that other commands do not ask for the credential until it expires."
+}
+
+// Help implements command.
+func (l *loginCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (l *loginCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	var s = settingsOf(ctx)
+	if s.noSession {
+		return false, errors.New("login saves a session, it cannot be used with --no-session")
+	}
+
+	var username, token, loginError = login(ctx, l.gophkeeper, "")
+	if loginError != nil {
+		return true, loginError
+	}
+	var started, sessionError = newSession(s.server, username, token)
+	if sessionError != nil {
+		return true, sessionError
+	}
+	if err := started.save(); err != nil {
+		return true, err
+	}
+
+	printf(ctx, "Logged in as %s until %s.\n", started.Username, started.ExpiresAt.Local().Format(time.DateTime))
+	result(ctx, map[string]any{
+		"username":   started.Username,
+		"server":     started.Server,
+		"expires_at": started.ExpiresAt,
+	})
+	return true, nil
+}
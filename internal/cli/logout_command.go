@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+)
+
+type logoutCommand struct{}
+
+var _ command = (*logoutCommand)(nil)
+
+// Description implements command.
+func (*logoutCommand) Description() string {
+	return "Remove the saved session and lock the agent."
+}
+
+// Help implements command.
+func (*logoutCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (*logoutCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	var removed, removeError = removeSession()
+	if removeError != nil {
+		return true, removeError
+	}
+	var locked, lockError = lockAgent(ctx)
+	if lockError != nil {
+		return true, lockError
+	}
+	if removed || locked {
+		printf(ctx, "Logged out.\n")
+	} else {
+		printf(ctx, "Not logged in.\n")
+	}
+	result(ctx, map[string]any{"logged_out": removed || locked})
+	return true, nil
+}
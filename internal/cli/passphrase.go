@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// passphrase asks for an archive passphrase,
+// a new one is asked to be retyped.
+func passphrase(ctx context.Context, confirm bool) (string, error) {
+	var s = settingsOf(ctx)
+	var value, set, valueError = s.archivePassphrase()
+	if valueError != nil {
+		return "", valueError
+	}
+	if set {
+		return value, nil
+	}
+	if !s.interactive {
+		var typed, typedError = s.line("Passphrase: ")
+		if typedError != nil {
+			return "", typedError
+		}
+		if confirm {
+			var retyped, retypedError = s.line("Retype passphrase: ")
+			if retypedError != nil {
+				return "", retypedError
+			}
+			if retyped != typed {
+				return "", errors.New("passphrases do not match")
+			}
+		}
+		return typed, nil
+	}
+	var m, err = tea.NewProgram(
+		newPassphraseModel(confirm),
+		tea.WithAltScreen(),
+		tea.WithContext(ctx),
+	).Run()
+	if err != nil {
+		return "", err
+	}
+	if m.(passphraseModel).cancelled {
+		return "", fmt.Errorf("passphrase typing %w", errCancelled)
+	}
+	return m.(passphraseModel).passphrase.Value(), nil
+}
+
+type passphraseModel struct {
+	width, height int
+	cancelled     bool
+
+	confirm    bool
+	passphrase textinput.Model
+	retype     textinput.Model
+}
+
+func newPassphraseModel(confirm bool) passphraseModel {
+	var m = passphraseModel{
+		confirm:    confirm,
+		passphrase: textinput.New(),
+		retype:     textinput.New(),
+	}
+	m.passphrase.EchoMode = textinput.EchoPassword
+	m.passphrase.Prompt = "Passphrase: "
+	m.passphrase.CharLimit = 128
+	m.passphrase.Placeholder = "enter the archive passphrase..."
+	m.passphrase.Focus()
+
+	m.retype.EchoMode = textinput.EchoPassword
+	m.retype.Prompt = "Retype:     "
+	m.retype.CharLimit = 128
+	m.retype.Placeholder = "retype the archive passphrase..."
+	return m
+}
+
+var _ tea.Model = (*passphraseModel)(nil)
+
+// Init implements tea.Model.
+func (p passphraseModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model.
+func (p passphraseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		passphraseCmd tea.Cmd
+		retypeCmd     tea.Cmd
+	)
+	p.passphrase, passphraseCmd = p.passphrase.Update(msg)
+	p.retype, retypeCmd = p.retype.Update(msg)
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.height = msg.Height
+		p.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			switch {
+			case p.passphrase.Focused():
+				if p.passphrase.Value() == "" {
+					return p, textinput.Blink
+				}
+				p.passphrase.Blur()
+				if !p.confirm {
+					return p, tea.Quit
+				}
+				p.retype.Focus()
+			case p.retype.Focused():
+				if p.retype.Value() != p.passphrase.Value() {
+					return p, textinput.Blink
+				}
+				p.retype.Blur()
+				return p, tea.Quit
+			}
+		case "ctrl+c", "esc":
+			p.cancelled = true
+			return p, tea.Quit
+		}
+	}
+	return p, tea.Batch(passphraseCmd, retypeCmd)
+}
+
+// View implements tea.Model.
+func (p passphraseModel) View() string {
+	var content = []string{p.passphrase.View()}
+	if p.confirm {
+		content = append(content, p.retype.View())
+		if p.retype.Value() != "" && p.retype.Value() != p.passphrase.Value() {
+			content = append(content, strings.Repeat(" ", 64), violationStyle.Render("✗ passphrases do not match"))
+		}
+	}
+	return form(
+		p.width, p.height,
+		"Archive",
+		lipgloss.NewStyle().Width(64).Render(lipgloss.JoinVertical(lipgloss.Left, content...)),
+	)
+}
@@ -1,18 +1,11 @@
 package cli
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"errors"
-	"fmt"
-	"os"
-	"strings"
-	"syscall"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
-	"golang.org/x/term"
 )
 
 type registerCommand struct {
@@ -23,49 +16,34 @@ var _ command = (*registerCommand)(nil)
 
 // Execute implements command.
 func (r *registerCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
-	if len(args) > 0 {
-		return false, errors.New("expected 0 arguments")
+	if len(args) > 1 {
+		return false, errors.New("expected at most 1 argument")
 	}
-	var input = bufio.NewReader(os.Stdin)
-
-	fmt.Print("Type new identity's username: ")
-	var username, usernameError = input.ReadString('\n')
-	if usernameError != nil {
-		return true, usernameError
-	}
-	username = strings.TrimSuffix(username, "\n")
-
-	fmt.Print("Type new identity's password: ")
-	var password1, password1Error = term.ReadPassword((int)(syscall.Stdin))
-	if password1Error != nil {
-		return true, password1Error
+	var invite string
+	if len(args) == 1 {
+		invite = args.Pop()
 	}
-
-	fmt.Println()
-	fmt.Print("Retype new identity's password: ")
-	var password2, password2Error = term.ReadPassword((int)(syscall.Stdin))
-	if password2Error != nil {
-		return true, password1Error
-	}
-	fmt.Println()
-
-	if !bytes.Equal(password1, password2) {
-		return true, errors.New("passwords do not match")
+	var policy, policyError = r.gophkeeper.PasswordPolicy(ctx)
+	if policyError != nil && !errors.Is(policyError, gophkeeper.ErrIncompatibleAPI) {
+		return true, policyError
 	}
 
-	var credential = gophkeeper.Credential{
-		Username: username,
-		Password: (string)(password1),
+	var credential, credentialError = registration(ctx, policy)
+	if credentialError != nil {
+		return true, credentialError
 	}
+	credential.Invite = invite
 	if err := r.gophkeeper.Register(ctx, credential); err != nil {
 		return true, err
 	}
+	printf(ctx, "Registered %s.\n", credential.Username)
+	result(ctx, map[string]any{"username": credential.Username})
 	return true, nil
 }
 
 // Help implements command.
 func (r *registerCommand) Help() string {
-	return ""
+	return "[INVITE: string]"
 }
 
 // Description implements command.
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+var violationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#CC3333"))
+
+// registration asks for a new identity's credential,
+// checking the password against the policy as it is typed.
+func registration(ctx context.Context, policy gophkeeper.PasswordPolicy) (gophkeeper.Credential, error) {
+	var s = settingsOf(ctx)
+	if !s.interactive || (s.username != "" && s.password != "") {
+		var credential = gophkeeper.Credential{}
+		var usernameError, passwordError error
+		if credential.Username, usernameError = s.value(s.username, "Username: "); usernameError != nil {
+			return gophkeeper.Credential{}, usernameError
+		}
+		if credential.Password, passwordError = s.value(s.password, "Password: "); passwordError != nil {
+			return gophkeeper.Credential{}, passwordError
+		}
+		return credential, policy.Check(credential.Username, credential.Password)
+	}
+	var m, err = tea.NewProgram(
+		newRegistrationModel(policy),
+		tea.WithAltScreen(),
+		tea.WithContext(ctx),
+	).Run()
+	if err != nil {
+		return gophkeeper.Credential{}, err
+	}
+	if m.(registrationModel).cancelled {
+		return gophkeeper.Credential{}, fmt.Errorf("registration %w", errCancelled)
+	}
+	var credential = gophkeeper.Credential{
+		Username: m.(registrationModel).username.Value(),
+		Password: m.(registrationModel).password.Value(),
+	}
+	return credential, nil
+}
+
+type registrationModel struct {
+	cancelled bool
+
+	width, height int
+
+	policy gophkeeper.PasswordPolicy
+
+	username textinput.Model
+	password textinput.Model
+	retype   textinput.Model
+}
+
+func newRegistrationModel(policy gophkeeper.PasswordPolicy) registrationModel {
+	var m = registrationModel{
+		policy:   policy,
+		username: textinput.New(),
+		password: textinput.New(),
+		retype:   textinput.New(),
+	}
+	m.username.CharLimit = 32
+	m.username.Prompt = "Username: "
+	m.username.Placeholder = "type new identity's username..."
+
+	m.password.CharLimit = gophkeeper.MaxPasswordBytes
+	m.password.Prompt = "Password: "
+	m.password.EchoMode = textinput.EchoPassword
+	m.password.Placeholder = "type new identity's password..."
+
+	m.retype.CharLimit = gophkeeper.MaxPasswordBytes
+	m.retype.Prompt = "Retype:   "
+	m.retype.EchoMode = textinput.EchoPassword
+	m.retype.Placeholder = "retype the password..."
+
+	m.username.Focus()
+	return m
+}
+
+// violations returns violations of the policy by the typed password.
+func (m registrationModel) violations() []gophkeeper.PasswordViolation {
+	var policyError *gophkeeper.PasswordPolicyError
+	if errors.As(m.policy.Check(m.username.Value(), m.password.Value()), &policyError) {
+		return policyError.Violations
+	}
+	return nil
+}
+
+var _ tea.Model = (*registrationModel)(nil)
+
+// Init implements tea.Model.
+func (m registrationModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model.
+func (m registrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		usernameCmd tea.Cmd
+		passwordCmd tea.Cmd
+		retypeCmd   tea.Cmd
+	)
+	m.username, usernameCmd = m.username.Update(msg)
+	m.password, passwordCmd = m.password.Update(msg)
+	m.retype, retypeCmd = m.retype.Update(msg)
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+		m.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			switch {
+			case m.username.Focused():
+				if m.username.Value() == "" {
+					return m, textinput.Blink
+				}
+				m.username.Blur()
+				m.password.Focus()
+			case m.password.Focused():
+				if m.password.Value() == "" || len(m.violations()) > 0 {
+					return m, textinput.Blink
+				}
+				m.password.Blur()
+				m.retype.Focus()
+			case m.retype.Focused():
+				if m.retype.Value() != m.password.Value() {
+					return m, textinput.Blink
+				}
+				m.retype.Blur()
+				return m, tea.Quit
+			}
+		case "shift+tab":
+			switch {
+			case m.password.Focused():
+				m.password.Blur()
+				m.username.Focus()
+			case m.retype.Focused():
+				m.retype.Blur()
+				m.password.Focus()
+			}
+		case "tab":
+			if m.password.EchoMode == textinput.EchoPassword {
+				m.password.EchoMode = textinput.EchoNormal
+				m.retype.EchoMode = textinput.EchoNormal
+			} else {
+				m.password.EchoMode = textinput.EchoPassword
+				m.retype.EchoMode = textinput.EchoPassword
+			}
+		case "ctrl+c", "esc":
+			m.cancelled = true
+			return m, tea.Quit
+		}
+	}
+	return m, tea.Batch(usernameCmd, passwordCmd, retypeCmd)
+}
+
+// View implements tea.Model.
+func (m registrationModel) View() string {
+	var feedback []string
+	if m.password.Value() != "" {
+		for _, violation := range m.violations() {
+			feedback = append(feedback, violationStyle.Render("✗ password "+violation.Message))
+		}
+	}
+	if m.retype.Value() != "" && m.retype.Value() != m.password.Value() {
+		feedback = append(feedback, violationStyle.Render("✗ passwords do not match"))
+	}
+	var help = help.New()
+	help.Width = 64
+	return form(
+		m.width, m.height,
+		"Register to Gophkeeper",
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			m.username.View(),
+			strings.Repeat(" ", 64),
+			m.password.View(),
+			m.retype.View(),
+			strings.Repeat(" ", 64),
+			strings.Join(feedback, "\n"),
+			help.ShortHelpView(
+				[]key.Binding{
+					key.NewBinding(
+						key.WithHelp("[esc]", "cancel"),
+						key.WithKeys("esc"),
+					),
+					key.NewBinding(
+						key.WithHelp("[shift+tab]", "back"),
+						key.WithKeys("shift+tab"),
+					),
+					key.NewBinding(
+						key.WithHelp("[tab]", "view password"),
+						key.WithKeys("tab"),
+					),
+				},
+			),
+		),
+	)
+}
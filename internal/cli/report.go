@@ -0,0 +1,242 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// Kinds of findings of a report.
+const (
+	findingReused        = "reused"
+	findingWeak          = "weak"
+	findingOld           = "old"
+	findingUnknownAge    = "unknown_age"
+	findingDuplicate     = "duplicate"
+	findingExpiring      = "expiring"
+	findingExpired       = "expired"
+	findingInvalidExpiry = "invalid_expiry"
+	findingUndecryptable = "undecryptable"
+)
+
+const (
+	// cardExpiryLayout is the layout of cards' expiry dates, MM/YY.
+	cardExpiryLayout = "01/06"
+	day              = 24 * time.Hour
+	// ridLimit is how many RIDs of a finding are printed.
+	ridLimit = 8
+)
+
+// reportOptions are thresholds of a report.
+type reportOptions struct {
+	// minEntropy is bits of gophkeeper.PasswordEntropy below which a password is weak.
+	minEntropy float64
+	// maxAge is how long a password may stay unchanged.
+	maxAge time.Duration
+	// expiryWithin is how long before its expiry a card is reported.
+	expiryWithin time.Duration
+}
+
+// auditEntry is a decrypted resource, at most one of its resources is set.
+type auditEntry struct {
+	resource   resource
+	credential *credentialResource
+	card       *cardResource
+	text       *textResource
+	// failed tells the resource could not be decrypted.
+	failed bool
+}
+
+// finding is a problem found in the vault, it must not contain secrets.
+type finding struct {
+	Kind    string                  `json:"kind"`
+	RIDs    []gophkeeper.ResourceID `json:"rids"`
+	Message string                  `json:"message"`
+}
+
+// report is findings of an audit of the vault.
+type report struct {
+	Credentials int       `json:"credentials"`
+	Cards       int       `json:"cards"`
+	Texts       int       `json:"texts"`
+	Findings    []finding `json:"findings"`
+}
+
+// counts returns the number of findings of every kind.
+func (r report) counts() map[string]int {
+	var counts = make(map[string]int)
+	for _, f := range r.Findings {
+		counts[f.Kind]++
+	}
+	return counts
+}
+
+// audit checks the entries. Passwords, card numbers and texts are only
+// compared in memory, findings carry RIDs and descriptions of problems.
+func audit(entries []auditEntry, now time.Time, options reportOptions) report {
+	var (
+		r          = report{Findings: make([]finding, 0)}
+		passwords  = make(map[string][]gophkeeper.ResourceID)
+		duplicates = make(map[string][]gophkeeper.ResourceID)
+		unknownAge []gophkeeper.ResourceID
+	)
+	for _, e := range entries {
+		var rid = e.resource.RID
+		switch {
+		case e.failed:
+			r.Findings = append(r.Findings, finding{
+				Kind:    findingUndecryptable,
+				RIDs:    []gophkeeper.ResourceID{rid},
+				Message: fmt.Sprintf("the %s could not be decrypted", strings.ToLower(e.resource.Type.String())),
+			})
+		case e.credential != nil:
+			r.Credentials++
+			var password = e.credential.password
+			passwords[password] = append(passwords[password], rid)
+			var account = strings.ToLower(strings.TrimSpace(e.resource.Description)) + "\x00" + e.credential.username
+			duplicates["credential\x00"+account] = append(duplicates["credential\x00"+account], rid)
+
+			if entropy := gophkeeper.PasswordEntropy(password); entropy < options.minEntropy {
+				r.Findings = append(r.Findings, finding{
+					Kind:    findingWeak,
+					RIDs:    []gophkeeper.ResourceID{rid},
+					Message: fmt.Sprintf("the password has about %.0f bits of entropy, less than %.0f", math.Floor(entropy), options.minEntropy),
+				})
+			}
+			switch age := now.Sub(e.resource.StoredAt); {
+			case e.resource.StoredAt.IsZero():
+				unknownAge = append(unknownAge, rid)
+			case options.maxAge > 0 && age > options.maxAge:
+				r.Findings = append(r.Findings, finding{
+					Kind:    findingOld,
+					RIDs:    []gophkeeper.ResourceID{rid},
+					Message: fmt.Sprintf("the password is unchanged for %d days", (int)(age/day)),
+				})
+			}
+		case e.card != nil:
+			r.Cards++
+			var number = strings.Map(
+				func(r rune) rune {
+					if r < '0' || r > '9' {
+						return -1
+					}
+					return r
+				},
+				e.card.ccn,
+			)
+			duplicates["card\x00"+number] = append(duplicates["card\x00"+number], rid)
+			if f, ok := cardExpiry(rid, e.card.exp, now, options.expiryWithin); ok {
+				r.Findings = append(r.Findings, f)
+			}
+		case e.text != nil:
+			r.Texts++
+			duplicates["text\x00"+e.text.content] = append(duplicates["text\x00"+e.text.content], rid)
+		}
+	}
+
+	for _, rids := range passwords {
+		if len(rids) > 1 {
+			r.Findings = append(r.Findings, finding{
+				Kind:    findingReused,
+				RIDs:    rids,
+				Message: fmt.Sprintf("the same password is used by %d credentials", len(rids)),
+			})
+		}
+	}
+	for key, rids := range duplicates {
+		if len(rids) > 1 {
+			var kind, _, _ = strings.Cut(key, "\x00")
+			r.Findings = append(r.Findings, finding{
+				Kind:    findingDuplicate,
+				RIDs:    rids,
+				Message: fmt.Sprintf("%d %ss are the same entry", len(rids), kind),
+			})
+		}
+	}
+	if len(unknownAge) > 0 {
+		r.Findings = append(r.Findings, finding{
+			Kind:    findingUnknownAge,
+			RIDs:    unknownAge,
+			Message: fmt.Sprintf("%d credentials were stored before their age was recorded", len(unknownAge)),
+		})
+	}
+
+	for _, f := range r.Findings {
+		sort.Slice(f.RIDs, func(i, j int) bool { return f.RIDs[i] < f.RIDs[j] })
+	}
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		if r.Findings[i].Kind != r.Findings[j].Kind {
+			return findingOrder(r.Findings[i].Kind) < findingOrder(r.Findings[j].Kind)
+		}
+		return r.Findings[i].RIDs[0] < r.Findings[j].RIDs[0]
+	})
+	return r
+}
+
+// cardExpiry returns a finding if the card is expired or expires within the duration.
+func cardExpiry(rid gophkeeper.ResourceID, exp string, now time.Time, within time.Duration) (finding, bool) {
+	var month, parseError = time.Parse(cardExpiryLayout, strings.TrimSpace(exp))
+	if parseError != nil {
+		return finding{
+			Kind:    findingInvalidExpiry,
+			RIDs:    []gophkeeper.ResourceID{rid},
+			Message: "the card's expiry date is not MM/YY",
+		}, true
+	}
+	// A card is valid through the last day of its month.
+	var expiresAt = month.AddDate(0, 1, 0)
+	switch left := expiresAt.Sub(now); {
+	case left <= 0:
+		return finding{
+			Kind:    findingExpired,
+			RIDs:    []gophkeeper.ResourceID{rid},
+			Message: "the card has expired",
+		}, true
+	case left <= within:
+		return finding{
+			Kind:    findingExpiring,
+			RIDs:    []gophkeeper.ResourceID{rid},
+			Message: fmt.Sprintf("the card expires in %d days", (int)(math.Ceil((float64)(left)/(float64)(day)))),
+		}, true
+	}
+	return finding{}, false
+}
+
+// findingKinds are kinds of findings from the most to the least severe.
+var findingKinds = []string{
+	findingUndecryptable,
+	findingReused,
+	findingWeak,
+	findingExpired,
+	findingOld,
+	findingExpiring,
+	findingDuplicate,
+	findingInvalidExpiry,
+	findingUnknownAge,
+}
+
+// findingOrder orders findings by severity.
+func findingOrder(kind string) int {
+	for n, k := range findingKinds {
+		if k == kind {
+			return n
+		}
+	}
+	return math.MaxInt
+}
+
+// ridList formats RIDs, eliding long lists.
+func ridList(rids []gophkeeper.ResourceID) string {
+	var shown = make([]string, 0, min(len(rids), ridLimit))
+	for _, rid := range rids {
+		if len(shown) == ridLimit {
+			return strings.Join(shown, ", ") + fmt.Sprintf(" and %d more", len(rids)-len(shown))
+		}
+		shown = append(shown, fmt.Sprintf("%d", rid))
+	}
+	return strings.Join(shown, ", ")
+}
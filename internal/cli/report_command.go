@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type reportCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+
+	minEntropy float64
+	maxAgeDays int
+	expiryDays int
+}
+
+var (
+	_ command = (*reportCommand)(nil)
+	_ flagged = (*reportCommand)(nil)
+)
+
+// Flags implements flagged.
+func (r *reportCommand) Flags(set *flag.FlagSet) {
+	set.Float64Var(&r.minEntropy, "min-entropy", 50, "Report passwords with fewer bits of estimated entropy")
+	set.IntVar(&r.maxAgeDays, "max-age", 365, "Report passwords unchanged for more days, 0 to not report them")
+	set.IntVar(&r.expiryDays, "expiry-within", 60, "Report cards expiring within the days")
+}
+
+// Description implements command.
+func (r *reportCommand) Description() string {
+	return "Report reused, weak and old passwords, duplicate entries and cards near expiry, without secrets."
+}
+
+// Help implements command.
+func (r *reportCommand) Help() string {
+	return "[--min-entropy: float] [--max-age: days] [--expiry-within: days]"
+}
+
+// Execute implements command.
+func (r *reportCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	if r.maxAgeDays < 0 || r.expiryDays < 0 {
+		return false, errors.New("days must not be negative")
+	}
+
+	var gophkeeperIdentity, gophkeeperIdentityError = authenticate(ctx, r.gophkeeper)
+	if gophkeeperIdentityError != nil {
+		return true, gophkeeperIdentityError
+	}
+	var vaultPassword, vaultPasswordError = vaultPassword(ctx)
+	if vaultPasswordError != nil {
+		return true, vaultPasswordError
+	}
+
+	var identity = identity{
+		origin: gophkeeperIdentity,
+	}
+	var entries, entriesError = decryptAll(ctx, identity, vaultPassword)
+	if entriesError != nil {
+		return true, entriesError
+	}
+	var report = audit(
+		entries,
+		time.Now(),
+		reportOptions{
+			minEntropy:   r.minEntropy,
+			maxAge:       (time.Duration)(r.maxAgeDays) * day,
+			expiryWithin: (time.Duration)(r.expiryDays) * day,
+		},
+	)
+	result(ctx, report)
+
+	var s = settingsOf(ctx)
+	if s.interactive && !s.json {
+		var _, err = tea.NewProgram(
+			newReportModel(report),
+			tea.WithAltScreen(),
+			tea.WithContext(ctx),
+		).Run()
+		return true, err
+	}
+	printf(ctx, "Checked %d credentials, %d cards and %d texts.\n", report.Credentials, report.Cards, report.Texts)
+	for _, f := range report.Findings {
+		printf(ctx, "%s\t(RID: %s) %s\n", f.Kind, ridList(f.RIDs), f.Message)
+	}
+	return true, nil
+}
+
+// decryptAll decrypts credentials, cards and texts, a resource that fails
+// authentication on decryption is marked as failed instead of stopping the report.
+func decryptAll(ctx context.Context, i identity, vaultPassword string) ([]auditEntry, error) {
+	var resources, resourcesError = i.List(ctx)
+	if resourcesError != nil {
+		return nil, resourcesError
+	}
+	var entries = make([]auditEntry, 0, len(resources))
+	for _, r := range resources {
+		var (
+			entry = auditEntry{resource: r}
+			err   error
+		)
+		switch r.Type {
+		case resourceTypeCredential:
+			var credential credentialResource
+			credential, err = i.RestoreCredential(ctx, r.RID, vaultPassword)
+			entry.credential = &credential
+		case resourceTypeCard:
+			var card cardResource
+			card, err = i.RestoreCard(ctx, r.RID, vaultPassword)
+			entry.card = &card
+		case resourceTypeText:
+			var text textResource
+			text, err = i.RestoreText(ctx, r.RID, vaultPassword)
+			entry.text = &text
+		default:
+			continue
+		}
+		if err != nil {
+			// Any other error, like a wrong vault password or a server error,
+			// is not a problem of the resource and fails the report.
+			if !errors.Is(err, gophkeeper.ErrResourceCorrupted) {
+				return nil, err
+			}
+			entry = auditEntry{resource: r, failed: true}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+type reportModel struct {
+	width, height int
+
+	report   report
+	findings viewport.Model
+}
+
+func newReportModel(r report) reportModel {
+	var m = reportModel{
+		report:   r,
+		findings: viewport.New(64, 12),
+	}
+	var rows = make([]string, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		rows = append(
+			rows,
+			lipgloss.NewStyle().Width(64).Render(
+				fmt.Sprintf("%s (RID: %s) %s", findingStyle(f.Kind).Render(f.Kind), ridList(f.RIDs), f.Message),
+			),
+		)
+	}
+	if len(rows) == 0 {
+		rows = append(rows, "Nothing to report.")
+	}
+	m.findings.SetContent(strings.Join(rows, "\n"))
+	return m
+}
+
+// findingStyle highlights findings that need to be fixed.
+func findingStyle(kind string) lipgloss.Style {
+	switch kind {
+	case findingUndecryptable, findingReused, findingWeak, findingExpired:
+		return violationStyle
+	default:
+		return labelStyle
+	}
+}
+
+var _ tea.Model = (*reportModel)(nil)
+
+// Init implements tea.Model.
+func (m reportModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m reportModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q", "enter":
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.findings, cmd = m.findings.Update(msg)
+	return m, cmd
+}
+
+// View implements tea.Model.
+func (m reportModel) View() string {
+	var counts = m.report.counts()
+	var summary = make([]string, 0, len(counts))
+	for _, kind := range findingKinds {
+		if counts[kind] > 0 {
+			summary = append(summary, fmt.Sprintf("%s: %d", findingStyle(kind).Render(kind), counts[kind]))
+		}
+	}
+	var help = help.New()
+	help.Width = 64
+	return form(
+		m.width, m.height,
+		"Vault report",
+		lipgloss.JoinVertical(
+			lipgloss.Left,
+			fmt.Sprintf(
+				"Checked %d credentials, %d cards and %d texts.",
+				m.report.Credentials, m.report.Cards, m.report.Texts,
+			),
+			lipgloss.NewStyle().Width(64).Render(strings.Join(summary, "  ")),
+			strings.Repeat(" ", 64),
+			m.findings.View(),
+			strings.Repeat(" ", 64),
+			help.ShortHelpView(
+				[]key.Binding{
+					key.NewBinding(
+						key.WithKeys("up", "down"),
+						key.WithHelp("[↑/↓]", "scroll"),
+					),
+					key.NewBinding(
+						key.WithKeys("q"),
+						key.WithHelp("[q]", "quit"),
+					),
+				},
+			),
+		),
+	)
+}
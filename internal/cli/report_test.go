@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	var now = time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	var credential = func(rid gophkeeper.ResourceID, description, username, password string, storedAt time.Time) auditEntry {
+		return auditEntry{
+			resource:   resource{RID: rid, Description: description, Type: resourceTypeCredential, StoredAt: storedAt},
+			credential: &credentialResource{description: description, username: username, password: password},
+		}
+	}
+	var card = func(rid gophkeeper.ResourceID, number, exp string) auditEntry {
+		return auditEntry{
+			resource: resource{RID: rid, Type: resourceTypeCard, StoredAt: now},
+			card:     &cardResource{cardInfo: cardInfo{ccn: number, exp: exp, cvv: "123"}},
+		}
+	}
+	var entries = []auditEntry{
+		credential(1, "GitHub", "alice", "correct-Horse-battery-staple-42", now),
+		credential(2, "GitLab", "alice", "correct-Horse-battery-staple-42", now),
+		credential(3, "Forum", "alice", "hunter2", now),
+		credential(4, "Bank", "alice", "Xk7#mQ9$vL2@pR5!wN8", now.AddDate(-2, 0, 0)),
+		credential(5, "bank ", "alice", "Zt4%hJ6^bG1&sD3*fW0", time.Time{}),
+		card(6, "4111 1111 1111 1111", "11/26"),
+		card(7, "4111111111111111", "12/30"),
+		card(8, "5500000000000004", "01/24"),
+		card(9, "5500000000000005", "13/99"),
+		{resource: resource{RID: 10, Type: resourceTypeText}, failed: true},
+	}
+	var r = audit(entries, now, reportOptions{minEntropy: 50, maxAge: 365 * day, expiryWithin: 60 * day})
+	assert.Equal(t, 5, r.Credentials)
+	assert.Equal(t, 4, r.Cards)
+
+	var found = make(map[string][][]gophkeeper.ResourceID)
+	for _, f := range r.Findings {
+		found[f.Kind] = append(found[f.Kind], f.RIDs)
+	}
+	assert.Equal(t, [][]gophkeeper.ResourceID{{10}}, found[findingUndecryptable])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{1, 2}}, found[findingReused])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{3}}, found[findingWeak])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{4}}, found[findingOld])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{5}}, found[findingUnknownAge])
+	assert.ElementsMatch(t, [][]gophkeeper.ResourceID{{4, 5}, {6, 7}}, found[findingDuplicate])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{6}}, found[findingExpiring])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{8}}, found[findingExpired])
+	assert.Equal(t, [][]gophkeeper.ResourceID{{9}}, found[findingInvalidExpiry])
+	assert.Equal(t, findingUndecryptable, r.Findings[0].Kind, "findings must be ordered by severity")
+
+	var encoded, encodeError = json.Marshal(r)
+	assert.Nil(t, encodeError)
+	for _, secret := range []string{"hunter2", "correct-Horse", "Xk7#", "4111", "5500", "123", "alice", "11/26"} {
+		assert.NotContains(t, (string)(encoded), secret)
+	}
+	assert.NotContains(t, newReportModel(r).View(), "hunter2")
+}
+
+func TestRIDList(t *testing.T) {
+	assert.Equal(t, "1, 2", ridList([]gophkeeper.ResourceID{1, 2}))
+	assert.Equal(
+		t,
+		"1, 2, 3, 4, 5, 6, 7, 8 and 2 more",
+		ridList([]gophkeeper.ResourceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}),
+	)
+}
+
+type fakeAuditIdentity struct {
+	gophkeeper.Identity
+
+	errors map[gophkeeper.ResourceID]error
+}
+
+// List implements gophkeeper.Identity.
+func (i *fakeAuditIdentity) List(context.Context) ([]gophkeeper.Resource, error) {
+	var resources = make([]gophkeeper.Resource, 0, len(i.errors))
+	for rid := range i.errors {
+		resources = append(resources, gophkeeper.Resource{ID: rid, Meta: `{"type": 1}`})
+	}
+	return resources, nil
+}
+
+// RestorePiece implements gophkeeper.Identity.
+func (i *fakeAuditIdentity) RestorePiece(_ context.Context, rid gophkeeper.ResourceID, _ string) (gophkeeper.Piece, error) {
+	return gophkeeper.Piece{}, i.errors[rid]
+}
+
+func TestDecryptAll(t *testing.T) {
+	t.Run("Corrupted resource is undecryptable", func(t *testing.T) {
+		var origin = &fakeAuditIdentity{errors: map[gophkeeper.ResourceID]error{1: gophkeeper.ErrResourceCorrupted}}
+		var entries, err = decryptAll(context.Background(), identity{origin: origin}, "hunter2")
+		assert.Nil(t, err)
+		assert.Len(t, entries, 1)
+		assert.True(t, entries[0].failed)
+	})
+	t.Run("Server error fails the report", func(t *testing.T) {
+		var origin = &fakeAuditIdentity{errors: map[gophkeeper.ResourceID]error{1: gophkeeper.ErrServerIsDown}}
+		var _, err = decryptAll(context.Background(), identity{origin: origin}, "hunter2")
+		assert.ErrorIs(t, err, gophkeeper.ErrServerIsDown)
+	})
+}
@@ -3,7 +3,7 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
+	"flag"
 	"strconv"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
@@ -12,9 +12,18 @@ import (
 
 type restoreCardCommand struct {
 	gophkeeper gophkeeper.Gophkeeper
+	copy       string
 }
 
-var _ command = (*restoreCardCommand)(nil)
+var (
+	_ command = (*restoreCardCommand)(nil)
+	_ flagged = (*restoreCardCommand)(nil)
+)
+
+// Flags implements flagged.
+func (r *restoreCardCommand) Flags(set *flag.FlagSet) {
+	set.StringVar(&r.copy, "copy", "", "Copy the field to the clipboard instead of printing the resource")
+}
 
 // Description implements command.
 func (r *restoreCardCommand) Description() string {
@@ -23,7 +32,7 @@ func (r *restoreCardCommand) Description() string {
 
 // Help implements command.
 func (r *restoreCardCommand) Help() string {
-	return "<RID: int>"
+	return "<RID: int> [--copy: field]"
 }
 
 // Execute implements command.
@@ -36,6 +45,11 @@ func (r *restoreCardCommand) Execute(ctx context.Context, args stack.Stack[strin
 	if ridError != nil {
 		return false, ridError
 	}
+	if r.copy != "" {
+		if _, err := lookupField(cardResource{}.fields(), r.copy); err != nil {
+			return false, err
+		}
+	}
 
 	var gophkeeperIdentity, gophkeeperIdentityError = authenticate(ctx, r.gophkeeper)
 	if gophkeeperIdentityError != nil {
@@ -54,12 +68,23 @@ func (r *restoreCardCommand) Execute(ctx context.Context, args stack.Stack[strin
 	if resourceError != nil {
 		return true, resourceError
 	}
+	if r.copy != "" {
+		return true, copyResult(ctx, rid, resource.fields(), r.copy)
+	}
 
-	fmt.Printf("(%d) Card\n", rid)
-	fmt.Printf("\nCard Number\n%s\n", resource.ccn)
-	fmt.Printf("\nExpiration Date    CVV\n")
-	fmt.Printf("%s              %s\n", resource.exp, resource.cvv)
-	fmt.Printf("\nCard Holder\n%s\n", resource.holder)
+	printf(ctx, "(%d) Card\n", rid)
+	printf(ctx, "\nCard Number\n%s\n", resource.ccn)
+	printf(ctx, "\nExpiration Date    CVV\n")
+	printf(ctx, "%s              %s\n", resource.exp, resource.cvv)
+	printf(ctx, "\nCard Holder\n%s\n", resource.holder)
+	result(ctx, map[string]any{
+		"rid":         rid,
+		"description": resource.description,
+		"number":      resource.ccn,
+		"expiry":      resource.exp,
+		"cvv":         resource.cvv,
+		"holder":      resource.holder,
+	})
 
 	return true, nil
 }
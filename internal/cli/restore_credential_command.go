@@ -3,7 +3,7 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
+	"flag"
 	"strconv"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
@@ -12,9 +12,18 @@ import (
 
 type restoreCredentialCommand struct {
 	gophkeeper gophkeeper.Gophkeeper
+	copy       string
 }
 
-var _ command = (*restoreCredentialCommand)(nil)
+var (
+	_ command = (*restoreCredentialCommand)(nil)
+	_ flagged = (*restoreCredentialCommand)(nil)
+)
+
+// Flags implements flagged.
+func (r *restoreCredentialCommand) Flags(set *flag.FlagSet) {
+	set.StringVar(&r.copy, "copy", "", "Copy the field to the clipboard instead of printing the resource")
+}
 
 // Description implements command.
 func (r *restoreCredentialCommand) Description() string {
@@ -23,7 +32,7 @@ func (r *restoreCredentialCommand) Description() string {
 
 // Help implements command.
 func (r *restoreCredentialCommand) Help() string {
-	return "<RID: int>"
+	return "<RID: int> [--copy: field]"
 }
 
 // Execute implements command.
@@ -36,6 +45,11 @@ func (r *restoreCredentialCommand) Execute(ctx context.Context, args stack.Stack
 	if ridError != nil {
 		return false, ridError
 	}
+	if r.copy != "" {
+		if _, err := lookupField(credentialResource{}.fields(), r.copy); err != nil {
+			return false, err
+		}
+	}
 
 	var gophkeeperIdentity, gophkeeperIdentityError = authenticate(ctx, r.gophkeeper)
 	if gophkeeperIdentityError != nil {
@@ -54,10 +68,19 @@ func (r *restoreCredentialCommand) Execute(ctx context.Context, args stack.Stack
 	if resourceError != nil {
 		return true, resourceError
 	}
+	if r.copy != "" {
+		return true, copyResult(ctx, rid, resource.fields(), r.copy)
+	}
 
-	fmt.Printf("(%d) Credential\n", rid)
-	fmt.Printf("\tUsername: %s\n", resource.username)
-	fmt.Printf("\tPassword: %s\n\n", resource.password)
+	printf(ctx, "(%d) Credential\n", rid)
+	printf(ctx, "\tUsername: %s\n", resource.username)
+	printf(ctx, "\tPassword: %s\n\n", resource.password)
+	result(ctx, map[string]any{
+		"rid":         rid,
+		"description": resource.description,
+		"username":    resource.username,
+		"password":    resource.password,
+	})
 
 	return true, nil
 }
@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strconv"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
@@ -56,7 +55,8 @@ func (r *restoreFileCommand) Execute(ctx context.Context, args stack.Stack[strin
 		return true, resourceError
 	}
 
-	fmt.Printf("Restored resource (RID: %d) to file: %s\n", rid, resource.path)
+	printf(ctx, "Restored resource (RID: %d) to file: %s\n", rid, resource.path)
+	result(ctx, map[string]any{"rid": rid, "path": resource.path})
 
 	return true, nil
 }
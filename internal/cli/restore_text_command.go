@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 	"strconv"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
@@ -55,7 +54,12 @@ func (r *restoreTextCommand) Execute(ctx context.Context, args stack.Stack[strin
 		return true, resourceError
 	}
 
-	fmt.Printf("\n%s\n", resource.content)
+	printf(ctx, "\n%s\n", resource.content)
+	result(ctx, map[string]any{
+		"rid":         rid,
+		"description": resource.description,
+		"text":        resource.content,
+	})
 
 	return true, nil
 }
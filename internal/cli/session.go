@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// sessionMargin is how long before its expiry a session is not used anymore,
+// so that the token does not expire in the middle of a command.
+const sessionMargin = 30 * time.Second
+
+// session is a login to a server saved between invocations.
+type session struct {
+	Server    string           `json:"server"`
+	Username  string           `json:"username"`
+	Token     gophkeeper.Token `json:"token"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
+
+// newSession returns a session with the token,
+// its expiry is read from the token's claims.
+func newSession(server, username string, token gophkeeper.Token) (session, error) {
+	var claims = make(jwt.MapClaims)
+	if _, _, err := jwt.NewParser().ParseUnverified((string)(token), claims); err != nil {
+		return session{}, err
+	}
+	var expiresAt, expiresAtError = claims.GetExpirationTime()
+	if expiresAtError != nil {
+		return session{}, expiresAtError
+	}
+	if expiresAt == nil {
+		return session{}, errors.New("token does not expire")
+	}
+	var s = session{
+		Server:    server,
+		Username:  username,
+		Token:     token,
+		ExpiresAt: expiresAt.Time,
+	}
+	return s, nil
+}
+
+// expired tells whether the session cannot be used anymore.
+func (s session) expired() bool {
+	return !time.Now().Add(sessionMargin).Before(s.ExpiresAt)
+}
+
+// configPath returns the path of the file in the user's config directory.
+func configPath(name string) (string, error) {
+	var dir, dirError = os.UserConfigDir()
+	if dirError != nil {
+		return "", dirError
+	}
+	return filepath.Join(dir, "gophkeeper", name), nil
+}
+
+// writeConfig replaces the file in the user's config directory,
+// it is only accessible by the user.
+func writeConfig(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	// CreateTemp creates the file with 0600 permissions.
+	var file, fileError = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if fileError != nil {
+		return fileError
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// sessionPath returns the path of the session file in the user's config directory.
+func sessionPath() (string, error) {
+	return configPath("session.json")
+}
+
+// loadSession returns the saved session and whether there is one.
+func loadSession() (session, bool, error) {
+	var path, pathError = sessionPath()
+	if pathError != nil {
+		return session{}, false, pathError
+	}
+	var content, readError = os.ReadFile(path)
+	if errors.Is(readError, fs.ErrNotExist) {
+		return session{}, false, nil
+	}
+	if readError != nil {
+		return session{}, false, readError
+	}
+	var s session
+	if err := json.Unmarshal(content, &s); err != nil {
+		return session{}, false, err
+	}
+	return s, true, nil
+}
+
+// save replaces the saved session, the file is only accessible by the user.
+func (s session) save() error {
+	var path, pathError = sessionPath()
+	if pathError != nil {
+		return pathError
+	}
+	var content, marshalError = json.Marshal(s)
+	if marshalError != nil {
+		return marshalError
+	}
+	return writeConfig(path, content)
+}
+
+// removeSession removes the saved session and tells whether there was one.
+func removeSession() (bool, error) {
+	var path, pathError = sessionPath()
+	if pathError != nil {
+		return false, pathError
+	}
+	var err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// sessionIdentity is an identity of a saved session,
+// it logs in again when the server rejects the session's token.
+type sessionIdentity struct {
+	gophkeeper gophkeeper.Gophkeeper
+	session    session
+	origin     gophkeeper.Identity
+}
+
+var _ gophkeeper.Identity = (*sessionIdentity)(nil)
+
+// relogin asks for the credential again and saves the new session.
+func (s *sessionIdentity) relogin(ctx context.Context) error {
+	if _, err := removeSession(); err != nil {
+		return err
+	}
+	var username, token, loginError = login(ctx, s.gophkeeper, s.session.Username)
+	if loginError != nil {
+		return loginError
+	}
+	var renewed, sessionError = newSession(s.session.Server, username, token)
+	if sessionError != nil {
+		return sessionError
+	}
+	if err := renewed.save(); err != nil {
+		return err
+	}
+	var identity, identityError = s.gophkeeper.Identity(ctx, token)
+	if identityError != nil {
+		return identityError
+	}
+	s.session, s.origin = renewed, identity
+	return nil
+}
+
+// rejected tells whether the session's token is rejected, since
+// ErrBadCredential is also returned for a wrong vault password.
+func (s *sessionIdentity) rejected(ctx context.Context) bool {
+	var _, err = s.origin.Profile(ctx)
+	return errors.Is(err, gophkeeper.ErrBadCredential)
+}
+
+// retry calls the identity and calls it again after
+// logging in if the session's token is rejected.
+func retry[T any](ctx context.Context, s *sessionIdentity, call func(gophkeeper.Identity) (T, error)) (T, error) {
+	var value, err = call(s.origin)
+	if !errors.Is(err, gophkeeper.ErrBadCredential) || !s.rejected(ctx) {
+		return value, err
+	}
+	if reloginError := s.relogin(ctx); reloginError != nil {
+		return value, reloginError
+	}
+	return call(s.origin)
+}
+
+// StorePiece implements gophkeeper.Identity.
+func (s *sessionIdentity) StorePiece(ctx context.Context, piece gophkeeper.Piece, password string) (gophkeeper.ResourceID, error) {
+	return retry(ctx, s, func(i gophkeeper.Identity) (gophkeeper.ResourceID, error) {
+		return i.StorePiece(ctx, piece, password)
+	})
+}
+
+// RestorePiece implements gophkeeper.Identity.
+func (s *sessionIdentity) RestorePiece(ctx context.Context, rid gophkeeper.ResourceID, password string) (gophkeeper.Piece, error) {
+	return retry(ctx, s, func(i gophkeeper.Identity) (gophkeeper.Piece, error) {
+		return i.RestorePiece(ctx, rid, password)
+	})
+}
+
+// StoreBlob implements gophkeeper.Identity, it is not retried
+// since the content is consumed, the session is removed instead.
+func (s *sessionIdentity) StoreBlob(ctx context.Context, blob gophkeeper.Blob, password string) (gophkeeper.ResourceID, error) {
+	var rid, err = s.origin.StoreBlob(ctx, blob, password)
+	if errors.Is(err, gophkeeper.ErrBadCredential) && s.rejected(ctx) {
+		if _, removeError := removeSession(); removeError != nil {
+			return rid, errors.Join(err, removeError)
+		}
+		return rid, fmt.Errorf("session is rejected, log in again: %w", err)
+	}
+	return rid, err
+}
+
+// RestoreBlob implements gophkeeper.Identity.
+func (s *sessionIdentity) RestoreBlob(ctx context.Context, rid gophkeeper.ResourceID, password string) (gophkeeper.Blob, error) {
+	return retry(ctx, s, func(i gophkeeper.Identity) (gophkeeper.Blob, error) {
+		return i.RestoreBlob(ctx, rid, password)
+	})
+}
+
+// Delete implements gophkeeper.Identity.
+func (s *sessionIdentity) Delete(ctx context.Context, rid gophkeeper.ResourceID) error {
+	var _, err = retry(ctx, s, func(i gophkeeper.Identity) (struct{}, error) {
+		return struct{}{}, i.Delete(ctx, rid)
+	})
+	return err
+}
+
+// List implements gophkeeper.Identity.
+func (s *sessionIdentity) List(ctx context.Context) ([]gophkeeper.Resource, error) {
+	return retry(ctx, s, func(i gophkeeper.Identity) ([]gophkeeper.Resource, error) {
+		return i.List(ctx)
+	})
+}
+
+// DeleteIdentity implements gophkeeper.Identity, the session is removed with the identity.
+func (s *sessionIdentity) DeleteIdentity(ctx context.Context, password string) error {
+	var _, err = retry(ctx, s, func(i gophkeeper.Identity) (struct{}, error) {
+		return struct{}{}, i.DeleteIdentity(ctx, password)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = removeSession()
+	return err
+}
+
+// Profile implements gophkeeper.Identity.
+func (s *sessionIdentity) Profile(ctx context.Context) (gophkeeper.Profile, error) {
+	return retry(ctx, s, func(i gophkeeper.Identity) (gophkeeper.Profile, error) {
+		return i.Profile(ctx)
+	})
+}
+
+// Invite implements gophkeeper.Identity.
+func (s *sessionIdentity) Invite(ctx context.Context) (gophkeeper.Invite, error) {
+	return retry(ctx, s, func(i gophkeeper.Identity) (gophkeeper.Invite, error) {
+		return i.Invite(ctx)
+	})
+}
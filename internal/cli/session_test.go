@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kerelape/gophkeeper/internal/agent"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func token(t *testing.T, lifespan time.Duration) gophkeeper.Token {
+	var signed, signError = jwt.NewWithClaims(
+		jwt.SigningMethodHS256,
+		jwt.MapClaims{
+			"exp": time.Now().Add(lifespan).Unix(),
+			"sub": "alice",
+		},
+	).SignedString(([]byte)("secret"))
+	assert.Nil(t, signError)
+	return (gophkeeper.Token)(signed)
+}
+
+type fakeSessionGophkeeper struct {
+	gophkeeper.Gophkeeper
+
+	token  gophkeeper.Token
+	logins int
+}
+
+// Authenticate implements gophkeeper.Gophkeeper.
+func (g *fakeSessionGophkeeper) Authenticate(_ context.Context, credential gophkeeper.Credential) (gophkeeper.Token, error) {
+	if credential.Username != "alice" || credential.Password != "hunter2" {
+		return "", gophkeeper.ErrBadCredential
+	}
+	g.logins++
+	return g.token, nil
+}
+
+// Identity implements gophkeeper.Gophkeeper.
+func (g *fakeSessionGophkeeper) Identity(_ context.Context, token gophkeeper.Token) (gophkeeper.Identity, error) {
+	return &fakeSessionIdentity{valid: token == g.token}, nil
+}
+
+type fakeSessionIdentity struct {
+	gophkeeper.Identity
+
+	valid bool
+}
+
+// List implements gophkeeper.Identity.
+func (i *fakeSessionIdentity) List(context.Context) ([]gophkeeper.Resource, error) {
+	if !i.valid {
+		return nil, gophkeeper.ErrBadCredential
+	}
+	return []gophkeeper.Resource{{ID: 1}}, nil
+}
+
+// Profile implements gophkeeper.Identity.
+func (i *fakeSessionIdentity) Profile(context.Context) (gophkeeper.Profile, error) {
+	if !i.valid {
+		return gophkeeper.Profile{}, gophkeeper.ErrBadCredential
+	}
+	return gophkeeper.Profile{Username: "alice"}, nil
+}
+
+func sessionContext(t *testing.T) context.Context {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	var s = newSettings()
+	s.server = "https://localhost:16355"
+	s.interactive = false
+	s.username, s.password = "", "hunter2"
+	return withSettings(context.Background(), s)
+}
+
+func TestSession(t *testing.T) {
+	t.Run("SaveAndLoad", func(t *testing.T) {
+		sessionContext(t)
+		var started, sessionError = newSession("https://localhost:16355", "alice", token(t, time.Hour))
+		assert.Nil(t, sessionError)
+		assert.False(t, started.expired())
+		assert.Nil(t, started.save())
+
+		var path, _ = sessionPath()
+		var info, statError = os.Stat(path)
+		assert.Nil(t, statError)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+		var loaded, found, loadError = loadSession()
+		assert.Nil(t, loadError)
+		assert.True(t, found)
+		assert.Equal(t, started.Token, loaded.Token)
+		assert.True(t, started.ExpiresAt.Equal(loaded.ExpiresAt))
+
+		var removed, removeError = removeSession()
+		assert.Nil(t, removeError)
+		assert.True(t, removed)
+		_, found, _ = loadSession()
+		assert.False(t, found)
+	})
+	t.Run("Expired", func(t *testing.T) {
+		var expired, sessionError = newSession("", "alice", token(t, 10*time.Second))
+		assert.Nil(t, sessionError)
+		assert.True(t, expired.expired())
+	})
+	t.Run("Reused", func(t *testing.T) {
+		var ctx = sessionContext(t)
+		var g = &fakeSessionGophkeeper{token: token(t, time.Hour)}
+		var started, _ = newSession("https://localhost:16355", "alice", g.token)
+		assert.Nil(t, started.save())
+
+		var identity, identityError = authenticate(ctx, g)
+		assert.Nil(t, identityError)
+		var resources, listError = identity.List(ctx)
+		assert.Nil(t, listError)
+		assert.Len(t, resources, 1)
+		assert.Equal(t, 0, g.logins)
+	})
+	t.Run("RejectedIsRenewed", func(t *testing.T) {
+		var ctx = sessionContext(t)
+		var g = &fakeSessionGophkeeper{token: token(t, time.Hour)}
+		var stale, _ = newSession("https://localhost:16355", "alice", token(t, 2*time.Hour))
+		assert.Nil(t, stale.save())
+
+		var identity, identityError = authenticate(ctx, g)
+		assert.Nil(t, identityError)
+		var resources, listError = identity.List(ctx)
+		assert.Nil(t, listError)
+		assert.Len(t, resources, 1)
+		assert.Equal(t, 1, g.logins)
+
+		var renewed, _, _ = loadSession()
+		assert.Equal(t, g.token, renewed.Token)
+	})
+	t.Run("Agent", func(t *testing.T) {
+		var ctx = sessionContext(t)
+		var listener, listenError = agent.Listen(filepath.Join(t.TempDir(), "agent", "agent.sock"))
+		if !assert.Nil(t, listenError) {
+			t.FailNow()
+		}
+		var serveCtx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		go (&agent.Agent{}).Serve(serveCtx, listener)
+		settingsOf(ctx).agent = &agent.Client{Socket: listener.Addr().String()}
+		settingsOf(ctx).username = "alice"
+
+		var g = &fakeSessionGophkeeper{token: token(t, time.Hour)}
+		var _, identityError = authenticate(ctx, g)
+		assert.Nil(t, identityError)
+		assert.Equal(t, 1, g.logins)
+
+		settingsOf(ctx).password = ""
+		var identity, heldError = authenticate(ctx, g)
+		assert.Nil(t, heldError)
+		assert.Equal(t, 1, g.logins, "the agent's session must be used")
+		var _, listError = identity.List(ctx)
+		assert.Nil(t, listError)
+		var password, passwordError = vaultPassword(ctx)
+		assert.Nil(t, passwordError)
+		assert.Equal(t, "hunter2", password)
+
+		settingsOf(ctx).username = "bob"
+		var _, held = agentSession(ctx)
+		assert.False(t, held, "another user's session must not be used")
+
+		settingsOf(ctx).username = "alice"
+		var _, logoutError = (&logoutCommand{}).Execute(ctx, nil)
+		assert.Nil(t, logoutError)
+		_, held = agentSession(ctx)
+		assert.False(t, held, "logout must lock the agent")
+	})
+	t.Run("NoSession", func(t *testing.T) {
+		var ctx = sessionContext(t)
+		settingsOf(ctx).noSession = true
+		settingsOf(ctx).username = "alice"
+		var g = &fakeSessionGophkeeper{token: token(t, time.Hour)}
+		var started, _ = newSession("https://localhost:16355", "alice", token(t, 2*time.Hour))
+		assert.Nil(t, started.save())
+
+		var _, identityError = authenticate(ctx, g)
+		assert.Nil(t, identityError)
+		assert.Equal(t, 1, g.logins)
+		var kept, _, _ = loadSession()
+		assert.Equal(t, started.Token, kept.Token)
+	})
+}
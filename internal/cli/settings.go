@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/agent"
+	"golang.org/x/term"
+)
+
+// Environment variables read by every command.
+const (
+	usernameEnv      = "GOPHKEEPER_USERNAME"
+	passwordEnv      = "GOPHKEEPER_PASSWORD"
+	vaultPasswordEnv = "GOPHKEEPER_VAULT_PASSWORD"
+	passphraseEnv    = "GOPHKEEPER_PASSPHRASE"
+	profileEnv       = "GOPHKEEPER_PROFILE"
+)
+
+// errCancelled is returned when the user cancels a form.
+var errCancelled = errors.New("cancelled by user")
+
+// settings is what a command is given with flags and environment
+// instead of forms, it is carried in the command's context.
+type settings struct {
+	username           string
+	password           string
+	vaultPassword      string
+	vaultPasswordFile  string
+	vaultPasswordStdin bool
+	passphrase         string
+	passphraseFile     string
+	description        optionalString
+	fields             fieldValues
+	yes                bool
+	json               bool
+	noSession          bool
+	clearAfter         time.Duration
+	profile            string
+
+	// clipboard is where fields are copied to.
+	clipboard clipboardBackend
+	// server is the address of the server, sessions are saved for it.
+	server string
+	// agent is the agent holding the session, nil if it is not running.
+	agent *agent.Client
+
+	// interactive tells whether forms can be shown,
+	// otherwise missing values are read as lines from stdin.
+	interactive bool
+	stdin       *bufio.Reader
+	stdout      io.Writer
+	stderr      io.Writer
+
+	result any
+}
+
+func newSettings() *settings {
+	var agentClient *agent.Client
+	if socket := os.Getenv(agent.SocketEnv); socket != "" {
+		agentClient = &agent.Client{Socket: socket}
+	}
+	var profile = defaultProfile
+	if env := os.Getenv(profileEnv); env != "" {
+		profile = env
+	}
+	return &settings{
+		agent:       agentClient,
+		username:    os.Getenv(usernameEnv),
+		password:    os.Getenv(passwordEnv),
+		passphrase:  os.Getenv(passphraseEnv),
+		fields:      make(fieldValues),
+		clearAfter:  defaultClearAfter,
+		profile:     profile,
+		clipboard:   systemClipboard{},
+		interactive: term.IsTerminal((int)(os.Stdin.Fd())),
+		stdin:       bufio.NewReader(os.Stdin),
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+	}
+}
+
+// flags registers flags of the settings.
+func (s *settings) flags(set *flag.FlagSet) {
+	set.StringVar(&s.username, "username", s.username, "Username to authenticate with (env "+usernameEnv+")")
+	set.StringVar(&s.password, "password", s.password, "Password to authenticate with (env "+passwordEnv+")")
+	set.StringVar(&s.vaultPasswordFile, "vault-password-file", "", "Read the vault password from the file (env "+vaultPasswordEnv+")")
+	set.BoolVar(&s.vaultPasswordStdin, "vault-password-stdin", false, "Read the vault password from the first line of stdin")
+	set.StringVar(&s.passphraseFile, "passphrase-file", "", "Read the archive passphrase from the file (env "+passphraseEnv+")")
+	set.Var(&s.description, "description", "Description of the resource")
+	set.Var(&s.fields, "field", "Field of the resource as name=value, repeatable: username, password, text, number, expiry, cvv, holder")
+	set.BoolVar(&s.yes, "yes", false, "Confirm without asking")
+	set.BoolVar(&s.json, "json", false, "Write the result and errors as JSON")
+	set.BoolVar(&s.noSession, "no-session", false, "Use neither the saved login session nor the agent")
+	set.StringVar(&s.profile, "profile", s.profile, "Profile of the password generator's policy (env "+profileEnv+")")
+	set.DurationVar(&s.clearAfter, "clear-after", s.clearAfter, "Clear a copied field from the clipboard after this long, 0 keeps it")
+}
+
+type settingsKey struct{}
+
+func withSettings(ctx context.Context, s *settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, s)
+}
+
+// settingsOf returns settings of the command,
+// default ones if there are none in the context.
+func settingsOf(ctx context.Context) *settings {
+	if s, ok := ctx.Value(settingsKey{}).(*settings); ok {
+		return s
+	}
+	return newSettings()
+}
+
+// line asks for a line on stderr and reads it from stdin.
+func (s *settings) line(prompt string) (string, error) {
+	fmt.Fprint(s.stderr, prompt)
+	var line, readError = s.stdin.ReadString('\n')
+	if readError != nil && (!errors.Is(readError, io.EOF) || line == "") {
+		if errors.Is(readError, io.EOF) {
+			return "", fmt.Errorf("read %s: %w", strings.TrimSuffix(prompt, ": "), io.ErrUnexpectedEOF)
+		}
+		return "", readError
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// value returns the value if it is set, otherwise asks for it with line.
+func (s *settings) value(value, prompt string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	return s.line(prompt)
+}
+
+// secret reads a secret from the file, without the trailing newline.
+func secret(path string) (string, error) {
+	var content, readError = os.ReadFile(path)
+	if readError != nil {
+		return "", readError
+	}
+	return strings.TrimRight((string)(content), "\r\n"), nil
+}
+
+// vault returns the vault password set with flags or environment.
+func (s *settings) vault() (string, bool, error) {
+	switch {
+	case s.vaultPassword != "":
+		return s.vaultPassword, true, nil
+	case s.vaultPasswordStdin:
+		var password, lineError = s.line("")
+		if lineError != nil {
+			return "", false, lineError
+		}
+		s.vaultPassword = password
+	case s.vaultPasswordFile != "":
+		var password, secretError = secret(s.vaultPasswordFile)
+		if secretError != nil {
+			return "", false, secretError
+		}
+		s.vaultPassword = password
+	case os.Getenv(vaultPasswordEnv) != "":
+		s.vaultPassword = os.Getenv(vaultPasswordEnv)
+	case s.password != "":
+		// The vault is encrypted with the identity's password.
+		s.vaultPassword = s.password
+	}
+	return s.vaultPassword, s.vaultPassword != "", nil
+}
+
+// archivePassphrase returns the passphrase set with flags or environment.
+func (s *settings) archivePassphrase() (string, bool, error) {
+	if s.passphrase == "" && s.passphraseFile != "" {
+		var passphrase, secretError = secret(s.passphraseFile)
+		if secretError != nil {
+			return "", false, secretError
+		}
+		s.passphrase = passphrase
+	}
+	return s.passphrase, s.passphrase != "", nil
+}
+
+// confirm asks to type one of the answers, unless confirmed with --yes.
+func (s *settings) confirm(question string, answers ...string) (bool, error) {
+	if s.yes {
+		return true, nil
+	}
+	var typed, lineError = s.line(question)
+	if lineError != nil {
+		return false, lineError
+	}
+	for _, answer := range answers {
+		if strings.EqualFold(strings.TrimSpace(typed), answer) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// printf writes human-readable output, it is omitted with --json.
+func printf(ctx context.Context, format string, args ...any) {
+	var s = settingsOf(ctx)
+	if !s.json {
+		fmt.Fprintf(s.stdout, format, args...)
+	}
+}
+
+// result sets the result of the command written with --json.
+func result(ctx context.Context, value any) {
+	settingsOf(ctx).result = value
+}
+
+// optionalString is a string flag that tells whether it is set.
+type optionalString struct {
+	value string
+	set   bool
+}
+
+var _ flag.Value = (*optionalString)(nil)
+
+// String implements flag.Value.
+func (o *optionalString) String() string {
+	return o.value
+}
+
+// Set implements flag.Value.
+func (o *optionalString) Set(value string) error {
+	o.value, o.set = value, true
+	return nil
+}
+
+// fieldValues is values of resource fields by name.
+type fieldValues map[string]string
+
+var _ flag.Value = (fieldValues)(nil)
+
+// String implements flag.Value.
+func (f fieldValues) String() string {
+	var names = make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements flag.Value.
+func (f fieldValues) Set(value string) error {
+	var name, fieldValue, ok = strings.Cut(value, "=")
+	if !ok || name == "" {
+		return errors.New("expected name=value")
+	}
+	f[name] = fieldValue
+	return nil
+}
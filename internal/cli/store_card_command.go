@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -65,8 +64,9 @@ func (s *storeCardCommand) Execute(ctx context.Context, args stack.Stack[string]
 		return true, ridError
 	}
 
-	fmt.Printf("Successfully stored card.\n")
-	fmt.Printf("RID of the newly stored resource is %d.\n", rid)
+	printf(ctx, "Successfully stored card.\n")
+	printf(ctx, "RID of the newly stored resource is %d.\n", rid)
+	result(ctx, map[string]any{"rid": rid})
 
 	return true, nil
 }
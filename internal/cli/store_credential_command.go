@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -53,8 +52,9 @@ func (s *storeCredentialCommand) Execute(ctx context.Context, args stack.Stack[s
 	if storeError != nil {
 		return true, storeError
 	}
-	fmt.Printf("Successfully stored credential.\n")
-	fmt.Printf("RID of the newly stored resource is %d.\n", rid)
+	printf(ctx, "Successfully stored credential.\n")
+	printf(ctx, "RID of the newly stored resource is %d.\n", rid)
+	result(ctx, map[string]any{"rid": rid})
 	return true, nil
 }
 
@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -60,8 +59,9 @@ func (s *storeFileCommand) Execute(ctx context.Context, args stack.Stack[string]
 		return true, ridError
 	}
 
-	fmt.Printf("Successfully stored file.\n")
-	fmt.Printf("RID of the newly stored resource is %d.\n", rid)
+	printf(ctx, "Successfully stored file.\n")
+	printf(ctx, "RID of the newly stored resource is %d.\n", rid)
+	result(ctx, map[string]any{"rid": rid})
 
 	return true, nil
 }
@@ -3,7 +3,6 @@ package cli
 import (
 	"context"
 	"errors"
-	"fmt"
 
 	"github.com/kerelape/gophkeeper/internal/stack"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -66,8 +65,9 @@ func (s *storeTextCommand) Execute(ctx context.Context, args stack.Stack[string]
 		return true, ridError
 	}
 
-	fmt.Printf("\nSuccessfully saved text note.\n")
-	fmt.Printf("RID of the newly stored resource is %d.\n", rid)
+	printf(ctx, "\nSuccessfully saved text note.\n")
+	printf(ctx, "RID of the newly stored resource is %d.\n", rid)
+	result(ctx, map[string]any{"rid": rid})
 
 	return true, nil
 }
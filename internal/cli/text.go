@@ -2,8 +2,8 @@ package cli
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"io"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
@@ -37,6 +37,15 @@ func newTextModel() textModel {
 }
 
 func text(ctx context.Context) (string, error) {
+	var s = settingsOf(ctx)
+	if content, ok := s.fields["text"]; ok {
+		return content, nil
+	}
+	if !s.interactive {
+		// The rest of stdin is the note.
+		var content, readError = io.ReadAll(s.stdin)
+		return (string)(content), readError
+	}
 	var m, err = tea.NewProgram(
 		newTextModel(),
 		tea.WithAltScreen(),
@@ -46,7 +55,7 @@ func text(ctx context.Context) (string, error) {
 		return "", err
 	}
 	if m.(textModel).cancelled {
-		return "", errors.New("user cancelled typing")
+		return "", fmt.Errorf("typing %w", errCancelled)
 	}
 	return m.(textModel).content.Value(), nil
 }
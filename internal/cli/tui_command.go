@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type tuiCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+	lockAfter  time.Duration
+}
+
+var (
+	_ command = (*tuiCommand)(nil)
+	_ flagged = (*tuiCommand)(nil)
+)
+
+// Flags implements flagged.
+func (t *tuiCommand) Flags(set *flag.FlagSet) {
+	set.DurationVar(&t.lockAfter, "lock-after", 5*time.Minute, "Lock the browser when there is no input for this long")
+}
+
+// Description implements command.
+func (t *tuiCommand) Description() string {
+	return "Browse, decrypt, create, edit and delete resources in a full-screen browser."
+}
+
+// Help implements command.
+func (t *tuiCommand) Help() string {
+	return "[--lock-after: duration]"
+}
+
+// Execute implements command.
+func (t *tuiCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	if t.lockAfter <= 0 {
+		return false, errors.New("lock-after must be positive")
+	}
+	if !settingsOf(ctx).interactive {
+		return true, errors.New("tui needs a terminal")
+	}
+	var identity, identityError = authenticate(ctx, t.gophkeeper)
+	if identityError != nil {
+		return true, identityError
+	}
+	var password, passwordError = vaultPassword(ctx)
+	if passwordError != nil {
+		return true, passwordError
+	}
+	// The username is needed to unlock the browser, which logs in again.
+	var username string
+	if profile, err := identity.Profile(ctx); err == nil {
+		username = profile.Username
+	}
+	var _, err = tea.NewProgram(
+		newBrowserModel(ctx, t.gophkeeper, identity, username, password, t.lockAfter),
+		tea.WithAltScreen(),
+		tea.WithContext(ctx),
+	).Run()
+	return true, err
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type unlockCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*unlockCommand)(nil)
+
+// Description implements command.
+func (u *unlockCommand) Description() string {
+	return "Log in and make the agent hold the session and the vault password."
+}
+
+// Help implements command.
+func (u *unlockCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (u *unlockCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+	var s = settingsOf(ctx)
+	if s.agent == nil {
+		return true, errNoAgent
+	}
+	if s.noSession {
+		return false, errors.New("unlock cannot be used with --no-session")
+	}
+	var credential, credentialError = authenticationCredential(ctx, "")
+	if credentialError != nil {
+		return true, credentialError
+	}
+	var token, tokenError = u.gophkeeper.Authenticate(ctx, credential)
+	if tokenError != nil {
+		return true, tokenError
+	}
+	if err := unlockAgent(ctx, credential, token); err != nil {
+		return true, err
+	}
+	var held, heldError = s.agent.Status(ctx)
+	if heldError != nil {
+		return true, heldError
+	}
+	printf(ctx, "The agent is unlocked for %s until %s.\n", held.Username, held.ExpiresAt.Local().Format(time.DateTime))
+	result(ctx, map[string]any{
+		"username":   held.Username,
+		"server":     held.Server,
+		"expires_at": held.ExpiresAt,
+	})
+	return true, nil
+}
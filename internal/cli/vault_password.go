@@ -2,14 +2,29 @@ package cli
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
 
 func vaultPassword(ctx context.Context) (string, error) {
+	var s = settingsOf(ctx)
+	var password, set, passwordError = s.vault()
+	if passwordError != nil {
+		return "", passwordError
+	}
+	if set {
+		return password, nil
+	}
+	if held, ok := agentSession(ctx); ok {
+		return held.VaultPassword, nil
+	}
+	if !s.interactive {
+		return s.line("Vault password: ")
+	}
 	var m, err = tea.NewProgram(
 		newVaultPasswordModel(),
 		tea.WithAltScreen(),
@@ -19,7 +34,7 @@ func vaultPassword(ctx context.Context) (string, error) {
 		return "", err
 	}
 	if m.(vaultPasswordModel).cancelled {
-		return "", errors.New("vault password typing cancelled by user")
+		return "", fmt.Errorf("vault password typing %w", errCancelled)
 	}
 	return m.(vaultPasswordModel).password.Value(), nil
 }
@@ -37,7 +52,7 @@ func newVaultPasswordModel() vaultPasswordModel {
 	}
 	m.password.EchoMode = textinput.EchoPassword
 	m.password.Prompt = "Vault password: "
-	m.password.CharLimit = 32
+	m.password.CharLimit = gophkeeper.MaxPasswordBytes
 	m.password.Placeholder = "enter your vault password..."
 	m.password.Focus()
 	return m
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kerelape/gophkeeper/internal/stack"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+type whoamiCommand struct {
+	gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ command = (*whoamiCommand)(nil)
+
+// Description implements command.
+func (w *whoamiCommand) Description() string {
+	return "Show the identity's profile and usage."
+}
+
+// Help implements command.
+func (w *whoamiCommand) Help() string {
+	return ""
+}
+
+// Execute implements command.
+func (w *whoamiCommand) Execute(ctx context.Context, args stack.Stack[string]) (bool, error) {
+	if len(args) > 0 {
+		return false, errors.New("expected 0 arguments")
+	}
+
+	var gophkeeperIdentity, identityError = authenticate(ctx, w.gophkeeper)
+	if identityError != nil {
+		return true, identityError
+	}
+	var profile, profileError = gophkeeperIdentity.Profile(ctx)
+	if profileError != nil {
+		return true, profileError
+	}
+	var identity = identity{
+		origin: gophkeeperIdentity,
+	}
+	var resources, resourcesError = identity.List(ctx)
+	if resourcesError != nil {
+		return true, resourcesError
+	}
+	var kinds = make(map[resourceType]int)
+	for _, r := range resources {
+		kinds[r.Type]++
+	}
+	var kindCounts = make(map[string]int, len(kinds))
+	for kind, count := range kinds {
+		kindCounts[kind.String()] = count
+	}
+	result(ctx, map[string]any{
+		"username":    profile.Username,
+		"created_at":  profile.CreatedAt,
+		"last_login":  profile.LastLogin,
+		"pieces":      profile.Resources[gophkeeper.ResourceTypePiece],
+		"blobs":       profile.Resources[gophkeeper.ResourceTypeBlob],
+		"piece_bytes": profile.PieceBytes,
+		"blob_bytes":  profile.BlobBytes,
+		"kinds":       kindCounts,
+		"security": map[string]any{
+			"admin":          profile.Security.Admin,
+			"invites":        profile.Security.Invites,
+			"key_iterations": profile.Security.KeyIterations,
+		},
+	})
+
+	var lastLogin = "never"
+	if !profile.LastLogin.IsZero() {
+		lastLogin = profile.LastLogin.Local().Format(time.DateTime)
+	}
+	printf(ctx, "Username: %s\n", profile.Username)
+	printf(ctx, "Created: %s\n", profile.CreatedAt.Local().Format(time.DateTime))
+	printf(ctx, "Last login: %s\n", lastLogin)
+	printf(ctx,
+		"Resources: %d pieces (%s), %d blobs (%s)\n",
+		profile.Resources[gophkeeper.ResourceTypePiece], byteSize(profile.PieceBytes),
+		profile.Resources[gophkeeper.ResourceTypeBlob], byteSize(profile.BlobBytes),
+	)
+	for _, kind := range []resourceType{resourceTypeCredential, resourceTypeText, resourceTypeCard, resourceTypeFile} {
+		printf(ctx, "\t%s: %d\n", kind.String(), kinds[kind])
+	}
+	printf(ctx, "Security:\n")
+	printf(ctx, "\tAdmin: %t\n", profile.Security.Admin)
+	printf(ctx, "\tInvites: %t\n", profile.Security.Invites)
+	printf(ctx, "\tKey derivation: PBKDF2-SHA256, %d iterations\n", profile.Security.KeyIterations)
+	return true, nil
+}
+
+// byteSize formats the size in bytes with a binary unit.
+func byteSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	var div, exp = (int64)(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", (float64)(size)/(float64)(div), "KMGTPE"[exp])
+}
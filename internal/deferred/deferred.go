@@ -2,6 +2,7 @@ package deferred
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 )
 
@@ -9,28 +10,45 @@ import (
 type Deferred[T any] struct {
 	value    T
 	hasValue atomic.Bool
+	ready    chan struct{}
+	once     sync.Once
 }
 
 // Get returns the value stored in the Deferred or wait until it is set.
 func (d *Deferred[T]) Get(ctx context.Context) (value T, err error) {
-	for {
-		select {
-		case <-ctx.Done():
-			err = ctx.Err()
-			return
-		default:
-			if d.hasValue.Load() {
-				return d.value, nil
-			}
-		}
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+		return
+	case <-d.readyChannel():
+		return d.value, nil
+	}
+}
+
+// Peek returns the value stored in the Deferred without waiting,
+// ok is false if it's not set yet.
+func (d *Deferred[T]) Peek() (value T, ok bool) {
+	select {
+	case <-d.readyChannel():
+		return d.value, true
+	default:
+		return
 	}
 }
 
 // Set sets the value to the Deferred.
 func (d *Deferred[T]) Set(value T) {
-	if d.hasValue.Load() {
+	var ready = d.readyChannel()
+	if !d.hasValue.CompareAndSwap(false, true) {
 		panic("value already set")
 	}
 	d.value = value
-	d.hasValue.Swap(true)
+	close(ready)
+}
+
+func (d *Deferred[T]) readyChannel() chan struct{} {
+	d.once.Do(func() {
+		d.ready = make(chan struct{})
+	})
+	return d.ready
 }
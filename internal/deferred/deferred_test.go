@@ -0,0 +1,50 @@
+package deferred
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferred(t *testing.T) {
+	t.Run("Get returns the value that was set before", func(t *testing.T) {
+		var d Deferred[string]
+		d.Set("Hello, World!")
+		var value, err = d.Get(context.Background())
+		assert.NoError(t, err, "expected Get to succeed")
+		assert.Equal(t, "Hello, World!", value, "got value doesn't match the set one")
+	})
+	t.Run("Get waits until the value is set", func(t *testing.T) {
+		var d Deferred[int]
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			d.Set(42)
+		}()
+		var value, err = d.Get(context.Background())
+		assert.NoError(t, err, "expected Get to succeed")
+		assert.Equal(t, 42, value, "got value doesn't match the set one")
+	})
+	t.Run("Get returns context error when cancelled", func(t *testing.T) {
+		var d Deferred[int]
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		var _, err = d.Get(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "expected Get to fail with the context error")
+	})
+	t.Run("Peek doesn't wait for the value", func(t *testing.T) {
+		var d Deferred[int]
+		var _, ok = d.Peek()
+		assert.False(t, ok, "expected Peek to report missing value")
+		d.Set(42)
+		var value, set = d.Peek()
+		assert.True(t, set, "expected Peek to report set value")
+		assert.Equal(t, 42, value, "peeked value doesn't match the set one")
+	})
+	t.Run("Set panics when called twice", func(t *testing.T) {
+		var d Deferred[int]
+		d.Set(1)
+		assert.Panics(t, func() { d.Set(2) }, "expected the second Set to panic")
+	})
+}
@@ -0,0 +1,223 @@
+// Package generator generates passwords and diceware passphrases with crypto/rand.
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// Character classes of a password.
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+	// Ambiguous is characters easily confused with each other when read or typed.
+	Ambiguous = "0OoIl1|`'\";:.,"
+)
+
+// Limits of a policy.
+const (
+	MaxLength = 1024
+	MaxWords  = 64
+)
+
+var (
+	// ErrNoClasses is returned when a policy allows no characters.
+	ErrNoClasses = errors.New("no character classes are allowed")
+
+	// ErrLength is returned when a policy's length is out of range.
+	ErrLength = errors.New("length is out of range")
+)
+
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist []byte
+
+// Wordlist returns the words of passphrases, the EFF's large wordlist:
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+var Wordlist = sync.OnceValue(func() []string {
+	var words = make([]string, 0, 7776)
+	var scanner = bufio.NewScanner(bytes.NewReader(effLargeWordlist))
+	for scanner.Scan() {
+		// A line is the dice roll and the word separated by a tab.
+		var _, word, _ = strings.Cut(scanner.Text(), "\t")
+		words = append(words, word)
+	}
+	return words
+})
+
+// Policy is what a generated password is made of.
+type Policy struct {
+	Length           int  `json:"length"`
+	Lower            bool `json:"lower"`
+	Upper            bool `json:"upper"`
+	Digits           bool `json:"digits"`
+	Symbols          bool `json:"symbols"`
+	ExcludeAmbiguous bool `json:"exclude_ambiguous"`
+
+	// Words is the number of words of a passphrase,
+	// a password of characters is generated if it is 0.
+	Words     int    `json:"words"`
+	Separator string `json:"separator"`
+}
+
+// DefaultPolicy is the policy used unless another one is configured.
+var DefaultPolicy = Policy{
+	Length:    20,
+	Lower:     true,
+	Upper:     true,
+	Digits:    true,
+	Symbols:   true,
+	Separator: "-",
+}
+
+// Generate returns a password or, if p.Words is set, a passphrase.
+func (p Policy) Generate() (string, error) {
+	if p.Words > 0 {
+		return p.passphrase()
+	}
+	return p.password()
+}
+
+// Entropy returns bits of entropy of a value generated with the policy.
+func (p Policy) Entropy() float64 {
+	if p.Words > 0 {
+		return (float64)(p.Words) * math.Log2((float64)(len(Wordlist())))
+	}
+	var charset = strings.Join(p.classes(), "")
+	if charset == "" {
+		return 0
+	}
+	return (float64)(p.Length) * math.Log2((float64)(len(charset)))
+}
+
+// Validate returns an error if nothing can be generated with the policy.
+func (p Policy) Validate() error {
+	if p.Words > 0 {
+		if p.Words > MaxWords {
+			return fmt.Errorf("%w: %d words, at most %d", ErrLength, p.Words, MaxWords)
+		}
+		return nil
+	}
+	var classes = p.classes()
+	if len(classes) == 0 {
+		return ErrNoClasses
+	}
+	if p.Length < len(classes) || p.Length > MaxLength {
+		return fmt.Errorf("%w: %d characters, from %d to %d", ErrLength, p.Length, len(classes), MaxLength)
+	}
+	return nil
+}
+
+// classes returns characters of the allowed classes.
+func (p Policy) classes() []string {
+	var classes = make([]string, 0, 4)
+	for _, class := range []struct {
+		allowed    bool
+		characters string
+	}{
+		{p.Lower, Lower},
+		{p.Upper, Upper},
+		{p.Digits, Digits},
+		{p.Symbols, Symbols},
+	} {
+		if !class.allowed {
+			continue
+		}
+		var characters = class.characters
+		if p.ExcludeAmbiguous {
+			characters = strings.Map(
+				func(r rune) rune {
+					if strings.ContainsRune(Ambiguous, r) {
+						return -1
+					}
+					return r
+				},
+				characters,
+			)
+		}
+		classes = append(classes, characters)
+	}
+	return classes
+}
+
+// password returns a password with at least one character of every allowed class.
+func (p Policy) password() (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	var (
+		classes  = p.classes()
+		charset  = strings.Join(classes, "")
+		password = make([]byte, 0, p.Length)
+	)
+	for _, class := range classes {
+		var c, err = pick(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < p.Length {
+		var c, err = pick(charset)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	// The characters of the classes must not always come first.
+	for i := len(password) - 1; i > 0; i-- {
+		var j, err = random(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return (string)(password), nil
+}
+
+// passphrase returns words of the wordlist joined with the separator.
+func (p Policy) passphrase() (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+	var (
+		wordlist = Wordlist()
+		words    = make([]string, 0, p.Words)
+	)
+	for len(words) < p.Words {
+		var n, err = random(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		words = append(words, wordlist[n])
+	}
+	return strings.Join(words, p.Separator), nil
+}
+
+// pick returns a uniformly random character of the set.
+func pick(set string) (byte, error) {
+	var n, err = random(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[n], nil
+}
+
+// random returns a uniformly random number in [0, n).
+func random(n int) (int, error) {
+	var r, err = rand.Int(rand.Reader, big.NewInt((int64)(n)))
+	if err != nil {
+		return 0, err
+	}
+	return (int)(r.Int64()), nil
+}
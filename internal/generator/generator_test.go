@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordlist(t *testing.T) {
+	var words = Wordlist()
+	assert.Len(t, words, 7776)
+	assert.Equal(t, "abacus", words[0])
+	assert.Equal(t, "zoom", words[len(words)-1])
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("Classes", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			var password, err = DefaultPolicy.Generate()
+			assert.Nil(t, err)
+			assert.Len(t, password, DefaultPolicy.Length)
+			for _, class := range []string{Lower, Upper, Digits, Symbols} {
+				assert.True(t, strings.ContainsAny(password, class), "%q must contain one of %q", password, class)
+			}
+		}
+	})
+	t.Run("ExcludeAmbiguous", func(t *testing.T) {
+		var policy = DefaultPolicy
+		policy.Length = 256
+		policy.ExcludeAmbiguous = true
+		var password, err = policy.Generate()
+		assert.Nil(t, err)
+		assert.False(t, strings.ContainsAny(password, Ambiguous))
+	})
+	t.Run("OnlyDigits", func(t *testing.T) {
+		var password, err = Policy{Length: 6, Digits: true}.Generate()
+		assert.Nil(t, err)
+		assert.Equal(t, "", strings.Trim(password, Digits))
+	})
+	t.Run("Passphrase", func(t *testing.T) {
+		var passphrase, err = Policy{Words: 6, Separator: " "}.Generate()
+		assert.Nil(t, err)
+		var words = strings.Split(passphrase, " ")
+		assert.Len(t, words, 6)
+		for _, word := range words {
+			assert.Contains(t, Wordlist(), word)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		var _, noClassesError = Policy{Length: 20}.Generate()
+		assert.ErrorIs(t, noClassesError, ErrNoClasses)
+		var _, shortError = Policy{Length: 3, Lower: true, Upper: true, Digits: true, Symbols: true}.Generate()
+		assert.ErrorIs(t, shortError, ErrLength)
+		var _, longError = Policy{Words: MaxWords + 1}.Generate()
+		assert.ErrorIs(t, longError, ErrLength)
+	})
+	t.Run("Entropy", func(t *testing.T) {
+		assert.InDelta(t, 77.5, Policy{Words: 6}.Entropy(), 0.1)
+		assert.InDelta(t, 131.1, DefaultPolicy.Entropy(), 0.1)
+	})
+}
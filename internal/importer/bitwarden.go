@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type bitwardenExport struct {
+	Encrypted bool            `json:"encrypted"`
+	Items     []bitwardenItem `json:"items"`
+}
+
+type bitwardenItem struct {
+	Type   int    `json:"type"`
+	Name   string `json:"name"`
+	Notes  string `json:"notes"`
+	Fields []struct {
+		Name string `json:"name"`
+	} `json:"fields"`
+	Login *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		TOTP     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+		Fido2Credentials []json.RawMessage `json:"fido2Credentials"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity    map[string]any    `json:"identity"`
+	Attachments []json.RawMessage `json:"attachments"`
+}
+
+const (
+	bitwardenLogin      = 1
+	bitwardenSecureNote = 2
+	bitwardenCard       = 3
+	bitwardenIdentity   = 4
+)
+
+// bitwardenIdentityFields is fields of identities in the order they are written.
+var bitwardenIdentityFields = []string{
+	"title", "firstName", "middleName", "lastName", "username", "company",
+	"email", "phone", "address1", "address2", "address3", "city", "state",
+	"postalCode", "country", "ssn", "passportNumber", "licenseNumber",
+}
+
+func parseBitwarden(in io.Reader) (Result, error) {
+	var export bitwardenExport
+	if err := json.NewDecoder(in).Decode(&export); err != nil {
+		return Result{}, fmt.Errorf("parse Bitwarden JSON: %w", err)
+	}
+	if export.Encrypted {
+		return Result{}, errors.New("encrypted Bitwarden exports are not supported, export unencrypted JSON")
+	}
+
+	var result Result
+	for _, item := range export.Items {
+		var entry = entry{title: item.Name, notes: item.Notes}
+		for _, field := range item.Fields {
+			entry.skipped = append(entry.skipped, field.Name)
+		}
+		if len(item.Attachments) > 0 {
+			entry.skipped = append(entry.skipped, "attachments")
+		}
+
+		switch {
+		case item.Type == bitwardenLogin && item.Login != nil:
+			entry.username = item.Login.Username
+			entry.password = item.Login.Password
+			for n, uri := range item.Login.URIs {
+				if n == 0 {
+					entry.url = uri.URI
+				} else {
+					entry.skipped = append(entry.skipped, "uri")
+				}
+			}
+			if item.Login.TOTP != "" {
+				entry.skipped = append(entry.skipped, "totp")
+			}
+			if len(item.Login.Fido2Credentials) > 0 {
+				entry.skipped = append(entry.skipped, "passkey")
+			}
+		case item.Type == bitwardenSecureNote:
+		case item.Type == bitwardenCard && item.Card != nil:
+			entry.card = &Card{
+				Number: item.Card.Number,
+				Expiry: expiry(item.Card.ExpMonth, item.Card.ExpYear),
+				CVV:    item.Card.Code,
+				Holder: item.Card.CardholderName,
+			}
+		case item.Type == bitwardenIdentity && item.Identity != nil:
+			var lines []string
+			for _, field := range bitwardenIdentityFields {
+				if value, ok := item.Identity[field].(string); ok && value != "" {
+					lines = append(lines, fmt.Sprintf("%s: %s", field, value))
+				}
+			}
+			if entry.notes != "" {
+				lines = append(lines, "", entry.notes)
+			}
+			entry.notes = strings.Join(lines, "\n")
+		default:
+			result.Skipped = append(result.Skipped, Skipped{Entry: item.Name, Reason: fmt.Sprintf("unknown item type %d", item.Type)})
+			continue
+		}
+
+		var records = entry.records()
+		if len(records) == 0 {
+			result.Skipped = append(result.Skipped, Skipped{Entry: item.Name, Reason: "item is empty"})
+		}
+		result.Records = append(result.Records, records...)
+	}
+	return result, nil
+}
+
+// expiry formats the month and the year of a card's expiry as MM/YY.
+func expiry(month, year string) string {
+	if month == "" && year == "" {
+		return ""
+	}
+	if len(month) == 1 {
+		month = "0" + month
+	}
+	if len(year) == 4 {
+		year = year[2:]
+	}
+	return month + "/" + year
+}
@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvColumns is names of columns in 1Password and browser
+// exports, lowercase, for each field of an entry.
+var csvColumns = map[string]func(*entry, string){
+	"title":          func(e *entry, v string) { e.title = v },
+	"name":           func(e *entry, v string) { e.title = v },
+	"url":            func(e *entry, v string) { e.url = v },
+	"website":        func(e *entry, v string) { e.url = v },
+	"login_uri":      func(e *entry, v string) { e.url = v },
+	"username":       func(e *entry, v string) { e.username = v },
+	"login_username": func(e *entry, v string) { e.username = v },
+	"password":       func(e *entry, v string) { e.password = v },
+	"login_password": func(e *entry, v string) { e.password = v },
+	"notes":          func(e *entry, v string) { e.notes = v },
+	"note":           func(e *entry, v string) { e.notes = v },
+}
+
+// parseCSV reads a CSV export with a header row, columns
+// that are not in csvColumns are reported as skipped.
+func parseCSV(in io.Reader) (Result, error) {
+	var reader = csv.NewReader(in)
+	reader.FieldsPerRecord = -1
+	var header, headerError = reader.Read()
+	if errors.Is(headerError, io.EOF) {
+		return Result{}, nil
+	}
+	if headerError != nil {
+		return Result{}, fmt.Errorf("parse CSV: %w", headerError)
+	}
+	for n, column := range header {
+		header[n] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+	}
+
+	var result Result
+	for row := 2; ; row++ {
+		var values, readError = reader.Read()
+		if errors.Is(readError, io.EOF) {
+			break
+		}
+		if readError != nil {
+			return Result{}, fmt.Errorf("parse CSV: %w", readError)
+		}
+		var entry entry
+		for n, value := range values {
+			if n >= len(header) || value == "" {
+				continue
+			}
+			if set, ok := csvColumns[header[n]]; ok {
+				set(&entry, value)
+			} else {
+				entry.skipped = append(entry.skipped, header[n])
+			}
+		}
+		var records = entry.records()
+		if len(records) == 0 {
+			result.Skipped = append(result.Skipped, Skipped{Entry: fmt.Sprintf("row %d", row), Reason: "row is empty"})
+		}
+		result.Records = append(result.Records, records...)
+	}
+	return result, nil
+}
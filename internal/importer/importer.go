@@ -0,0 +1,184 @@
+// Package importer reads exports of other password managers.
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Kind is a kind of resource a record is stored as.
+type Kind int
+
+const (
+	// KindCredential is a username and a password.
+	KindCredential Kind = iota
+	// KindText is a text.
+	KindText
+	// KindCard is a payment card.
+	KindCard
+	// KindFile is an attached file.
+	KindFile
+)
+
+// Record is an entry mapped onto a resource.
+type Record struct {
+	Kind        Kind
+	Description string
+
+	Username string // Username is set for KindCredential.
+	Password string // Password is set for KindCredential.
+	Text     string // Text is set for KindText.
+	Card     Card   // Card is set for KindCard.
+	File     File   // File is set for KindFile.
+
+	// Skipped is names of the entry's fields that could not be mapped.
+	Skipped []string
+}
+
+// Card is a payment card.
+type Card struct {
+	Number string
+	Expiry string // Expiry is MM/YY.
+	CVV    string
+	Holder string
+}
+
+// File is an attachment.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Skipped is an entry that could not be mapped at all.
+type Skipped struct {
+	Entry  string
+	Reason string
+}
+
+// Result is records read from an export.
+type Result struct {
+	Records []Record
+	Skipped []Skipped
+}
+
+// Format is a format of exports.
+type Format string
+
+const (
+	// FormatKeePass is KeePass 2.x XML.
+	FormatKeePass Format = "keepass"
+	// FormatBitwarden is unencrypted Bitwarden JSON.
+	FormatBitwarden Format = "bitwarden"
+	// Format1Password is 1Password CSV.
+	Format1Password Format = "1password"
+	// FormatBrowser is CSV of Chrome, Edge, Firefox or Safari.
+	FormatBrowser Format = "browser"
+)
+
+// ErrUnknownFormat is returned when the format of an export is not supported.
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Detect guesses the format of the export by its name and beginning.
+func Detect(name string, head []byte) (Format, error) {
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, ([]byte)("\xef\xbb\xbf")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(head, ([]byte)("<")):
+		return FormatKeePass, nil
+	case bytes.HasPrefix(head, ([]byte)("{")):
+		return FormatBitwarden, nil
+	case strings.EqualFold(filepath.Ext(name), ".csv"):
+		var header, _, _ = bytes.Cut(head, ([]byte)("\n"))
+		header = bytes.ToLower(header)
+		if bytes.Contains(header, ([]byte)("favorite")) || bytes.Contains(header, ([]byte)("archived")) {
+			return Format1Password, nil
+		}
+		return FormatBrowser, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// Parse reads an export of the format.
+func Parse(format Format, in io.Reader) (Result, error) {
+	switch format {
+	case FormatKeePass:
+		return parseKeePass(in)
+	case FormatBitwarden:
+		return parseBitwarden(in)
+	case Format1Password, FormatBrowser:
+		return parseCSV(in)
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// entry is an entry of any format before it is mapped.
+type entry struct {
+	title    string
+	url      string
+	username string
+	password string
+	notes    string
+	card     *Card
+	files    []File
+	skipped  []string
+}
+
+// records maps the entry onto a credential or a card, if it has one,
+// its notes onto a text and its attachments onto files.
+func (e entry) records() []Record {
+	var (
+		records     []Record
+		name        = e.title
+		description = e.title
+	)
+	if name == "" {
+		name = e.url
+	}
+	if description == "" {
+		description = e.url
+	} else if e.url != "" {
+		description += "\n" + e.url
+	}
+	if e.username != "" || e.password != "" {
+		records = append(records, Record{
+			Kind:        KindCredential,
+			Description: description,
+			Username:    e.username,
+			Password:    e.password,
+		})
+	}
+	if e.card != nil {
+		records = append(records, Record{
+			Kind:        KindCard,
+			Description: description,
+			Card:        *e.card,
+		})
+	}
+	if e.notes != "" {
+		var notes = description
+		if len(records) > 0 {
+			notes = fmt.Sprintf("%s (notes)", name)
+		}
+		records = append(records, Record{
+			Kind:        KindText,
+			Description: notes,
+			Text:        e.notes,
+		})
+	}
+	for _, file := range e.files {
+		records = append(records, Record{
+			Kind:        KindFile,
+			Description: fmt.Sprintf("%s: %s", name, file.Name),
+			File:        file,
+		})
+	}
+	if len(records) > 0 {
+		records[0].Skipped = e.skipped
+	}
+	return records
+}
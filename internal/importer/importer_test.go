@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeePass(t *testing.T) {
+	var compressed bytes.Buffer
+	var writer = gzip.NewWriter(&compressed)
+	writer.Write(([]byte)("recovery codes"))
+	writer.Close()
+	var export = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<Binaries>
+			<Binary ID="0" Compressed="True">` + base64.StdEncoding.EncodeToString(compressed.Bytes()) + `</Binary>
+		</Binaries>
+	</Meta>
+	<Root>
+		<Group>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>GitHub</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value ProtectInMemory="True">hunter2</Value></String>
+				<String><Key>URL</Key><Value>https://github.com</Value></String>
+				<String><Key>Notes</Key><Value>2FA enabled</Value></String>
+				<String><Key>otp</Key><Value>otpauth://totp/github</Value></String>
+				<Binary><Key>codes.txt</Key><Value Ref="0"/></Binary>
+				<History>
+					<Entry>
+						<String><Key>Password</Key><Value>old</Value></String>
+					</Entry>
+				</History>
+			</Entry>
+			<Group>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Deleted</Value></String>
+					<String><Key>Password</Key><Value>deleted</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<Name>Notes</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>Wi-Fi</Value></String>
+					<String><Key>Notes</Key><Value>password is on the router</Value></String>
+				</Entry>
+				<Entry>
+					<String><Key>Title</Key><Value>Empty</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+	var format, formatError = Detect("export.xml", ([]byte)(export))
+	assert.Nil(t, formatError)
+	assert.Equal(t, FormatKeePass, format)
+
+	var result, parseError = Parse(format, strings.NewReader(export))
+	assert.Nil(t, parseError)
+	assert.Equal(t, []Record{
+		{
+			Kind:        KindCredential,
+			Description: "GitHub\nhttps://github.com",
+			Username:    "alice",
+			Password:    "hunter2",
+			Skipped:     []string{"otp"},
+		},
+		{Kind: KindText, Description: "GitHub (notes)", Text: "2FA enabled"},
+		{
+			Kind:        KindFile,
+			Description: "GitHub: codes.txt",
+			File:        File{Name: "codes.txt", Content: ([]byte)("recovery codes")},
+		},
+		{Kind: KindText, Description: "Wi-Fi", Text: "password is on the router"},
+	}, result.Records)
+	assert.Equal(t, []Skipped{{Entry: "Empty", Reason: "entry is empty"}}, result.Skipped)
+}
+
+func TestBitwarden(t *testing.T) {
+	var export = `{
+		"encrypted": false,
+		"folders": [],
+		"items": [
+			{
+				"type": 1,
+				"name": "Mail",
+				"notes": null,
+				"fields": [{"name": "PIN", "value": "1234", "type": 1}],
+				"login": {
+					"username": "alice@example.com",
+					"password": "hunter2",
+					"totp": "JBSWY3DPEHPK3PXP",
+					"uris": [{"match": null, "uri": "https://mail.example.com"}, {"uri": "https://example.com"}]
+				}
+			},
+			{
+				"type": 3,
+				"name": "Visa",
+				"notes": "backup card",
+				"card": {"cardholderName": "ALICE", "brand": "Visa", "number": "4111111111111111", "expMonth": "7", "expYear": "2031", "code": "123"}
+			},
+			{"type": 2, "name": "Recipe", "notes": "flour", "secureNote": {"type": 0}},
+			{"type": 4, "name": "Passport", "identity": {"firstName": "Alice", "lastName": "Liddell", "passportNumber": "X123"}},
+			{"type": 5, "name": "Key"}
+		]
+	}`
+	var format, formatError = Detect("export.json", ([]byte)(export))
+	assert.Nil(t, formatError)
+	assert.Equal(t, FormatBitwarden, format)
+
+	var result, parseError = Parse(format, strings.NewReader(export))
+	assert.Nil(t, parseError)
+	assert.Equal(t, []Record{
+		{
+			Kind:        KindCredential,
+			Description: "Mail\nhttps://mail.example.com",
+			Username:    "alice@example.com",
+			Password:    "hunter2",
+			Skipped:     []string{"PIN", "uri", "totp"},
+		},
+		{
+			Kind:        KindCard,
+			Description: "Visa",
+			Card:        Card{Number: "4111111111111111", Expiry: "07/31", CVV: "123", Holder: "ALICE"},
+		},
+		{Kind: KindText, Description: "Visa (notes)", Text: "backup card"},
+		{Kind: KindText, Description: "Recipe", Text: "flour"},
+		{Kind: KindText, Description: "Passport", Text: "firstName: Alice\nlastName: Liddell\npassportNumber: X123"},
+	}, result.Records)
+	assert.Equal(t, []Skipped{{Entry: "Key", Reason: "unknown item type 5"}}, result.Skipped)
+
+	var _, encryptedError = Parse(FormatBitwarden, strings.NewReader(`{"encrypted": true}`))
+	assert.NotNil(t, encryptedError)
+}
+
+func TestCSV(t *testing.T) {
+	t.Run("1Password", func(t *testing.T) {
+		var export = "Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+			"Bank,https://bank.example.com,alice,hunter2,,false,false,,\"security question:\nfirst pet\"\n"
+		var format, formatError = Detect("1password.csv", ([]byte)(export))
+		assert.Nil(t, formatError)
+		assert.Equal(t, Format1Password, format)
+
+		var result, parseError = Parse(format, strings.NewReader(export))
+		assert.Nil(t, parseError)
+		assert.Equal(t, []Record{
+			{
+				Kind:        KindCredential,
+				Description: "Bank\nhttps://bank.example.com",
+				Username:    "alice",
+				Password:    "hunter2",
+				Skipped:     []string{"favorite", "archived"},
+			},
+			{Kind: KindText, Description: "Bank (notes)", Text: "security question:\nfirst pet"},
+		}, result.Records)
+	})
+	t.Run("Browser", func(t *testing.T) {
+		var export = "\ufeffurl,username,password,httpRealm,formActionOrigin,guid,timeCreated,timeLastUsed,timePasswordChanged\n" +
+			"https://shop.example.com,bob,qwerty,,,{0a1b},1700000000000,,\n" +
+			",,,,,,,,\n"
+		var format, formatError = Detect("logins.csv", ([]byte)(export))
+		assert.Nil(t, formatError)
+		assert.Equal(t, FormatBrowser, format)
+
+		var result, parseError = Parse(format, strings.NewReader(export))
+		assert.Nil(t, parseError)
+		assert.Equal(t, []Record{
+			{
+				Kind:        KindCredential,
+				Description: "https://shop.example.com",
+				Username:    "bob",
+				Password:    "qwerty",
+				Skipped:     []string{"guid", "timecreated"},
+			},
+		}, result.Records)
+		assert.Equal(t, []Skipped{{Entry: "row 3", Reason: "row is empty"}}, result.Skipped)
+	})
+}
+
+func TestDetect(t *testing.T) {
+	var _, detectError = Detect("vault.gkv", ([]byte)("GKVAULT\x00"))
+	assert.ErrorIs(t, detectError, ErrUnknownFormat)
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type keepassFile struct {
+	Meta struct {
+		Binaries []keepassBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []keepassGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type keepassBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed bool   `xml:"Compressed,attr"`
+	Content    string `xml:",chardata"`
+}
+
+type keepassGroup struct {
+	Name    string         `xml:"Name"`
+	Entries []keepassEntry `xml:"Entry"`
+	Groups  []keepassGroup `xml:"Group"`
+}
+
+type keepassEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref string `xml:"Ref,attr"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+}
+
+// keepassRecycleBin is the group of deleted entries, they are not imported.
+const keepassRecycleBin = "Recycle Bin"
+
+func parseKeePass(in io.Reader) (Result, error) {
+	var file keepassFile
+	if err := xml.NewDecoder(in).Decode(&file); err != nil {
+		return Result{}, fmt.Errorf("parse KeePass XML: %w", err)
+	}
+
+	var binaries = make(map[string][]byte, len(file.Meta.Binaries))
+	for _, binary := range file.Meta.Binaries {
+		var content, contentError = binary.decode()
+		if contentError != nil {
+			return Result{}, fmt.Errorf("parse KeePass XML: binary %s: %w", binary.ID, contentError)
+		}
+		binaries[binary.ID] = content
+	}
+
+	var result Result
+	var walk func(groups []keepassGroup)
+	walk = func(groups []keepassGroup) {
+		for _, group := range groups {
+			if group.Name == keepassRecycleBin {
+				continue
+			}
+			for _, e := range group.Entries {
+				var entry entry
+				for _, s := range e.Strings {
+					switch s.Key {
+					case "Title":
+						entry.title = s.Value
+					case "URL":
+						entry.url = s.Value
+					case "UserName":
+						entry.username = s.Value
+					case "Password":
+						entry.password = s.Value
+					case "Notes":
+						entry.notes = s.Value
+					default:
+						if s.Value != "" {
+							entry.skipped = append(entry.skipped, s.Key)
+						}
+					}
+				}
+				for _, b := range e.Binaries {
+					var content, ok = binaries[b.Value.Ref]
+					if !ok {
+						entry.skipped = append(entry.skipped, b.Key)
+						continue
+					}
+					entry.files = append(entry.files, File{Name: b.Key, Content: content})
+				}
+				var records = entry.records()
+				if len(records) == 0 {
+					result.Skipped = append(result.Skipped, Skipped{Entry: entry.title, Reason: "entry is empty"})
+				}
+				result.Records = append(result.Records, records...)
+			}
+			walk(group.Groups)
+		}
+	}
+	walk(file.Root.Groups)
+	return result, nil
+}
+
+func (b keepassBinary) decode() ([]byte, error) {
+	var content, decodeError = base64.StdEncoding.DecodeString(strings.TrimSpace(b.Content))
+	if decodeError != nil {
+		return nil, decodeError
+	}
+	if !b.Compressed {
+		return content, nil
+	}
+	var reader, readerError = gzip.NewReader(bytes.NewReader(content))
+	if readerError != nil {
+		return nil, readerError
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,42 @@
+package metrics
+
+// Gophkeeper server metrics registered in Default.
+var (
+	// Requests counts REST requests by route, method and status.
+	Requests = Default.Counter(
+		"gophkeeper_http_requests_total",
+		"Number of REST requests.",
+		"route", "method", "status",
+	)
+	// RequestDuration observes REST request latency by route and method.
+	RequestDuration = Default.Histogram(
+		"gophkeeper_http_request_duration_seconds",
+		"REST request latency in seconds.",
+		DefaultBuckets,
+		"route", "method",
+	)
+	// KeyDerivationDuration observes bcrypt and PBKDF2 timing by algorithm.
+	KeyDerivationDuration = Default.Histogram(
+		"gophkeeper_key_derivation_duration_seconds",
+		"Password hashing and key derivation time in seconds.",
+		DefaultBuckets,
+		"algorithm",
+	)
+	// ActiveUploads is the number of blob uploads in progress.
+	ActiveUploads = Default.Gauge(
+		"gophkeeper_active_uploads",
+		"Number of blob uploads in progress.",
+	)
+	// BytesStored counts plaintext bytes stored by resource type.
+	BytesStored = Default.Counter(
+		"gophkeeper_stored_bytes_total",
+		"Number of bytes stored.",
+		"type",
+	)
+	// BytesRestored counts plaintext bytes restored by resource type.
+	BytesRestored = Default.Counter(
+		"gophkeeper_restored_bytes_total",
+		"Number of bytes restored.",
+		"type",
+	)
+)
@@ -0,0 +1,324 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are default histogram buckets in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry is a set of metrics exposed in Prometheus text format.
+type Registry struct {
+	mutex   sync.Mutex
+	metrics []metric
+	funcs   map[string]int
+}
+
+type metric interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Default is the default registry.
+var Default = &Registry{}
+
+// Counter registers a new counter.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	var c = &Counter{vector: newVector(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// Gauge registers a new gauge.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	var g = &Gauge{vector: newVector(name, help, "gauge", labels)}
+	r.register(g)
+	return g
+}
+
+// Histogram registers a new histogram with the buckets.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	var h = &Histogram{
+		vector:  newVector(name, help, "histogram", labels),
+		buckets: buckets,
+	}
+	r.register(h)
+	return h
+}
+
+// GaugeFunc registers a gauge whose value is read from value on every scrape.
+//
+// Registering a GaugeFunc with the same name replaces the previous one.
+func (r *Registry) GaugeFunc(name, help string, value func() float64) {
+	r.registerFunc(&valueFunc{metricName: name, help: help, kind: "gauge", value: value})
+}
+
+// CounterFunc registers a counter whose value is read from value on every scrape,
+// value must never decrease.
+//
+// Registering a CounterFunc with the same name replaces the previous one.
+func (r *Registry) CounterFunc(name, help string, value func() float64) {
+	r.registerFunc(&valueFunc{metricName: name, help: help, kind: "counter", value: value})
+}
+
+func (r *Registry) registerFunc(g *valueFunc) {
+	var name = g.metricName
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.funcs == nil {
+		r.funcs = make(map[string]int)
+	}
+	if i, ok := r.funcs[name]; ok {
+		r.metrics[i] = g
+		return
+	}
+	r.funcs[name] = len(r.metrics)
+	r.metrics = append(r.metrics, g)
+}
+
+// Write writes all metrics in Prometheus text format.
+func (r *Registry) Write(w io.Writer) {
+	r.mutex.Lock()
+	var metrics = append(([]metric)(nil), r.metrics...)
+	r.mutex.Unlock()
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].name() < metrics[j].name()
+	})
+	for _, m := range metrics {
+		m.write(w)
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (r *Registry) ServeHTTP(out http.ResponseWriter, _ *http.Request) {
+	out.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	out.WriteHeader(http.StatusOK)
+	r.Write(out)
+}
+
+func (r *Registry) register(m metric) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// Counter is a monotonically increasing metric.
+type Counter struct {
+	*vector
+}
+
+// Add adds delta to the counter with the label values.
+func (c *Counter) Add(delta float64, labels ...string) {
+	c.series(labels).add(delta)
+}
+
+// Inc increments the counter with the label values.
+func (c *Counter) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.writeHeader(w)
+	c.each(func(labels string, s *series) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, labels, formatFloat(s.value))
+	})
+}
+
+// Gauge is a metric that can go up and down.
+type Gauge struct {
+	*vector
+}
+
+// Add adds delta to the gauge with the label values.
+func (g *Gauge) Add(delta float64, labels ...string) {
+	g.series(labels).add(delta)
+}
+
+// Set sets the gauge with the label values.
+func (g *Gauge) Set(value float64, labels ...string) {
+	var s = g.series(labels)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.value = value
+}
+
+func (g *Gauge) write(w io.Writer) {
+	g.writeHeader(w)
+	g.each(func(labels string, s *series) {
+		fmt.Fprintf(w, "%s%s %s\n", g.metricName, labels, formatFloat(s.value))
+	})
+}
+
+// Histogram counts observations in buckets.
+type Histogram struct {
+	*vector
+	buckets []float64
+}
+
+// Observe records value in the histogram with the label values.
+func (h *Histogram) Observe(value float64, labels ...string) {
+	var s = h.series(labels)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.buckets == nil {
+		s.buckets = make([]uint64, len(h.buckets))
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.count++
+	s.value += value
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.writeHeader(w)
+	h.each(func(labels string, s *series) {
+		for i, bound := range h.buckets {
+			var count uint64
+			if s.buckets != nil {
+				count = s.buckets[i]
+			}
+			fmt.Fprintf(
+				w, "%s_bucket%s %d\n",
+				h.metricName, withLabel(labels, "le", formatFloat(bound)), count,
+			)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, withLabel(labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, labels, formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, labels, s.count)
+	})
+}
+
+type valueFunc struct {
+	metricName string
+	help       string
+	kind       string
+	value      func() float64
+}
+
+func (g *valueFunc) name() string {
+	return g.metricName
+}
+
+func (g *valueFunc) write(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", g.metricName, g.help, g.metricName, g.kind)
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatFloat(g.value()))
+}
+
+type vector struct {
+	metricName string
+	help       string
+	kind       string
+	labels     []string
+
+	mutex  sync.Mutex
+	values map[string]*series
+}
+
+type series struct {
+	mutex   sync.Mutex
+	value   float64
+	count   uint64
+	buckets []uint64
+}
+
+func newVector(name, help, kind string, labels []string) *vector {
+	return &vector{
+		metricName: name,
+		help:       help,
+		kind:       kind,
+		labels:     labels,
+		values:     make(map[string]*series),
+	}
+}
+
+func (v *vector) name() string {
+	return v.metricName
+}
+
+func (v *vector) series(values []string) *series {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", v.metricName, len(v.labels), len(values)))
+	}
+	var key = v.formatLabels(values)
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	var s, ok = v.values[key]
+	if !ok {
+		s = &series{}
+		v.values[key] = s
+	}
+	return s
+}
+
+func (v *vector) each(f func(labels string, s *series)) {
+	v.mutex.Lock()
+	var keys = make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	v.mutex.Unlock()
+	sort.Strings(keys)
+	for _, key := range keys {
+		v.mutex.Lock()
+		var s = v.values[key]
+		v.mutex.Unlock()
+		s.mutex.Lock()
+		f(key, s)
+		s.mutex.Unlock()
+	}
+}
+
+func (v *vector) writeHeader(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.metricName, v.help, v.metricName, v.kind)
+}
+
+func (v *vector) formatLabels(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	var pairs = make([]string, 0, len(values))
+	for i, value := range values {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", v.labels[i], quote(value)))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (s *series) add(delta float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.value += delta
+}
+
+func withLabel(labels, name, value string) string {
+	var pair = fmt.Sprintf("%s=%s", name, quote(value))
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return strings.TrimSuffix(labels, "}") + "," + pair + "}"
+}
+
+func quote(value string) string {
+	var replacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("Counter is written with labels", func(t *testing.T) {
+		var registry Registry
+		var counter = registry.Counter("requests_total", "Requests.", "route")
+		counter.Inc("/vault")
+		counter.Add(2, "/vault")
+		var output strings.Builder
+		registry.Write(&output)
+		assert.Equal(
+			t,
+			"# HELP requests_total Requests.\n# TYPE requests_total counter\nrequests_total{route=\"/vault\"} 3\n",
+			output.String(),
+			"unexpected counter exposition",
+		)
+	})
+	t.Run("Histogram buckets are cumulative", func(t *testing.T) {
+		var registry Registry
+		var histogram = registry.Histogram("duration_seconds", "Duration.", []float64{1, 2})
+		histogram.Observe(0.5)
+		histogram.Observe(1.5)
+		histogram.Observe(3)
+		var output strings.Builder
+		registry.Write(&output)
+		assert.Contains(t, output.String(), "duration_seconds_bucket{le=\"1\"} 1\n")
+		assert.Contains(t, output.String(), "duration_seconds_bucket{le=\"2\"} 2\n")
+		assert.Contains(t, output.String(), "duration_seconds_bucket{le=\"+Inf\"} 3\n")
+		assert.Contains(t, output.String(), "duration_seconds_sum 5\n")
+		assert.Contains(t, output.String(), "duration_seconds_count 3\n")
+	})
+	t.Run("GaugeFunc with the same name replaces the previous one", func(t *testing.T) {
+		var registry Registry
+		registry.GaugeFunc("connections", "Connections.", func() float64 { return 1 })
+		registry.GaugeFunc("connections", "Connections.", func() float64 { return 2 })
+		var output strings.Builder
+		registry.Write(&output)
+		assert.Equal(
+			t,
+			"# HELP connections Connections.\n# TYPE connections gauge\nconnections 2\n",
+			output.String(),
+			"expected only the latest gauge func",
+		)
+	})
+	t.Run("CounterFunc is exposed as a counter", func(t *testing.T) {
+		var registry Registry
+		registry.CounterFunc("acquires_total", "Acquires.", func() float64 { return 3 })
+		var output strings.Builder
+		registry.Write(&output)
+		assert.Equal(
+			t,
+			"# HELP acquires_total Acquires.\n# TYPE acquires_total counter\nacquires_total 3\n",
+			output.String(),
+		)
+	})
+}
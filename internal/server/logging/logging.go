@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+type contextKey struct{}
+
+type request struct {
+	id string
+
+	mutex    sync.Mutex
+	username string
+}
+
+// WithRequest returns a context carrying the request ID.
+func WithRequest(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &request{id: id})
+}
+
+// RequestID returns the request ID carried by the context,
+// or an empty string if there is none.
+func RequestID(ctx context.Context) string {
+	if r, ok := ctx.Value(contextKey{}).(*request); ok {
+		return r.id
+	}
+	return ""
+}
+
+// SetUsername records the username the request is made on behalf of.
+func SetUsername(ctx context.Context, username string) {
+	if r, ok := ctx.Value(contextKey{}).(*request); ok {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.username = username
+	}
+}
+
+// Username returns the username recorded with SetUsername.
+func Username(ctx context.Context) string {
+	if r, ok := ctx.Value(contextKey{}).(*request); ok {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		return r.username
+	}
+	return ""
+}
+
+// Logger returns the default logger with the request ID
+// carried by the context attached.
+func Logger(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
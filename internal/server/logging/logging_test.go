@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest(t *testing.T) {
+	t.Run("Context without request has no ID and username", func(t *testing.T) {
+		var ctx = context.Background()
+		SetUsername(ctx, "gopher")
+		assert.Empty(t, RequestID(ctx), "expected no request ID")
+		assert.Empty(t, Username(ctx), "expected no username")
+	})
+	t.Run("Username set deeper is visible through the request context", func(t *testing.T) {
+		var ctx = WithRequest(context.Background(), "42")
+		var deeper, cancel = context.WithCancel(ctx)
+		defer cancel()
+		SetUsername(deeper, "gopher")
+		assert.Equal(t, "42", RequestID(ctx), "request ID doesn't match")
+		assert.Equal(t, "gopher", Username(ctx), "username doesn't match")
+	})
+}
@@ -0,0 +1,246 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kerelape/gophkeeper/internal/metrics"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Administration is a postgres administration.
+type Administration struct {
+	Pool             *pgxpool.Pool
+	PasswordEncoding *base64.Encoding
+	BcryptCost       int
+	PasswordPolicy   *gophkeeper.PasswordPolicy
+
+	Username string // Username is the admin's username.
+}
+
+var _ gophkeeper.Administration = (*Administration)(nil)
+
+// Identities implements Administration.
+func (a *Administration) Identities(ctx context.Context) ([]gophkeeper.IdentityInfo, error) {
+	var rows, rowsError = a.Pool.Query(
+		ctx,
+		`SELECT identities.username, identities.admin, identities.disabled,
+				identities.created_at, identities.last_login,
+				COUNT(pieces.id), COUNT(blobs.id),
+				COALESCE(SUM(octet_length(pieces.content)), 0),
+				COALESCE(SUM(blobs.size), 0)
+			FROM identities
+			LEFT JOIN resources ON resources.owner = identities.username
+			LEFT JOIN pieces ON pieces.resource = resources.id
+			LEFT JOIN blobs ON blobs.resource = resources.id
+			GROUP BY identities.username
+			ORDER BY identities.username`,
+	)
+	if rowsError != nil {
+		return nil, rowsError
+	}
+	defer rows.Close()
+
+	var identities = make([]gophkeeper.IdentityInfo, 0)
+	for rows.Next() {
+		var (
+			identity  gophkeeper.IdentityInfo
+			lastLogin *time.Time
+		)
+		var scanError = rows.Scan(
+			&identity.Username, &identity.Admin, &identity.Disabled,
+			&identity.CreatedAt, &lastLogin,
+			&identity.Pieces, &identity.Blobs,
+			&identity.PieceBytes, &identity.BlobBytes,
+		)
+		if scanError != nil {
+			return nil, scanError
+		}
+		if lastLogin != nil {
+			identity.LastLogin = *lastLogin
+		}
+		identities = append(identities, identity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// SetDisabled implements Administration.
+func (a *Administration) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	var result, updateError = a.Pool.Exec(
+		ctx,
+		`UPDATE identities SET disabled = $2 WHERE username = $1`,
+		username, disabled,
+	)
+	if updateError != nil {
+		return updateError
+	}
+	if result.RowsAffected() == 0 {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	slog.Info("identity disabled changed", "admin", a.Username, "identity", username, "disabled", disabled)
+	return nil
+}
+
+// Logout implements Administration.
+func (a *Administration) Logout(ctx context.Context, username string) error {
+	var result, updateError = a.Pool.Exec(
+		ctx,
+		`UPDATE identities SET tokens_not_before = $2 WHERE username = $1`,
+		username, tokensNotBefore(),
+	)
+	if updateError != nil {
+		return updateError
+	}
+	if result.RowsAffected() == 0 {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	slog.Info("identity logged out", "admin", a.Username, "identity", username)
+	return nil
+}
+
+// Delete implements Administration.
+func (a *Administration) Delete(ctx context.Context, username string) error {
+	var blobs, deleteError = deleteIdentity(ctx, a.Pool, username)
+	if deleteError != nil {
+		return deleteError
+	}
+	slog.Info("identity deleted", "admin", a.Username, "identity", username, "blobs", blobs)
+	return nil
+}
+
+// ResetCredential implements Administration.
+func (a *Administration) ResetCredential(ctx context.Context, username, password string) error {
+	if password == "" {
+		return gophkeeper.ErrBadCredential
+	}
+	if a.PasswordPolicy != nil {
+		if err := a.PasswordPolicy.Check(username, password); err != nil {
+			return err
+		}
+	}
+	var start = time.Now()
+	var hash, hashError = bcrypt.GenerateFromPassword(([]byte)(password), a.BcryptCost)
+	metrics.KeyDerivationDuration.Observe(time.Since(start).Seconds(), "bcrypt")
+	if hashError != nil {
+		return hashError
+	}
+
+	var transaction, transactionError = a.Pool.Begin(ctx)
+	if transactionError != nil {
+		return transactionError
+	}
+	defer transaction.Rollback(context.Background())
+
+	var result, updateError = transaction.Exec(
+		ctx,
+		`UPDATE identities SET password = $2, tokens_not_before = $3 WHERE username = $1`,
+		username, a.PasswordEncoding.EncodeToString(hash), tokensNotBefore(),
+	)
+	if updateError != nil {
+		return updateError
+	}
+	if result.RowsAffected() == 0 {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	var locations, purgeError = purgeResources(ctx, transaction, username)
+	if purgeError != nil {
+		return purgeError
+	}
+	if err := transaction.Commit(ctx); err != nil {
+		return err
+	}
+	removeBlobFiles(locations)
+	slog.Info("identity credential reset", "admin", a.Username, "identity", username, "blobs", len(locations))
+	return nil
+}
+
+// SetAdmin grants or revokes the admin role of the identity.
+func SetAdmin(ctx context.Context, pool *pgxpool.Pool, username string, admin bool) error {
+	var result, updateError = pool.Exec(
+		ctx,
+		`UPDATE identities SET admin = $2 WHERE username = $1`,
+		username, admin,
+	)
+	if updateError != nil {
+		return updateError
+	}
+	if result.RowsAffected() == 0 {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	return nil
+}
+
+// deleteIdentity deletes the identity with all of its resources
+// and blob files and returns the number of blob files removed.
+func deleteIdentity(ctx context.Context, pool *pgxpool.Pool, username string) (int, error) {
+	var transaction, transactionError = pool.Begin(ctx)
+	if transactionError != nil {
+		return 0, transactionError
+	}
+	defer transaction.Rollback(context.Background())
+
+	var locations, purgeError = purgeResources(ctx, transaction, username)
+	if purgeError != nil {
+		return 0, purgeError
+	}
+	var result, deleteError = transaction.Exec(
+		ctx,
+		`DELETE FROM identities WHERE username = $1`,
+		username,
+	)
+	if deleteError != nil {
+		return 0, deleteError
+	}
+	if result.RowsAffected() == 0 {
+		return 0, gophkeeper.ErrIdentityNotFound
+	}
+	if err := transaction.Commit(ctx); err != nil {
+		return 0, err
+	}
+	removeBlobFiles(locations)
+	return len(locations), nil
+}
+
+// purgeResources deletes all resources of the identity in the transaction
+// and returns locations of their blob files to remove once it is committed.
+func purgeResources(ctx context.Context, transaction pgx.Tx, username string) ([]string, error) {
+	var rows, rowsError = transaction.Query(
+		ctx,
+		`SELECT blobs.location
+			FROM resources JOIN blobs ON blobs.resource = resources.id
+			WHERE resources.owner = $1
+			FOR UPDATE OF resources`,
+		username,
+	)
+	if rowsError != nil {
+		return nil, rowsError
+	}
+	var locations, collectError = pgx.CollectRows(rows, pgx.RowTo[string])
+	if collectError != nil {
+		return nil, collectError
+	}
+	if _, err := transaction.Exec(ctx, `DELETE FROM resources WHERE owner = $1`, username); err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+// removeBlobFiles removes blob files of purged resources,
+// files that fail to be removed are left to the blob collector.
+func removeBlobFiles(locations []string) {
+	for _, location := range locations {
+		if err := os.Remove(location); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Error("failed to remove blob file", "location", location, "error", err)
+		}
+	}
+}
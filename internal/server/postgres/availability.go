@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// uploadError is a failure to read or stage the content of a blob,
+// it is never caused by the database.
+type uploadError struct {
+	err error
+}
+
+// Error implements error.
+func (e *uploadError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the cause.
+func (e *uploadError) Unwrap() error {
+	return e.err
+}
+
+// connectionLost tells whether the error is caused by a lost
+// connection to the database rather than by the query itself.
+func connectionLost(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var (
+		pgError *pgconn.PgError
+		upload  *uploadError
+	)
+	if errors.As(err, &pgError) || errors.As(err, &upload) {
+		return false
+	}
+	// pgconn's errors of the connection tell whether the query may be retried.
+	var connectionError interface{ SafeToRetry() bool }
+	if errors.As(err, &connectionError) {
+		return true
+	}
+	// Failures to connect wrap the network error.
+	var netError net.Error
+	return errors.As(err, &netError)
+}
+
+// unavailable reports a lost connection as gophkeeper.ErrUnavailable and
+// marks the database unavailable until Run restores the connection.
+// The cause is not wrapped, so an error joined with ErrBadCredential
+// by a failed password check is not taken for a wrong password.
+func (r *Gophkeeper) unavailable(err error) error {
+	if !connectionLost(err) {
+		return err
+	}
+	if r.available.CompareAndSwap(true, false) {
+		slog.Warn("database connection is lost", "error", err)
+	}
+	return fmt.Errorf("%w: %v", gophkeeper.ErrUnavailable, err)
+}
+
+// availableIdentity reports lost connections of an identity's queries.
+type availableIdentity struct {
+	origin     gophkeeper.Identity
+	gophkeeper *Gophkeeper
+}
+
+var _ gophkeeper.Identity = (*availableIdentity)(nil)
+
+// StorePiece implements Identity.
+func (i *availableIdentity) StorePiece(ctx context.Context, piece gophkeeper.Piece, password string) (gophkeeper.ResourceID, error) {
+	var rid, err = i.origin.StorePiece(ctx, piece, password)
+	return rid, i.gophkeeper.unavailable(err)
+}
+
+// RestorePiece implements Identity.
+func (i *availableIdentity) RestorePiece(ctx context.Context, rid gophkeeper.ResourceID, password string) (gophkeeper.Piece, error) {
+	var piece, err = i.origin.RestorePiece(ctx, rid, password)
+	return piece, i.gophkeeper.unavailable(err)
+}
+
+// StoreBlob implements Identity.
+func (i *availableIdentity) StoreBlob(ctx context.Context, blob gophkeeper.Blob, password string) (gophkeeper.ResourceID, error) {
+	var rid, err = i.origin.StoreBlob(ctx, blob, password)
+	return rid, i.gophkeeper.unavailable(err)
+}
+
+// RestoreBlob implements Identity.
+func (i *availableIdentity) RestoreBlob(ctx context.Context, rid gophkeeper.ResourceID, password string) (gophkeeper.Blob, error) {
+	var blob, err = i.origin.RestoreBlob(ctx, rid, password)
+	return blob, i.gophkeeper.unavailable(err)
+}
+
+// Delete implements Identity.
+func (i *availableIdentity) Delete(ctx context.Context, rid gophkeeper.ResourceID) error {
+	return i.gophkeeper.unavailable(i.origin.Delete(ctx, rid))
+}
+
+// List implements Identity.
+func (i *availableIdentity) List(ctx context.Context) ([]gophkeeper.Resource, error) {
+	var resources, err = i.origin.List(ctx)
+	return resources, i.gophkeeper.unavailable(err)
+}
+
+// DeleteIdentity implements Identity.
+func (i *availableIdentity) DeleteIdentity(ctx context.Context, password string) error {
+	return i.gophkeeper.unavailable(i.origin.DeleteIdentity(ctx, password))
+}
+
+// Profile implements Identity.
+func (i *availableIdentity) Profile(ctx context.Context) (gophkeeper.Profile, error) {
+	var profile, err = i.origin.Profile(ctx)
+	return profile, i.gophkeeper.unavailable(err)
+}
+
+// Invite implements Identity.
+func (i *availableIdentity) Invite(ctx context.Context) (gophkeeper.Invite, error) {
+	var invite, err = i.origin.Invite(ctx)
+	return invite, i.gophkeeper.unavailable(err)
+}
+
+// availableAdministration reports lost connections of an administration's queries.
+type availableAdministration struct {
+	origin     gophkeeper.Administration
+	gophkeeper *Gophkeeper
+}
+
+var _ gophkeeper.Administration = (*availableAdministration)(nil)
+
+// Identities implements Administration.
+func (a *availableAdministration) Identities(ctx context.Context) ([]gophkeeper.IdentityInfo, error) {
+	var identities, err = a.origin.Identities(ctx)
+	return identities, a.gophkeeper.unavailable(err)
+}
+
+// SetDisabled implements Administration.
+func (a *availableAdministration) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	return a.gophkeeper.unavailable(a.origin.SetDisabled(ctx, username, disabled))
+}
+
+// Logout implements Administration.
+func (a *availableAdministration) Logout(ctx context.Context, username string) error {
+	return a.gophkeeper.unavailable(a.origin.Logout(ctx, username))
+}
+
+// Delete implements Administration.
+func (a *availableAdministration) Delete(ctx context.Context, username string) error {
+	return a.gophkeeper.unavailable(a.origin.Delete(ctx, username))
+}
+
+// ResetCredential implements Administration.
+func (a *availableAdministration) ResetCredential(ctx context.Context, username, password string) error {
+	return a.gophkeeper.unavailable(a.origin.ResetCredential(ctx, username, password))
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnavailable(t *testing.T) {
+	t.Run("Lost connection marks the database unavailable", func(t *testing.T) {
+		var r Gophkeeper
+		r.available.Store(true)
+		var err = r.unavailable(errors.Join(gophkeeper.ErrBadCredential, &net.OpError{Op: "read", Err: io.EOF}))
+		assert.ErrorIs(t, err, gophkeeper.ErrUnavailable)
+		assert.NotErrorIs(t, err, gophkeeper.ErrBadCredential)
+		assert.False(t, r.Available())
+	})
+	t.Run("Query errors are kept", func(t *testing.T) {
+		var r Gophkeeper
+		r.available.Store(true)
+		for _, origin := range []error{nil, gophkeeper.ErrBadCredential, context.Canceled} {
+			assert.Equal(t, origin, r.unavailable(origin))
+		}
+		assert.True(t, r.Available())
+	})
+	t.Run("Truncated upload keeps the database available", func(t *testing.T) {
+		var r Gophkeeper
+		r.available.Store(true)
+		var dir = t.TempDir()
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, blobsStagingDir), 0700))
+		var block, blockError = aes.NewCipher(make([]byte, keyLen))
+		assert.NoError(t, blockError)
+		for _, cause := range []error{io.ErrUnexpectedEOF, &net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}} {
+			var content = io.MultiReader(bytes.NewReader([]byte("Hello")), iotest.ErrReader(cause))
+			var _, _, stageError = stageBlob(dir, content, cipher.NewCTR(block, make([]byte, block.BlockSize())))
+			var err = r.unavailable(stageError)
+			assert.ErrorIs(t, err, cause)
+			assert.NotErrorIs(t, err, gophkeeper.ErrUnavailable)
+		}
+		assert.True(t, r.Available())
+	})
+}
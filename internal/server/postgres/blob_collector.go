@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pior/runnable"
+)
+
+// BlobsReport is the result of cross-checking blob files against the database.
+type BlobsReport struct {
+	// Unreferenced are files in the blobs directory that no blobs row
+	// references and that are older than the grace period.
+	Unreferenced []string
+	// Removed are the unreferenced files that were removed.
+	Removed []string
+	// Missing are blobs rows whose files do not exist.
+	Missing []MissingBlob
+}
+
+// MissingBlob is a blobs row whose file does not exist.
+type MissingBlob struct {
+	Resource int64
+	Location string
+}
+
+// Log logs every finding of the report.
+func (r BlobsReport) Log() {
+	for _, location := range r.Unreferenced {
+		slog.Warn("unreferenced blob file", "location", location)
+	}
+	for _, location := range r.Removed {
+		slog.Info("removed blob file", "location", location)
+	}
+	for _, blob := range r.Missing {
+		slog.Error("blob file is missing", "resource", blob.Resource, "location", blob.Location)
+	}
+}
+
+// CollectBlobs cross-checks blobsDir against the blobs table.
+//
+// Files not referenced by any row and last modified before the grace period
+// are reported, and removed if remove is true. Rows whose files are missing
+// are only reported.
+func CollectBlobs(ctx context.Context, pool *pgxpool.Pool, blobsDir string, gracePeriod time.Duration, remove bool) (BlobsReport, error) {
+	var report BlobsReport
+
+	var rows, rowsError = pool.Query(ctx, `SELECT resource, location FROM blobs`)
+	if rowsError != nil {
+		return report, rowsError
+	}
+	defer rows.Close()
+	var locations = make(map[string]struct{})
+	for rows.Next() {
+		var blob MissingBlob
+		if err := rows.Scan(&blob.Resource, &blob.Location); err != nil {
+			return report, err
+		}
+		locations[filepath.Clean(blob.Location)] = struct{}{}
+		if _, err := os.Stat(blob.Location); errors.Is(err, os.ErrNotExist) {
+			report.Missing = append(report.Missing, blob)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return report, err
+	}
+
+	var deadline = time.Now().Add(-gracePeriod)
+	var unreferenced, unreferencedError = unreferencedBlobs(blobsDir, locations, deadline)
+	if unreferencedError != nil {
+		return report, unreferencedError
+	}
+	// Staged files are never referenced, old ones are left by failed stores.
+	var staged, stagedError = unreferencedBlobs(filepath.Join(blobsDir, blobsStagingDir), nil, deadline)
+	if stagedError != nil {
+		return report, stagedError
+	}
+	unreferenced = append(unreferenced, staged...)
+	report.Unreferenced = unreferenced
+	if remove {
+		for _, location := range unreferenced {
+			if err := os.Remove(location); err != nil {
+				slog.Error("failed to remove blob file", "location", location, "error", err)
+				continue
+			}
+			report.Removed = append(report.Removed, location)
+		}
+	}
+	return report, nil
+}
+
+// unreferencedBlobs returns the files in blobsDir that are not in locations
+// and were last modified before the deadline.
+func unreferencedBlobs(blobsDir string, locations map[string]struct{}, deadline time.Time) ([]string, error) {
+	var entries, entriesError = os.ReadDir(blobsDir)
+	if entriesError != nil {
+		if errors.Is(entriesError, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, entriesError
+	}
+	var unreferenced []string
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		var location = filepath.Clean(filepath.Join(blobsDir, entry.Name()))
+		if _, ok := locations[location]; ok {
+			continue
+		}
+		var info, infoError = entry.Info()
+		if infoError != nil {
+			if errors.Is(infoError, os.ErrNotExist) {
+				continue
+			}
+			return nil, infoError
+		}
+		if info.ModTime().After(deadline) {
+			continue
+		}
+		unreferenced = append(unreferenced, location)
+	}
+	return unreferenced, nil
+}
+
+// BlobCollector periodically collects blob files of a Gophkeeper.
+type BlobCollector struct {
+	Gophkeeper *Gophkeeper
+
+	Interval    time.Duration
+	GracePeriod time.Duration
+	Remove      bool
+}
+
+var _ runnable.Runnable = (*BlobCollector)(nil)
+
+// Run implements runnable.Runnable.
+func (c *BlobCollector) Run(ctx context.Context) error {
+	var pool, poolError = c.Gophkeeper.pool.Get(ctx)
+	if poolError != nil {
+		return poolError
+	}
+	var ticker = time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			var report, err = CollectBlobs(ctx, pool, c.Gophkeeper.BlobsDir, c.GracePeriod, c.Remove)
+			if err != nil {
+				slog.Error("failed to collect blobs", "error", err)
+				continue
+			}
+			report.Log()
+		}
+	}
+}
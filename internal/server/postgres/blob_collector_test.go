@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnreferencedBlobs(t *testing.T) {
+	var (
+		dir        = t.TempDir()
+		referenced = filepath.Join(dir, "referenced")
+		orphaned   = filepath.Join(dir, "orphaned")
+		fresh      = filepath.Join(dir, "fresh")
+		old        = time.Now().Add(-time.Hour)
+	)
+	for _, location := range []string{referenced, orphaned, fresh} {
+		assert.NoError(t, os.WriteFile(location, nil, 0600))
+	}
+	assert.NoError(t, os.Chtimes(referenced, old, old))
+	assert.NoError(t, os.Chtimes(orphaned, old, old))
+
+	var unreferenced, err = unreferencedBlobs(
+		dir,
+		map[string]struct{}{referenced: {}},
+		time.Now().Add(-time.Minute),
+	)
+	assert.NoError(t, err, "expected blob dir to be scanned")
+	assert.Equal(t, []string{orphaned}, unreferenced, "expected only old unreferenced files")
+
+	t.Run("Missing blob directory has no unreferenced files", func(t *testing.T) {
+		var unreferenced, err = unreferencedBlobs(filepath.Join(dir, "missing"), nil, time.Now())
+		assert.NoError(t, err, "expected missing dir to be ignored")
+		assert.Empty(t, unreferenced, "expected no unreferenced files")
+	})
+}
@@ -0,0 +1,123 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// blobsStagingDir is the directory inside BlobsDir where blob files
+// are written before they are published.
+//
+// It is inside BlobsDir so that publishing is an atomic rename.
+const blobsStagingDir = ".staging"
+
+var errBlobCorrupted = errors.New("blob checksum mismatch")
+
+// stageBlob encrypts content into a new staging file and syncs it to disk.
+//
+// It returns the staging file location and SHA-256 of the written file,
+// errors are uploadError.
+func stageBlob(blobsDir string, content io.Reader, stream cipher.Stream) (string, []byte, error) {
+	var location = filepath.Join(blobsDir, blobsStagingDir, uuid.New().String())
+	var file, createError = os.OpenFile(location, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if createError != nil {
+		return "", nil, &uploadError{err: createError}
+	}
+
+	var (
+		checksum = sha256.New()
+		writer   = cipher.StreamWriter{
+			S: stream,
+			W: io.MultiWriter(file, checksum),
+		}
+		reader = bufio.NewReader(content)
+	)
+	var _, writeError = reader.WriteTo(writer)
+	if writeError == nil {
+		writeError = file.Sync()
+	}
+	if err := file.Close(); err != nil && writeError == nil {
+		writeError = err
+	}
+	if writeError != nil {
+		removeBlobFile(location)
+		return "", nil, &uploadError{err: writeError}
+	}
+	return location, checksum.Sum(nil), nil
+}
+
+// publishBlob atomically moves a staged blob file to its location
+// and syncs the directory so that the rename survives a crash.
+// On failure the file is removed from wherever it is.
+func publishBlob(staged, location string) error {
+	if err := os.Rename(staged, location); err != nil {
+		removeBlobFile(staged)
+		return err
+	}
+	var dir, dirError = os.Open(filepath.Dir(location))
+	if dirError != nil {
+		removeBlobFile(location)
+		return dirError
+	}
+	defer dir.Close()
+	if err := dir.Sync(); err != nil {
+		removeBlobFile(location)
+		return err
+	}
+	return nil
+}
+
+func removeBlobFile(location string) {
+	if err := os.Remove(location); err != nil {
+		slog.Error("failed to remove blob file", "location", location, "error", err)
+	}
+}
+
+// checksumReader verifies SHA-256 of everything read
+// and fails at EOF if it doesn't match.
+type checksumReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected []byte
+}
+
+func newChecksumReader(reader io.Reader, expected []byte) *checksumReader {
+	return &checksumReader{
+		reader:   reader,
+		hash:     sha256.New(),
+		expected: expected,
+	}
+}
+
+// Read implements io.Reader.
+func (r *checksumReader) Read(p []byte) (int, error) {
+	var n, err = r.reader.Read(p)
+	r.hash.Write(p[:n])
+	if errors.Is(err, io.EOF) && !bytes.Equal(r.hash.Sum(nil), r.expected) {
+		return n, errBlobCorrupted
+	}
+	return n, err
+}
+
+// meteredReader reports the number of bytes read through it.
+type meteredReader struct {
+	reader io.Reader
+	read   func(n int)
+}
+
+// Read implements io.Reader.
+func (r *meteredReader) Read(p []byte) (int, error) {
+	var n, err = r.reader.Read(p)
+	r.read(n)
+	return n, err
+}
@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobFile(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		key     = make([]byte, keyLen)
+		content = []byte("Hello, World!")
+	)
+	assert.NoError(t, os.Mkdir(filepath.Join(dir, blobsStagingDir), 0700))
+	var block, blockError = aes.NewCipher(key)
+	assert.NoError(t, blockError)
+	var iv = make([]byte, block.BlockSize())
+
+	var staged, checksum, stageError = stageBlob(dir, bytes.NewReader(content), cipher.NewCTR(block, iv))
+	assert.NoError(t, stageError, "expected blob to be staged")
+	assert.Equal(t, filepath.Join(dir, blobsStagingDir), filepath.Dir(staged), "expected blob to be staged in staging dir")
+
+	var location = filepath.Join(dir, "blob")
+	assert.NoError(t, publishBlob(staged, location), "expected blob to be published")
+	assert.NoFileExists(t, staged, "expected staged file to be moved")
+
+	t.Run("Published blob decrypts with matching checksum", func(t *testing.T) {
+		var file, err = os.Open(location)
+		assert.NoError(t, err)
+		defer file.Close()
+		var decrypted, readError = io.ReadAll(
+			cipher.StreamReader{
+				S: cipher.NewCTR(block, iv),
+				R: newChecksumReader(file, checksum),
+			},
+		)
+		assert.NoError(t, readError, "expected checksum to match")
+		assert.Equal(t, content, decrypted, "decrypted blob doesn't match the content")
+	})
+	t.Run("Corrupted blob fails checksum verification", func(t *testing.T) {
+		var encrypted, err = os.ReadFile(location)
+		assert.NoError(t, err)
+		encrypted[0] ^= 0xFF
+		var _, readError = io.ReadAll(newChecksumReader(bytes.NewReader(encrypted), checksum))
+		assert.ErrorIs(t, readError, errBlobCorrupted, "expected checksum mismatch")
+	})
+	t.Run("Failed publish removes the staged file", func(t *testing.T) {
+		var staged, _, err = stageBlob(dir, bytes.NewReader(content), cipher.NewCTR(block, iv))
+		assert.NoError(t, err)
+		assert.Error(t, publishBlob(staged, filepath.Join(dir, "missing", "blob")))
+		assert.NoFileExists(t, staged, "expected staged file to be removed")
+	})
+}
@@ -2,36 +2,76 @@ package postgres
 
 import (
 	"context"
-	_ "embed"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/kerelape/gophkeeper/internal/deferred"
+	"github.com/kerelape/gophkeeper/internal/metrics"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 	"github.com/pior/runnable"
 	"golang.org/x/crypto/bcrypt"
 )
 
-//go:embed init.sql
-var initQuery string
+const (
+	// pingInterval is how often the database connection is checked.
+	pingInterval = 5 * time.Second
+	// minReconnectBackoff and maxReconnectBackoff bound the exponential
+	// backoff between attempts to reach an unavailable database.
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
 
 // Gophkeeper is a postgresql identity repository.
 type Gophkeeper struct {
-	connection deferred.Deferred[*pgx.Conn]
+	pool      deferred.Deferred[*pgxpool.Pool]
+	available atomic.Bool
 
 	PasswordEncoding *base64.Encoding
 
-	DSN           string
+	DSN string
+	// MaxConnections is the maximum size of the connection pool,
+	// zero means pgxpool's default.
+	MaxConnections int32
+	// StatementCacheCapacity is the number of prepared statements
+	// cached per connection, zero disables statement caching.
+	StatementCacheCapacity int
+
 	TokenSecret   []byte
 	TokenLifespan time.Duration
 	BlobsDir      string
 
+	// BcryptCost is the cost of password hashes of new identities,
+	// zero means bcrypt.DefaultCost.
+	BcryptCost int
+	// KeyIterations is the number of PBKDF2 iterations for new resources,
+	// zero means DefaultKeyIterations.
+	KeyIterations int
+
 	UsernameMinLength uint
-	PasswordMinLength uint
+	// Passwords is the policy of identities' passwords.
+	Passwords gophkeeper.PasswordPolicy
+	// UsernamePattern is what usernames must match, nil allows any.
+	UsernamePattern *regexp.Regexp
+
+	// Registration is how new identities are registered, empty means RegistrationOpen.
+	Registration RegistrationMode
+	// InviteLifespan is how long invites are valid, zero means DefaultInviteLifespan.
+	InviteLifespan time.Duration
+	// UsersInvite lets identities that are not admins create invites.
+	UsersInvite bool
 }
 
 var (
@@ -41,27 +81,49 @@ var (
 
 // Register implements Repository.
 func (r *Gophkeeper) Register(ctx context.Context, credential gophkeeper.Credential) error {
-	var connection, connectionError = r.connection.Get(ctx)
-	if connectionError != nil {
-		return connectionError
+	logging.SetUsername(ctx, credential.Username)
+	if r.Registration == RegistrationClosed {
+		return gophkeeper.ErrRegistrationClosed
+	}
+	var pool, poolError = r.database()
+	if poolError != nil {
+		return poolError
 	}
 
 	if len(credential.Username) < (int)(r.UsernameMinLength) {
 		return gophkeeper.ErrBadCredential
 	}
-	if len(credential.Password) < (int)(r.PasswordMinLength) {
+	if r.UsernamePattern != nil && !r.UsernamePattern.MatchString(credential.Username) {
 		return gophkeeper.ErrBadCredential
 	}
+	if err := r.Passwords.Check(credential.Username, credential.Password); err != nil {
+		return err
+	}
 
+	var start = time.Now()
 	var password, passwordError = bcrypt.GenerateFromPassword(
 		([]byte)(credential.Password),
-		bcrypt.DefaultCost,
+		r.bcryptCost(),
 	)
+	metrics.KeyDerivationDuration.Observe(time.Since(start).Seconds(), "bcrypt")
 	if passwordError != nil {
 		return passwordError
 	}
 
-	_, insertError := connection.Exec(
+	var transaction, transactionError = pool.Begin(ctx)
+	if transactionError != nil {
+		return r.unavailable(transactionError)
+	}
+	defer transaction.Rollback(context.Background())
+
+	// The invite is redeemed first, so that registering without one
+	// does not tell whether the username is taken.
+	if r.Registration == RegistrationInvite {
+		if err := redeemInvite(ctx, transaction, credential.Invite, credential.Username); err != nil {
+			return r.unavailable(err)
+		}
+	}
+	_, insertError := transaction.Exec(
 		ctx,
 		`INSERT INTO identities(username, password) VALUES($1, $2)`,
 		credential.Username,
@@ -71,32 +133,49 @@ func (r *Gophkeeper) Register(ctx context.Context, credential gophkeeper.Credent
 		if err := new(pgconn.PgError); errors.As(insertError, &err) && err.Code == "23505" {
 			return gophkeeper.ErrIdentityDuplicate
 		}
-		return insertError
+		return r.unavailable(insertError)
 	}
 
-	return nil
+	return r.unavailable(transaction.Commit(ctx))
 }
 
 // Authenticate implements Repository.
 func (r *Gophkeeper) Authenticate(ctx context.Context, credential gophkeeper.Credential) (gophkeeper.Token, error) {
-	var connection, connectionError = r.connection.Get(ctx)
-	if connectionError != nil {
-		return (gophkeeper.Token)(""), connectionError
+	logging.SetUsername(ctx, credential.Username)
+	var pool, poolError = r.database()
+	if poolError != nil {
+		return (gophkeeper.Token)(""), poolError
 	}
 
 	var identity = Identity{
-		Connection:       connection,
+		Pool:             pool,
 		PasswordEncoding: r.PasswordEncoding,
 		Username:         credential.Username,
 	}
 	if err := identity.comparePassword(ctx, credential.Password); err != nil {
-		return (gophkeeper.Token)(""), err
+		return (gophkeeper.Token)(""), r.unavailable(err)
 	}
 
+	var updateResult, updateError = pool.Exec(
+		ctx,
+		`UPDATE identities SET last_login = now() WHERE username = $1 AND NOT disabled`,
+		credential.Username,
+	)
+	if updateError != nil {
+		return (gophkeeper.Token)(""), r.unavailable(updateError)
+	}
+	if updateResult.RowsAffected() == 0 {
+		return (gophkeeper.Token)(""), gophkeeper.ErrBadCredential
+	}
+
+	var now = time.Now()
 	var rawToken = jwt.NewWithClaims(
 		jwt.SigningMethodHS256,
 		jwt.MapClaims{
-			"exp": time.Now().Add(r.TokenLifespan).Unix(),
+			"exp": now.Add(r.TokenLifespan).Unix(),
+			// Issue time has a precision of a microsecond, as tokens_not_before does,
+			// so a token issued right after a logout is accepted.
+			"iat": (float64)(now.UnixMicro()) / 1e6,
 			"sub": credential.Username,
 		},
 	)
@@ -109,6 +188,58 @@ func (r *Gophkeeper) Authenticate(ctx context.Context, credential gophkeeper.Cre
 
 // Identity implements Repository.
 func (r *Gophkeeper) Identity(ctx context.Context, token gophkeeper.Token) (gophkeeper.Identity, error) {
+	var pool, state, authorizeError = r.authorize(ctx, token)
+	if authorizeError != nil {
+		return nil, authorizeError
+	}
+	var identity = &Identity{
+		Pool:             pool,
+		PasswordEncoding: r.PasswordEncoding,
+		Username:         state.username,
+		BlobsDir:         r.BlobsDir,
+		KeyIterations:    r.KeyIterations,
+		Invites:          state.admin || r.UsersInvite,
+		InviteLifespan:   r.inviteLifespan(),
+		Admin:            state.admin,
+	}
+	return &availableIdentity{origin: identity, gophkeeper: r}, nil
+}
+
+// PasswordPolicy implements Repository.
+func (r *Gophkeeper) PasswordPolicy(context.Context) (gophkeeper.PasswordPolicy, error) {
+	var policy = r.Passwords
+	policy.Denylist = nil
+	return policy, nil
+}
+
+// Administration implements Repository.
+func (r *Gophkeeper) Administration(ctx context.Context, token gophkeeper.Token) (gophkeeper.Administration, error) {
+	var pool, state, authorizeError = r.authorize(ctx, token)
+	if authorizeError != nil {
+		return nil, authorizeError
+	}
+	if !state.admin {
+		return nil, gophkeeper.ErrForbidden
+	}
+	var administration = &Administration{
+		Pool:             pool,
+		PasswordEncoding: r.PasswordEncoding,
+		BcryptCost:       r.bcryptCost(),
+		PasswordPolicy:   &r.Passwords,
+		Username:         state.username,
+	}
+	return &availableAdministration{origin: administration, gophkeeper: r}, nil
+}
+
+// tokenState is the state of the identity a token is issued to.
+type tokenState struct {
+	username string
+	admin    bool
+}
+
+// authorize validates the token and checks that its identity
+// is neither disabled nor logged out since the token was issued.
+func (r *Gophkeeper) authorize(ctx context.Context, token gophkeeper.Token) (*pgxpool.Pool, tokenState, error) {
 	var parsedToken, parseTokenError = jwt.Parse(
 		(string)(token),
 		func(t *jwt.Token) (interface{}, error) {
@@ -116,54 +247,280 @@ func (r *Gophkeeper) Identity(ctx context.Context, token gophkeeper.Token) (goph
 		},
 	)
 	if parseTokenError != nil {
-		return nil, gophkeeper.ErrBadCredential
+		return nil, tokenState{}, gophkeeper.ErrBadCredential
 	}
 
 	var claims = parsedToken.Claims
 	if exp, err := claims.GetExpirationTime(); err == nil {
 		if exp.Before(time.Now()) {
-			return nil, gophkeeper.ErrBadCredential
+			return nil, tokenState{}, gophkeeper.ErrBadCredential
 		}
 	} else {
-		return nil, gophkeeper.ErrBadCredential
+		return nil, tokenState{}, gophkeeper.ErrBadCredential
 	}
 
-	var username string
+	var state tokenState
 	if sub, err := claims.GetSubject(); err == nil {
-		username = sub
+		state.username = sub
 	} else {
-		return nil, gophkeeper.ErrBadCredential
+		return nil, tokenState{}, gophkeeper.ErrBadCredential
 	}
+	logging.SetUsername(ctx, state.username)
+
+	// Tokens issued before the claim was introduced have no issue time.
+	var issuedAt = tokenIssuedAt(claims)
 
-	var connection, connectionError = r.connection.Get(ctx)
-	if connectionError != nil {
-		return nil, connectionError
+	var pool, poolError = r.database()
+	if poolError != nil {
+		return nil, tokenState{}, poolError
 	}
 
-	var identity = &Identity{
-		Connection:       connection,
-		PasswordEncoding: r.PasswordEncoding,
-		Username:         username,
-		BlobsDir:         r.BlobsDir,
+	var (
+		disabled        bool
+		tokensNotBefore *time.Time
+		createdAt       time.Time
+	)
+	var row = pool.QueryRow(
+		ctx,
+		`SELECT admin, disabled, tokens_not_before, created_at FROM identities WHERE username = $1`,
+		state.username,
+	)
+	if err := row.Scan(&state.admin, &disabled, &tokensNotBefore, &createdAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tokenState{}, gophkeeper.ErrBadCredential
+		}
+		return nil, tokenState{}, r.unavailable(err)
+	}
+	if disabled {
+		return nil, tokenState{}, gophkeeper.ErrBadCredential
+	}
+	if tokensNotBefore != nil && !issuedAt.After(*tokensNotBefore) {
+		return nil, tokenState{}, gophkeeper.ErrBadCredential
+	}
+	// A token issued to a deleted identity must not be
+	// accepted for a new identity with the same username.
+	if !issuedAt.IsZero() && issuedAt.Before(createdAt) {
+		return nil, tokenState{}, gophkeeper.ErrBadCredential
+	}
+	return pool, state, nil
+}
+
+// tokensNotBefore returns the time tokens issued until are rejected. It is taken
+// from the clock tokens are issued with, not the database's, so that a skew between
+// them does not reject new tokens or accept revoked ones.
+func tokensNotBefore() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// tokenIssuedAt returns the issue time of the token with a precision
+// of a microsecond, jwt truncates it to seconds. It is zero if the token has none.
+func tokenIssuedAt(claims jwt.Claims) time.Time {
+	var mapClaims, _ = claims.(jwt.MapClaims)
+	var iat, ok = mapClaims["iat"].(float64)
+	if !ok {
+		return time.Time{}
 	}
-	return identity, nil
+	var seconds, fraction = math.Modf(iat)
+	return time.Unix((int64)(seconds), (int64)(math.Round(fraction*1e6))*1e3)
+}
+
+func (r *Gophkeeper) inviteLifespan() time.Duration {
+	if r.InviteLifespan == 0 {
+		return DefaultInviteLifespan
+	}
+	return r.InviteLifespan
+}
+
+func (r *Gophkeeper) bcryptCost() int {
+	if r.BcryptCost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return r.BcryptCost
 }
 
 // Run implements Runnable.
+//
+// Run waits for the database to become reachable, retrying with
+// exponential backoff, and then keeps checking the connection,
+// reporting Gophkeeper as unavailable while the database is down.
 func (r *Gophkeeper) Run(ctx context.Context) error {
-	var connection, connectError = pgx.Connect(ctx, r.DSN)
-	if connectError != nil {
-		return connectError
+	if err := os.MkdirAll(filepath.Join(r.BlobsDir, blobsStagingDir), 0700); err != nil {
+		return err
+	}
+
+	var pool, poolError = Connect(ctx, r.DSN, r.MaxConnections, r.StatementCacheCapacity)
+	if poolError != nil {
+		return poolError
+	}
+	defer pool.Close()
+
+	if err := r.waitDatabase(ctx, pool); err != nil {
+		return err
+	}
+	if _, err := Migrate(ctx, pool, false); err != nil {
+		return err
+	}
+
+	r.pool.Set(pool)
+	r.available.Store(true)
+	registerPoolMetrics(pool)
+
+	var ticker = time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			// A query that lost its connection marks the database
+			// unavailable before the ping would notice.
+			if r.available.Load() && ping(ctx, pool) == nil {
+				continue
+			}
+			r.available.Store(false)
+			if err := r.waitDatabase(ctx, pool); err != nil {
+				return err
+			}
+			r.available.Store(true)
+			slog.Info("database connection is restored")
+		}
+	}
+}
+
+// Available reports whether the database is reachable.
+func (r *Gophkeeper) Available() bool {
+	return r.available.Load()
+}
+
+// Ready reports whether Gophkeeper can serve requests, that is
+// the database is reachable and the blobs directory is writable.
+func (r *Gophkeeper) Ready(_ context.Context) error {
+	if _, err := r.database(); err != nil {
+		return err
+	}
+	var probe, probeError = os.CreateTemp(filepath.Join(r.BlobsDir, blobsStagingDir), "ready-*")
+	if probeError != nil {
+		return fmt.Errorf("blobs directory is not writable: %w", probeError)
+	}
+	if err := probe.Close(); err != nil {
+		return err
+	}
+	return os.Remove(probe.Name())
+}
+
+func (r *Gophkeeper) database() (*pgxpool.Pool, error) {
+	var pool, ok = r.pool.Peek()
+	if !ok || !r.available.Load() {
+		return nil, gophkeeper.ErrUnavailable
 	}
-	defer connection.Close(context.Background())
+	return pool, nil
+}
 
-	_, initializeError := connection.Exec(ctx, initQuery)
-	if initializeError != nil {
-		return initializeError
+// waitDatabase pings the database with exponential backoff until it responds.
+func (r *Gophkeeper) waitDatabase(ctx context.Context, pool *pgxpool.Pool) error {
+	var backoff = minReconnectBackoff
+	for {
+		var err = ping(ctx, pool)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("database is unavailable", "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxReconnectBackoff)
 	}
+}
+
+func ping(ctx context.Context, pool *pgxpool.Pool) error {
+	var pingCtx, cancel = context.WithTimeout(ctx, pingInterval)
+	defer cancel()
+	return pool.Ping(pingCtx)
+}
 
-	r.connection.Set(connection)
+func registerPoolMetrics(pool *pgxpool.Pool) {
+	var stats = []struct {
+		name    string
+		help    string
+		counter bool
+		value   func(*pgxpool.Stat) float64
+	}{
+		{
+			name:  "gophkeeper_postgres_acquired_connections",
+			help:  "Number of currently acquired database connections.",
+			value: func(s *pgxpool.Stat) float64 { return (float64)(s.AcquiredConns()) },
+		},
+		{
+			name:  "gophkeeper_postgres_idle_connections",
+			help:  "Number of currently idle database connections.",
+			value: func(s *pgxpool.Stat) float64 { return (float64)(s.IdleConns()) },
+		},
+		{
+			name:  "gophkeeper_postgres_total_connections",
+			help:  "Total number of database connections in the pool.",
+			value: func(s *pgxpool.Stat) float64 { return (float64)(s.TotalConns()) },
+		},
+		{
+			name:  "gophkeeper_postgres_max_connections",
+			help:  "Maximum size of the database connection pool.",
+			value: func(s *pgxpool.Stat) float64 { return (float64)(s.MaxConns()) },
+		},
+		{
+			name:    "gophkeeper_postgres_acquires_total",
+			help:    "Cumulative number of successful database connection acquires.",
+			counter: true,
+			value:   func(s *pgxpool.Stat) float64 { return (float64)(s.AcquireCount()) },
+		},
+		{
+			name:    "gophkeeper_postgres_acquire_duration_seconds_total",
+			help:    "Cumulative time spent acquiring database connections in seconds.",
+			counter: true,
+			value:   func(s *pgxpool.Stat) float64 { return s.AcquireDuration().Seconds() },
+		},
+		{
+			name:    "gophkeeper_postgres_empty_acquires_total",
+			help:    "Cumulative number of acquires that waited for a connection.",
+			counter: true,
+			value:   func(s *pgxpool.Stat) float64 { return (float64)(s.EmptyAcquireCount()) },
+		},
+	}
+	for _, stat := range stats {
+		var value = stat.value
+		var read = func() float64 {
+			return value(pool.Stat())
+		}
+		if stat.counter {
+			metrics.Default.CounterFunc(stat.name, stat.help, read)
+		} else {
+			metrics.Default.GaugeFunc(stat.name, stat.help, read)
+		}
+	}
+}
 
-	<-ctx.Done()
-	return ctx.Err()
+// Connect creates a connection pool to the database.
+//
+// Zero maxConnections means pgxpool's default,
+// zero statementCacheCapacity disables statement caching.
+func Connect(ctx context.Context, dsn string, maxConnections int32, statementCacheCapacity int) (*pgxpool.Pool, error) {
+	var config, configError = pgxpool.ParseConfig(dsn)
+	if configError != nil {
+		return nil, configError
+	}
+	if maxConnections > 0 {
+		config.MaxConns = maxConnections
+	}
+	if statementCacheCapacity > 0 {
+		config.ConnConfig.StatementCacheCapacity = statementCacheCapacity
+		config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	} else {
+		config.ConnConfig.StatementCacheCapacity = 0
+		config.ConnConfig.DescriptionCacheCapacity = 0
+		config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+	config.ConnConfig.OnNotice = func(_ *pgconn.PgConn, notice *pgconn.Notice) {
+		slog.Info("postgres notice", "severity", notice.Severity, "message", notice.Message)
+	}
+	return pgxpool.NewWithConfig(ctx, config)
 }
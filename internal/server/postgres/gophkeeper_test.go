@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenIssuedAt(t *testing.T) {
+	t.Run("Keeps microseconds of the issue time", func(t *testing.T) {
+		var now = time.Date(2026, time.October, 19, 12, 0, 0, 123456000, time.UTC)
+		var claims = jwt.MapClaims{"iat": (float64)(now.UnixMicro()) / 1e6}
+		assert.True(t, now.Equal(tokenIssuedAt(claims)))
+	})
+	t.Run("Token without issue time", func(t *testing.T) {
+		assert.True(t, tokenIssuedAt(jwt.MapClaims{}).IsZero())
+	})
+}
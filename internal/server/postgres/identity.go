@@ -1,7 +1,6 @@
 package postgres
 
 import (
-	"bufio"
 	"context"
 	"crypto/aes"
 	"crypto/cipher"
@@ -9,29 +8,40 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
-	"log"
+	"io"
+	"log/slog"
 	"os"
-	"path"
+	"path/filepath"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	composedreadcloser "github.com/kerelape/gophkeeper/internal/composed_read_closer"
+	"github.com/kerelape/gophkeeper/internal/metrics"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
-	keyLen  = 32
-	keyIter = 4096
+	keyLen = 32
+	// DefaultKeyIterations is the default number of PBKDF2 iterations.
+	DefaultKeyIterations = 4096
 )
 
 // Identity is a postgres identity.
 type Identity struct {
-	Connection       *pgx.Conn
+	Pool             *pgxpool.Pool
 	PasswordEncoding *base64.Encoding
 	BlobsDir         string
+	// KeyIterations is the number of PBKDF2 iterations for new resources,
+	// existing resources keep the number they were stored with.
+	KeyIterations int
+
+	Invites        bool // Invites tells whether the identity may create invites.
+	InviteLifespan time.Duration
+
+	Admin bool // Admin tells whether the identity has the admin role.
 
 	Username string
 }
@@ -55,7 +65,7 @@ func (i *Identity) StorePiece(ctx context.Context, piece gophkeeper.Piece, passw
 	if _, err := rand.Read(iv); err != nil {
 		return -1, err
 	}
-	key = pbkdf2.Key(([]byte)(password), salt, keyIter, keyLen, sha256.New)
+	key = deriveKey(password, salt, i.keyIterations())
 	var block, blockError = aes.NewCipher(key)
 	if blockError != nil {
 		return -1, blockError
@@ -66,33 +76,33 @@ func (i *Identity) StorePiece(ctx context.Context, piece gophkeeper.Piece, passw
 	}
 	var content = aesgcm.Seal(nil, iv, piece.Content, nil)
 
-	var transaction, transactionError = i.Connection.Begin(ctx)
+	var transaction, transactionError = i.Pool.Begin(ctx)
 	if transactionError != nil {
 		return -1, transactionError
 	}
 	defer transaction.Rollback(context.Background())
 
-	insertPieceResult := transaction.QueryRow(
-		ctx,
-		`INSERT INTO pieces(content, salt, iv) VALUES($1, $2, $3) RETURNING id`,
-		content, salt, iv,
-	)
-	var id int
-	if err := insertPieceResult.Scan(&id); err != nil {
-		return -1, err
-	}
 	insertResourceResult := transaction.QueryRow(
 		ctx,
-		`INSERT INTO resources(meta, resource, type, owner) VALUES($1, $2, $3, $4) RETURNING id`,
-		piece.Meta, id, (int)(gophkeeper.ResourceTypePiece), i.Username,
+		`INSERT INTO resources(meta, type, owner) VALUES($1, $2, $3) RETURNING id`,
+		piece.Meta, (int)(gophkeeper.ResourceTypePiece), i.Username,
 	)
 	var rid int64
 	if err := insertResourceResult.Scan(&rid); err != nil {
 		return -1, err
 	}
+	_, insertPieceError := transaction.Exec(
+		ctx,
+		`INSERT INTO pieces(resource, content, salt, iv, iterations) VALUES($1, $2, $3, $4, $5)`,
+		rid, content, salt, iv, i.keyIterations(),
+	)
+	if insertPieceError != nil {
+		return -1, insertPieceError
+	}
 	if err := transaction.Commit(ctx); err != nil {
 		return -1, err
 	}
+	metrics.BytesStored.Add((float64)(len(piece.Content)), "piece")
 
 	return (gophkeeper.ResourceID)(rid), nil
 }
@@ -104,34 +114,28 @@ func (i *Identity) RestorePiece(ctx context.Context, rid gophkeeper.ResourceID,
 	}
 
 	var (
-		meta    string
-		content []byte
-		iv      []byte
-		salt    []byte
+		meta       string
+		content    []byte
+		iv         []byte
+		salt       []byte
+		iterations int
 	)
 
-	var queryResourceResult = i.Connection.QueryRow(
+	var queryPieceResult = i.Pool.QueryRow(
 		ctx,
-		`SELECT meta, resource FROM resources WHERE id = $1 AND owner = $2 AND type = $3`,
+		`SELECT resources.meta, pieces.content, pieces.iv, pieces.salt, pieces.iterations
+			FROM resources JOIN pieces ON pieces.resource = resources.id
+			WHERE resources.id = $1 AND resources.owner = $2 AND resources.type = $3`,
 		(int64)(rid), i.Username, (int)(gophkeeper.ResourceTypePiece),
 	)
-	var id int
-	if err := queryResourceResult.Scan(&meta, &id); err != nil {
+	if err := queryPieceResult.Scan(&meta, &content, &iv, &salt, &iterations); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return gophkeeper.Piece{}, gophkeeper.ErrResourceNotFound
 		}
 		return gophkeeper.Piece{}, err
 	}
-	var queryPieceResult = i.Connection.QueryRow(
-		ctx,
-		`SELECT content, iv, salt FROM pieces WHERE id = $1`,
-		id,
-	)
-	if err := queryPieceResult.Scan(&content, &iv, &salt); err != nil {
-		return gophkeeper.Piece{}, err
-	}
 
-	var key = pbkdf2.Key(([]byte)(password), salt, keyIter, keyLen, sha256.New)
+	var key = deriveKey(password, salt, iterations)
 	var block, blockError = aes.NewCipher(key)
 	if blockError != nil {
 		return gophkeeper.Piece{}, blockError
@@ -142,9 +146,10 @@ func (i *Identity) RestorePiece(ctx context.Context, rid gophkeeper.ResourceID,
 	}
 	var decryptedContent, openError = aesgcm.Open(nil, iv, content, nil)
 	if openError != nil {
-		return gophkeeper.Piece{}, openError
+		return gophkeeper.Piece{}, errors.Join(gophkeeper.ErrResourceCorrupted, openError)
 	}
 
+	metrics.BytesRestored.Add((float64)(len(decryptedContent)), "piece")
 	var piece = gophkeeper.Piece{
 		Meta:    meta,
 		Content: decryptedContent,
@@ -155,6 +160,8 @@ func (i *Identity) RestorePiece(ctx context.Context, rid gophkeeper.ResourceID,
 // StoreBlob implements Identity.
 func (i *Identity) StoreBlob(ctx context.Context, blob gophkeeper.Blob, password string) (gophkeeper.ResourceID, error) {
 	defer blob.Content.Close()
+	metrics.ActiveUploads.Add(1)
+	defer metrics.ActiveUploads.Add(-1)
 	if err := i.comparePassword(ctx, password); err != nil {
 		return -1, errors.Join(err, gophkeeper.ErrBadCredential)
 	}
@@ -164,8 +171,9 @@ func (i *Identity) StoreBlob(ctx context.Context, blob gophkeeper.Blob, password
 		return -1, err
 	}
 
+	var iterations = i.keyIterations()
 	var block, blockError = aes.NewCipher(
-		pbkdf2.Key(([]byte)(password), salt, keyIter, keyLen, sha256.New),
+		deriveKey(password, salt, iterations),
 	)
 	if blockError != nil {
 		return -1, blockError
@@ -176,66 +184,55 @@ func (i *Identity) StoreBlob(ctx context.Context, blob gophkeeper.Blob, password
 		return -1, err
 	}
 
-	var location = path.Join(i.BlobsDir, uuid.New().String())
-	var file, createError = os.Create(location)
-	if createError != nil {
-		return -1, createError
+	var stored int
+	var content = &meteredReader{
+		reader: blob.Content,
+		read:   func(n int) { stored += n },
 	}
-
-	var (
-		writer = cipher.StreamWriter{
-			S: cipher.NewCTR(block, iv),
-			W: file,
-		}
-		reader = bufio.NewReader(blob.Content)
-	)
-	if _, err := reader.WriteTo(writer); err != nil {
-		log.Printf("failed to write file: %s\n", err.Error())
-		if err := file.Close(); err != nil {
-			log.Printf("failed to close file: %s\n", err.Error())
-		}
-		if err := os.Remove(location); err != nil {
-			log.Printf("failed to remove file: %s\n", err.Error())
-		}
-		return -1, err
-	}
-	if err := file.Close(); err != nil {
-		log.Printf("failed to close file: %s\n", err.Error())
-		return -1, err
+	var staged, checksum, stageError = stageBlob(i.BlobsDir, content, cipher.NewCTR(block, iv))
+	if stageError != nil {
+		return -1, stageError
 	}
+	var location = filepath.Join(i.BlobsDir, filepath.Base(staged))
 
-	var transaction, transactionError = i.Connection.Begin(ctx)
+	var transaction, transactionError = i.Pool.Begin(ctx)
 	if transactionError != nil {
+		removeBlobFile(staged)
 		return -1, transactionError
 	}
 	defer transaction.Rollback(context.Background())
 
-	var (
-		blobID int
-		rid    int64
-	)
-
-	var insertBlobResult = transaction.QueryRow(
+	var insertResourceResult = transaction.QueryRow(
 		ctx,
-		`INSERT INTO blobs(location, iv, salt) VALUES($1, $2, $3) RETURNING id`,
-		location, iv, salt,
+		`INSERT INTO resources(meta, owner, type) VALUES($1, $2, $3) RETURNING id`,
+		blob.Meta, i.Username, (int)(gophkeeper.ResourceTypeBlob),
 	)
-	if err := insertBlobResult.Scan(&blobID); err != nil {
+	var rid int64
+	if err := insertResourceResult.Scan(&rid); err != nil {
+		removeBlobFile(staged)
 		return -1, err
 	}
 
-	var insertResourceResult = transaction.QueryRow(
+	_, insertBlobError := transaction.Exec(
 		ctx,
-		`INSERT INTO resources(meta, owner, type, resource) VALUES($1, $2, $3, $4) RETURNING id`,
-		blob.Meta, i.Username, gophkeeper.ResourceTypeBlob, blobID,
+		`INSERT INTO blobs(resource, location, iv, salt, checksum, iterations, size) VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		rid, location, iv, salt, checksum, iterations, stored,
 	)
-	if err := insertResourceResult.Scan(&rid); err != nil {
-		return -1, err
+	if insertBlobError != nil {
+		removeBlobFile(staged)
+		return -1, insertBlobError
 	}
 
+	// The file is published before the commit, so a committed row always
+	// has a complete file. If the commit fails the published file is left
+	// unreferenced and is collected by the blob collector.
+	if err := publishBlob(staged, location); err != nil {
+		return -1, err
+	}
 	if err := transaction.Commit(ctx); err != nil {
 		return -1, err
 	}
+	metrics.BytesStored.Add((float64)(stored), "blob")
 
 	return (gophkeeper.ResourceID)(rid), nil
 }
@@ -247,29 +244,33 @@ func (i *Identity) RestoreBlob(ctx context.Context, rid gophkeeper.ResourceID, p
 	}
 
 	var (
-		iv       []byte
-		salt     []byte
-		location string
-		meta     string
+		iv         []byte
+		salt       []byte
+		location   string
+		meta       string
+		checksum   []byte
+		iterations int
 	)
 
-	var selectResourceResult = i.Connection.QueryRow(
+	var selectBlobResult = i.Pool.QueryRow(
 		ctx,
-		`SELECT meta, resource FROM resources WHERE id = $1 AND owner = $2`,
-		(int64)(rid), i.Username,
+		`SELECT resources.meta, blobs.location, blobs.iv, blobs.salt, blobs.checksum, blobs.iterations
+			FROM resources JOIN blobs ON blobs.resource = resources.id
+			WHERE resources.id = $1 AND resources.owner = $2 AND resources.type = $3`,
+		(int64)(rid), i.Username, (int)(gophkeeper.ResourceTypeBlob),
 	)
-	var blobID int
-	if err := selectResourceResult.Scan(&meta, &blobID); err != nil {
+	if err := selectBlobResult.Scan(&meta, &location, &iv, &salt, &checksum, &iterations); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return gophkeeper.Blob{}, gophkeeper.ErrResourceNotFound
+		}
 		return gophkeeper.Blob{}, err
 	}
 
-	var selectBlobResult = i.Connection.QueryRow(
-		ctx,
-		`SELECT location, iv, salt FROM blobs WHERE id = $1`,
-		blobID,
+	var block, blockError = aes.NewCipher(
+		deriveKey(password, salt, iterations),
 	)
-	if err := selectBlobResult.Scan(&location, &iv, &salt); err != nil {
-		return gophkeeper.Blob{}, err
+	if blockError != nil {
+		return gophkeeper.Blob{}, blockError
 	}
 
 	var file, fileError = os.Open(location)
@@ -277,19 +278,21 @@ func (i *Identity) RestoreBlob(ctx context.Context, rid gophkeeper.ResourceID, p
 		return gophkeeper.Blob{}, fileError
 	}
 
-	var block, blockError = aes.NewCipher(
-		pbkdf2.Key(([]byte)(password), salt, keyIter, keyLen, sha256.New),
-	)
-	if blockError != nil {
-		return gophkeeper.Blob{}, blockError
+	var encrypted io.Reader = file
+	if checksum != nil {
+		encrypted = newChecksumReader(file, checksum)
 	}
-
 	var blob = gophkeeper.Blob{
 		Meta: meta,
 		Content: &composedreadcloser.ComposedReadCloser{
-			Reader: cipher.StreamReader{
-				S: cipher.NewCTR(block, iv),
-				R: file,
+			Reader: &meteredReader{
+				reader: cipher.StreamReader{
+					S: cipher.NewCTR(block, iv),
+					R: encrypted,
+				},
+				read: func(n int) {
+					metrics.BytesRestored.Add((float64)(n), "blob")
+				},
 			},
 			Closer: file,
 		},
@@ -299,99 +302,158 @@ func (i *Identity) RestoreBlob(ctx context.Context, rid gophkeeper.ResourceID, p
 
 // Delete implements Identity.
 func (i *Identity) Delete(ctx context.Context, rid gophkeeper.ResourceID) error {
-	var transaction, transactionError = i.Connection.Begin(ctx)
+	var transaction, transactionError = i.Pool.Begin(ctx)
 	if transactionError != nil {
 		return transactionError
 	}
 	defer transaction.Rollback(context.Background())
 
-	var deleteResourceResult = transaction.QueryRow(
+	var selectResourceResult = transaction.QueryRow(
 		ctx,
-		`DELETE FROM resources WHERE id = $1 AND owner = $2 RETURNING type, resource`,
+		`SELECT blobs.location
+			FROM resources LEFT JOIN blobs ON blobs.resource = resources.id
+			WHERE resources.id = $1 AND resources.owner = $2
+			FOR UPDATE OF resources`,
 		(int64)(rid), i.Username,
 	)
-	var (
-		resourceType int
-		resourceID   int
-	)
-	if err := deleteResourceResult.Scan(&resourceType, &resourceID); err != nil {
+	var location *string
+	if err := selectResourceResult.Scan(&location); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return gophkeeper.ErrResourceNotFound
 		}
 		return err
 	}
 
-	switch (gophkeeper.ResourceType)(resourceType) {
-	case gophkeeper.ResourceTypePiece:
-		_, err := transaction.Exec(
-			ctx,
-			`DELETE FROM pieces WHERE id = $1`,
-			resourceID,
-		)
-		if err != nil {
-			return err
-		}
-	case gophkeeper.ResourceTypeBlob:
-		var deleteResult = transaction.QueryRow(
-			ctx,
-			`DELETE FROM blobs WHERE id = $1 RETURNING location`,
-			resourceID,
-		)
-		var location string
-		if err := deleteResult.Scan(&location); err != nil {
-			return err
-		}
-		if err := os.Remove(location); err != nil {
-			return err
-		}
-	default:
-		log.Fatalf("unknown resource type: %d", resourceType)
+	_, deleteError := transaction.Exec(
+		ctx,
+		`DELETE FROM resources WHERE id = $1`,
+		(int64)(rid),
+	)
+	if deleteError != nil {
+		return deleteError
 	}
 
 	if err := transaction.Commit(ctx); err != nil {
 		return err
 	}
+	// The file is removed once the row is gone, a file left behind is collected.
+	if location != nil {
+		removeBlobFile(*location)
+	}
 	return nil
 }
 
 // List implements Identity.
 func (i *Identity) List(ctx context.Context) ([]gophkeeper.Resource, error) {
-	var selectResourcesResult, selectResourcesResultError = i.Connection.Query(
+	var selectResourcesResult, selectResourcesResultError = i.Pool.Query(
 		ctx,
 		`SELECT id, type, meta FROM resources WHERE owner = $1`,
 		i.Username,
 	)
 	if selectResourcesResultError != nil {
-		log.Fatal(selectResourcesResultError)
 		return nil, selectResourcesResultError
 	}
 	defer selectResourcesResult.Close()
 	var resources []gophkeeper.Resource
 	for selectResourcesResult.Next() {
-		if err := selectResourcesResult.Err(); err != nil {
-			log.Fatal(err)
-			return nil, err
-		}
 		var resource gophkeeper.Resource
 		if err := selectResourcesResult.Scan(&resource.ID, &resource.Type, &resource.Meta); err != nil {
-			log.Fatal(err)
 			return nil, err
 		}
 		resources = append(resources, resource)
 	}
+	if err := selectResourcesResult.Err(); err != nil {
+		return nil, err
+	}
 	return resources, nil
 }
 
+// DeleteIdentity implements Identity.
+func (i *Identity) DeleteIdentity(ctx context.Context, password string) error {
+	if err := i.comparePassword(ctx, password); err != nil {
+		return errors.Join(err, gophkeeper.ErrBadCredential)
+	}
+	var blobs, deleteError = deleteIdentity(ctx, i.Pool, i.Username)
+	if deleteError != nil {
+		if errors.Is(deleteError, gophkeeper.ErrIdentityNotFound) {
+			return gophkeeper.ErrBadCredential
+		}
+		return deleteError
+	}
+	slog.Info("identity deleted itself", "identity", i.Username, "blobs", blobs)
+	return nil
+}
+
+// Invite implements Identity.
+func (i *Identity) Invite(ctx context.Context) (gophkeeper.Invite, error) {
+	if !i.Invites {
+		return gophkeeper.Invite{}, gophkeeper.ErrForbidden
+	}
+	var invite, inviteError = createInvite(ctx, i.Pool, i.Username, i.InviteLifespan)
+	if inviteError != nil {
+		return gophkeeper.Invite{}, inviteError
+	}
+	slog.Info("invite created", "identity", i.Username, "expires_at", invite.ExpiresAt)
+	return invite, nil
+}
+
+// Profile implements Identity.
+func (i *Identity) Profile(ctx context.Context) (gophkeeper.Profile, error) {
+	var (
+		profile = gophkeeper.Profile{
+			Security: gophkeeper.Security{
+				Admin:         i.Admin,
+				Invites:       i.Invites,
+				KeyIterations: i.keyIterations(),
+			},
+		}
+		lastLogin     *time.Time
+		pieces, blobs int
+	)
+	var row = i.Pool.QueryRow(
+		ctx,
+		`SELECT identities.username, identities.created_at, identities.last_login,
+				COUNT(pieces.id), COUNT(blobs.id),
+				COALESCE(SUM(octet_length(pieces.content)), 0),
+				COALESCE(SUM(blobs.size), 0)
+			FROM identities
+			LEFT JOIN resources ON resources.owner = identities.username
+			LEFT JOIN pieces ON pieces.resource = resources.id
+			LEFT JOIN blobs ON blobs.resource = resources.id
+			WHERE identities.username = $1
+			GROUP BY identities.username`,
+		i.Username,
+	)
+	var scanError = row.Scan(
+		&profile.Username, &profile.CreatedAt, &lastLogin,
+		&pieces, &blobs,
+		&profile.PieceBytes, &profile.BlobBytes,
+	)
+	if errors.Is(scanError, pgx.ErrNoRows) {
+		return gophkeeper.Profile{}, gophkeeper.ErrBadCredential
+	}
+	if scanError != nil {
+		return gophkeeper.Profile{}, scanError
+	}
+	if lastLogin != nil {
+		profile.LastLogin = *lastLogin
+	}
+	profile.Resources = map[gophkeeper.ResourceType]int{
+		gophkeeper.ResourceTypePiece: pieces,
+		gophkeeper.ResourceTypeBlob:  blobs,
+	}
+	return profile, nil
+}
+
 func (i *Identity) comparePassword(ctx context.Context, password string) error {
-	var row = i.Connection.QueryRow(
+	var row = i.Pool.QueryRow(
 		ctx,
 		`SELECT password FROM identities WHERE username = $1`,
 		i.Username,
 	)
 	var encodedPassword string
 	if err := row.Scan(&encodedPassword); err != nil {
-		var pgerr pgconn.PgError
-		if errors.As(err, (any)(&pgerr)) {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return gophkeeper.ErrBadCredential
 		}
 		return err
@@ -401,8 +463,25 @@ func (i *Identity) comparePassword(ctx context.Context, password string) error {
 	if decodePasswordError != nil {
 		return decodePasswordError
 	}
-	if err := bcrypt.CompareHashAndPassword(decodedPassword, ([]byte)(password)); err != nil {
-		return errors.Join(gophkeeper.ErrBadCredential, err)
+	var start = time.Now()
+	var compareError = bcrypt.CompareHashAndPassword(decodedPassword, ([]byte)(password))
+	metrics.KeyDerivationDuration.Observe(time.Since(start).Seconds(), "bcrypt")
+	if compareError != nil {
+		return errors.Join(gophkeeper.ErrBadCredential, compareError)
 	}
 	return nil
 }
+
+func (i *Identity) keyIterations() int {
+	if i.KeyIterations <= 0 {
+		return DefaultKeyIterations
+	}
+	return i.KeyIterations
+}
+
+func deriveKey(password string, salt []byte, iterations int) []byte {
+	var start = time.Now()
+	var key = pbkdf2.Key(([]byte)(password), salt, iterations, keyLen, sha256.New)
+	metrics.KeyDerivationDuration.Observe(time.Since(start).Seconds(), "pbkdf2")
+	return key
+}
@@ -0,0 +1,202 @@
+package postgres
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLock is the advisory lock key held while migrating,
+// so that concurrent server instances don't migrate twice.
+const migrationLock = 0x6F706B65 // "gopk"
+
+// Migration is a versioned schema up-migration.
+type Migration struct {
+	Version int
+	Name    string
+	Query   string
+}
+
+// MigrationStatus is a migration with its state in the database.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrations returns all known migrations ordered by version.
+//
+// Migrations are embedded from migrations/NNNN_name.sql files.
+func Migrations() ([]Migration, error) {
+	var entries, entriesError = fs.ReadDir(migrationsFS, "migrations")
+	if entriesError != nil {
+		return nil, entriesError
+	}
+	var migrations = make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		var version, name, ok = strings.Cut(strings.TrimSuffix(entry.Name(), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration name: %s", entry.Name())
+		}
+		var number, numberError = strconv.Atoi(version)
+		if numberError != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", entry.Name())
+		}
+		var query, queryError = migrationsFS.ReadFile(path.Join("migrations", entry.Name()))
+		if queryError != nil {
+			return nil, queryError
+		}
+		migrations = append(
+			migrations,
+			Migration{
+				Version: number,
+				Name:    name,
+				Query:   (string)(query),
+			},
+		)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrate applies all pending migrations and returns them.
+//
+// With dryRun the migrations are executed and then rolled back,
+// so the returned list is what would have been applied.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, dryRun bool) ([]Migration, error) {
+	var migrations, migrationsError = Migrations()
+	if migrationsError != nil {
+		return nil, migrationsError
+	}
+
+	var transaction, transactionError = pool.Begin(ctx)
+	if transactionError != nil {
+		return nil, transactionError
+	}
+	defer transaction.Rollback(context.Background())
+
+	var version, versionError = lockSchemaVersion(ctx, transaction)
+	if versionError != nil {
+		return nil, versionError
+	}
+	if version > len(migrations) {
+		return nil, fmt.Errorf("database schema version %d is newer than %d", version, len(migrations))
+	}
+
+	var pending = migrations[version:]
+	for _, migration := range pending {
+		if _, err := transaction.Exec(ctx, migration.Query); err != nil {
+			return nil, fmt.Errorf("apply migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		_, insertError := transaction.Exec(
+			ctx,
+			`INSERT INTO schema_version(version, name) VALUES($1, $2)`,
+			migration.Version, migration.Name,
+		)
+		if insertError != nil {
+			return nil, insertError
+		}
+	}
+
+	if dryRun {
+		return pending, nil
+	}
+	if err := transaction.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// MigrationsStatus returns all known migrations with their state.
+func MigrationsStatus(ctx context.Context, pool *pgxpool.Pool) ([]MigrationStatus, error) {
+	var migrations, migrationsError = Migrations()
+	if migrationsError != nil {
+		return nil, migrationsError
+	}
+
+	var transaction, transactionError = pool.Begin(ctx)
+	if transactionError != nil {
+		return nil, transactionError
+	}
+	defer transaction.Rollback(context.Background())
+
+	if _, err := lockSchemaVersion(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	var rows, rowsError = transaction.Query(ctx, `SELECT version, applied_at FROM schema_version`)
+	if rowsError != nil {
+		return nil, rowsError
+	}
+	defer rows.Close()
+	var applied = make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var statuses = make([]MigrationStatus, 0, len(migrations))
+	for _, migration := range migrations {
+		var appliedAt, ok = applied[migration.Version]
+		statuses = append(
+			statuses,
+			MigrationStatus{
+				Migration: migration,
+				Applied:   ok,
+				AppliedAt: appliedAt,
+			},
+		)
+	}
+	return statuses, nil
+}
+
+func lockSchemaVersion(ctx context.Context, transaction pgx.Tx) (int, error) {
+	if _, err := transaction.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLock); err != nil {
+		return -1, err
+	}
+	_, createError := transaction.Exec(
+		ctx,
+		`CREATE TABLE IF NOT EXISTS schema_version(
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`,
+	)
+	if createError != nil {
+		return -1, createError
+	}
+	var version int
+	var row = transaction.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`)
+	if err := row.Scan(&version); err != nil {
+		return -1, err
+	}
+	return version, nil
+}
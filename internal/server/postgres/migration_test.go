@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	t.Run("Embedded migrations are numbered sequentially", func(t *testing.T) {
+		var migrations, err = Migrations()
+		assert.NoError(t, err, "expected embedded migrations to parse")
+		assert.NotEmpty(t, migrations, "expected at least one migration")
+		for i, migration := range migrations {
+			assert.Equal(t, i+1, migration.Version, "migration versions must be sequential")
+			assert.NotEmpty(t, migration.Name, "migration must have a name")
+			assert.NotEmpty(t, migration.Query, "migration must have a query")
+		}
+	})
+}
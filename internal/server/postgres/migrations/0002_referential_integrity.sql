@@ -0,0 +1,68 @@
+-- Pieces and blobs reference their resource row instead of
+-- resources.resource pointing into either table.
+ALTER TABLE pieces ADD COLUMN resource INTEGER;
+ALTER TABLE blobs ADD COLUMN resource INTEGER;
+
+UPDATE pieces SET resource = resources.id
+    FROM resources
+    WHERE resources.type = 1 AND resources.resource = pieces.id;
+UPDATE blobs SET resource = resources.id
+    FROM resources
+    WHERE resources.type = 2 AND resources.resource = blobs.id;
+
+-- Clean up existing inconsistencies, reporting what was removed.
+DO $$
+DECLARE
+    removed INTEGER;
+BEGIN
+    DELETE FROM resources
+        WHERE owner IS NULL
+        OR NOT EXISTS (SELECT 1 FROM identities WHERE identities.username = resources.owner);
+    GET DIAGNOSTICS removed = ROW_COUNT;
+    RAISE NOTICE 'removed % resources owned by missing identities', removed;
+
+    DELETE FROM resources WHERE type IS NULL OR type NOT IN (1, 2);
+    GET DIAGNOSTICS removed = ROW_COUNT;
+    RAISE NOTICE 'removed % resources of unknown type', removed;
+
+    DELETE FROM pieces
+        WHERE resource IS NULL
+        OR NOT EXISTS (SELECT 1 FROM resources WHERE resources.id = pieces.resource);
+    GET DIAGNOSTICS removed = ROW_COUNT;
+    RAISE NOTICE 'removed % orphaned pieces', removed;
+
+    DELETE FROM blobs
+        WHERE resource IS NULL
+        OR NOT EXISTS (SELECT 1 FROM resources WHERE resources.id = blobs.resource);
+    GET DIAGNOSTICS removed = ROW_COUNT;
+    RAISE NOTICE 'removed % orphaned blobs (their files are left for garbage collection)', removed;
+
+    DELETE FROM resources
+        WHERE (type = 1 AND NOT EXISTS (SELECT 1 FROM pieces WHERE pieces.resource = resources.id))
+        OR (type = 2 AND NOT EXISTS (SELECT 1 FROM blobs WHERE blobs.resource = resources.id));
+    GET DIAGNOSTICS removed = ROW_COUNT;
+    RAISE NOTICE 'removed % resources without content', removed;
+END
+$$;
+
+ALTER TABLE resources
+    DROP COLUMN resource,
+    ALTER COLUMN owner SET NOT NULL,
+    ALTER COLUMN type SET NOT NULL,
+    ADD CONSTRAINT resources_owner_fkey
+        FOREIGN KEY (owner) REFERENCES identities(username) ON DELETE CASCADE,
+    ADD CONSTRAINT resources_type_check CHECK (type IN (1, 2));
+
+ALTER TABLE pieces
+    ALTER COLUMN resource SET NOT NULL,
+    ADD CONSTRAINT pieces_resource_fkey
+        FOREIGN KEY (resource) REFERENCES resources(id) ON DELETE CASCADE,
+    ADD CONSTRAINT pieces_resource_key UNIQUE (resource);
+
+ALTER TABLE blobs
+    ALTER COLUMN resource SET NOT NULL,
+    ADD CONSTRAINT blobs_resource_fkey
+        FOREIGN KEY (resource) REFERENCES resources(id) ON DELETE CASCADE,
+    ADD CONSTRAINT blobs_resource_key UNIQUE (resource);
+
+CREATE INDEX resources_owner_type_idx ON resources(owner, type);
@@ -0,0 +1,2 @@
+-- SHA-256 of the encrypted blob file, NULL for blobs stored before it was recorded.
+ALTER TABLE blobs ADD COLUMN checksum BYTEA;
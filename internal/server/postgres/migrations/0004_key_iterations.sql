@@ -0,0 +1,7 @@
+-- Store the PBKDF2 iteration count of every encrypted resource,
+-- so that it can be changed without breaking existing resources.
+ALTER TABLE pieces ADD COLUMN iterations INTEGER NOT NULL DEFAULT 4096;
+ALTER TABLE pieces ALTER COLUMN iterations DROP DEFAULT;
+
+ALTER TABLE blobs ADD COLUMN iterations INTEGER NOT NULL DEFAULT 4096;
+ALTER TABLE blobs ALTER COLUMN iterations DROP DEFAULT;
@@ -0,0 +1,10 @@
+-- Administration of identities: roles, disabling, forced logout and usage.
+ALTER TABLE identities ADD COLUMN admin BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE identities ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT false;
+-- Tokens issued before tokens_not_before are rejected.
+ALTER TABLE identities ADD COLUMN tokens_not_before TIMESTAMPTZ;
+ALTER TABLE identities ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE identities ADD COLUMN last_login TIMESTAMPTZ;
+
+-- Size of the plain blob content, NULL for blobs stored before it was recorded.
+ALTER TABLE blobs ADD COLUMN size BIGINT;
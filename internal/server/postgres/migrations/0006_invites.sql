@@ -0,0 +1,11 @@
+-- Single-use invite tokens for invite-only registration.
+CREATE TABLE invites (
+    token_hash BYTEA PRIMARY KEY,
+    created_by TEXT NOT NULL REFERENCES identities(username) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_by TEXT,
+    used_at TIMESTAMPTZ
+);
+
+CREATE INDEX invites_created_by_idx ON invites(created_by);
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// RegistrationMode is how new identities are registered.
+type RegistrationMode string
+
+const (
+	// RegistrationOpen lets anyone register.
+	RegistrationOpen RegistrationMode = "open"
+
+	// RegistrationInvite requires an invite to register.
+	RegistrationInvite RegistrationMode = "invite"
+
+	// RegistrationClosed rejects all registrations.
+	RegistrationClosed RegistrationMode = "closed"
+)
+
+// DefaultInviteLifespan is how long an invite is valid by default.
+const DefaultInviteLifespan = 72 * time.Hour
+
+// ParseRegistrationMode parses RegistrationMode.
+func ParseRegistrationMode(mode string) (RegistrationMode, error) {
+	switch RegistrationMode(mode) {
+	case RegistrationOpen, RegistrationInvite, RegistrationClosed:
+		return RegistrationMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown registration mode: %s", mode)
+	}
+}
+
+// createInvite stores a new invite and returns it.
+func createInvite(ctx context.Context, pool *pgxpool.Pool, username string, lifespan time.Duration) (gophkeeper.Invite, error) {
+	var raw = make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return gophkeeper.Invite{}, err
+	}
+	var invite = gophkeeper.Invite{
+		Token:     base64.RawURLEncoding.EncodeToString(raw),
+		ExpiresAt: time.Now().Add(lifespan),
+	}
+	var _, insertError = pool.Exec(
+		ctx,
+		`INSERT INTO invites(token_hash, created_by, expires_at) VALUES($1, $2, $3)`,
+		inviteHash(invite.Token), username, invite.ExpiresAt,
+	)
+	if insertError != nil {
+		return gophkeeper.Invite{}, insertError
+	}
+	return invite, nil
+}
+
+// redeemInvite marks the invite used by the username,
+// ErrBadInvite if it is unknown, expired or already used.
+func redeemInvite(ctx context.Context, transaction pgx.Tx, token, username string) error {
+	if token == "" {
+		return gophkeeper.ErrBadInvite
+	}
+	var result, updateError = transaction.Exec(
+		ctx,
+		`UPDATE invites SET used_by = $2, used_at = now()
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()`,
+		inviteHash(token), username,
+	)
+	if updateError != nil {
+		return updateError
+	}
+	if result.RowsAffected() == 0 {
+		return gophkeeper.ErrBadInvite
+	}
+	return nil
+}
+
+// inviteHash returns the hash an invite token is stored as,
+// so that leaked database rows cannot be used to register.
+func inviteHash(token string) []byte {
+	var hash = sha256.Sum256(([]byte)(token))
+	return hash[:]
+}
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
+)
+
+// requestIDHeader is the header carrying the request ID,
+// it is accepted from clients and echoed in every response.
+const requestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// accessLog assigns every request an ID and logs it once it is served.
+//
+// Only the method, route, status, duration and username are logged,
+// headers such as X-Password never are.
+func accessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+		var id = in.Header.Get(requestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		out.Header().Set(requestIDHeader, id)
+
+		var (
+			ctx    = logging.WithRequest(in.Context(), id)
+			start  = time.Now()
+			writer = &statusWriter{ResponseWriter: out, status: http.StatusOK}
+		)
+		in = in.WithContext(ctx)
+		next.ServeHTTP(writer, in)
+		logging.Logger(ctx).Info(
+			"request",
+			"method", in.Method,
+			"route", routePattern(in),
+			"status", writer.status,
+			"duration", time.Since(start),
+			"username", logging.Username(ctx),
+		)
+	})
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/rest/monitoring"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/pior/runnable"
+)
+
+// Admin is gophkeeper's admin listener serving
+// health and metrics endpoints apart from the REST api.
+type Admin struct {
+	Address string // Address is the address that admin listener serves at.
+
+	ReadHeaderTimeout time.Duration
+	ShutdownTimeout   time.Duration
+
+	Gophkeeper gophkeeper.Gophkeeper
+}
+
+var _ runnable.Runnable = (*Admin)(nil)
+
+// Run implements runnable.Runnable for Admin.
+func (a *Admin) Run(ctx context.Context) error {
+	var (
+		monitoring = monitoring.Entry{
+			Gophkeeper: a.Gophkeeper,
+		}
+		router = chi.NewRouter()
+	)
+	router.Use(accessLog, instrument)
+	router.Mount("/", monitoring.Route())
+	var server = http.Server{
+		Addr:              a.Address,
+		Handler:           router,
+		ReadHeaderTimeout: a.ReadHeaderTimeout,
+	}
+	return serve(ctx, &server, nil, a.ShutdownTimeout, server.ListenAndServe)
+}
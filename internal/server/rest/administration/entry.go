@@ -0,0 +1,181 @@
+package administration
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// Entry is administration entry.
+type Entry struct {
+	Gophkeeper gophkeeper.Gophkeeper
+}
+
+// Route routes administration entry.
+func (e *Entry) Route() http.Handler {
+	var router = chi.NewRouter()
+	router.Get("/identities", e.identities)
+	router.Route("/identities/{username}", func(router chi.Router) {
+		router.Delete("/", e.delete)
+		router.Post("/disable", e.disable)
+		router.Post("/enable", e.enable)
+		router.Post("/logout", e.logout)
+		router.Put("/password", e.password)
+	})
+	return router
+}
+
+func (e *Entry) identities(out http.ResponseWriter, in *http.Request) {
+	var administration, ok = e.administration(out, in)
+	if !ok {
+		return
+	}
+	var identities, identitiesError = administration.Identities(in.Context())
+	if identitiesError != nil {
+		fail(out, in, "failed to list identities", identitiesError)
+		return
+	}
+
+	var response = make([](map[string]any), 0, len(identities))
+	for _, identity := range identities {
+		var lastLogin *time.Time
+		if !identity.LastLogin.IsZero() {
+			lastLogin = &identity.LastLogin
+		}
+		response = append(
+			response,
+			map[string]any{
+				"username":    identity.Username,
+				"admin":       identity.Admin,
+				"disabled":    identity.Disabled,
+				"created_at":  identity.CreatedAt,
+				"last_login":  lastLogin,
+				"pieces":      identity.Pieces,
+				"blobs":       identity.Blobs,
+				"piece_bytes": identity.PieceBytes,
+				"blob_bytes":  identity.BlobBytes,
+			},
+		)
+	}
+
+	out.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(out).Encode(&response); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
+
+func (e *Entry) disable(out http.ResponseWriter, in *http.Request) {
+	e.setDisabled(out, in, true)
+}
+
+func (e *Entry) enable(out http.ResponseWriter, in *http.Request) {
+	e.setDisabled(out, in, false)
+}
+
+func (e *Entry) setDisabled(out http.ResponseWriter, in *http.Request, disabled bool) {
+	var administration, ok = e.administration(out, in)
+	if !ok {
+		return
+	}
+	if err := administration.SetDisabled(in.Context(), chi.URLParam(in, "username"), disabled); err != nil {
+		var message = "failed to enable identity"
+		if disabled {
+			message = "failed to disable identity"
+		}
+		fail(out, in, message, err)
+		return
+	}
+	out.WriteHeader(http.StatusOK)
+}
+
+func (e *Entry) logout(out http.ResponseWriter, in *http.Request) {
+	var administration, ok = e.administration(out, in)
+	if !ok {
+		return
+	}
+	if err := administration.Logout(in.Context(), chi.URLParam(in, "username")); err != nil {
+		fail(out, in, "failed to log identity out", err)
+		return
+	}
+	out.WriteHeader(http.StatusOK)
+}
+
+func (e *Entry) delete(out http.ResponseWriter, in *http.Request) {
+	var administration, ok = e.administration(out, in)
+	if !ok {
+		return
+	}
+	if err := administration.Delete(in.Context(), chi.URLParam(in, "username")); err != nil {
+		fail(out, in, "failed to delete identity", err)
+		return
+	}
+	out.WriteHeader(http.StatusOK)
+}
+
+func (e *Entry) password(out http.ResponseWriter, in *http.Request) {
+	var administration, ok = e.administration(out, in)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(in.Body).Decode(&requestBody); err != nil || requestBody.Password == "" {
+		var status = http.StatusBadRequest
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+	if err := administration.ResetCredential(in.Context(), chi.URLParam(in, "username"), requestBody.Password); err != nil {
+		fail(out, in, "failed to reset credential", err)
+		return
+	}
+	out.WriteHeader(http.StatusOK)
+}
+
+// administration returns the administration of the request's token,
+// or responds with an error and returns false.
+func (e *Entry) administration(out http.ResponseWriter, in *http.Request) (gophkeeper.Administration, bool) {
+	var token = in.Header.Get("Authorization")
+	var administration, administrationError = e.Gophkeeper.Administration(in.Context(), (gophkeeper.Token)(token))
+	if administrationError != nil {
+		fail(out, in, "failed to get administration", administrationError)
+		return nil, false
+	}
+	return administration, true
+}
+
+// fail responds with the status of err.
+func fail(out http.ResponseWriter, in *http.Request, message string, err error) {
+	var policyError *gophkeeper.PasswordPolicyError
+	if errors.As(err, &policyError) {
+		out.Header().Set("Content-Type", "application/json")
+		out.WriteHeader(http.StatusUnprocessableEntity)
+		var content = map[string]any{"violations": policyError.Violations}
+		if err := json.NewEncoder(out).Encode(content); err != nil {
+			logging.Logger(in.Context()).Error("failed to write response", "error", err)
+		}
+		return
+	}
+	var status = http.StatusInternalServerError
+	if errors.Is(err, gophkeeper.ErrUnavailable) {
+		status = http.StatusServiceUnavailable
+	}
+	if errors.Is(err, gophkeeper.ErrBadCredential) {
+		status = http.StatusUnauthorized
+	}
+	if errors.Is(err, gophkeeper.ErrForbidden) {
+		status = http.StatusForbidden
+	}
+	if errors.Is(err, gophkeeper.ErrIdentityNotFound) {
+		status = http.StatusNotFound
+	}
+	if status == http.StatusInternalServerError {
+		logging.Logger(in.Context()).Error(message, "error", err)
+	}
+	http.Error(out, http.StatusText(status), status)
+}
@@ -0,0 +1,132 @@
+package administration
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGophkeeper struct {
+	gophkeeper.Gophkeeper
+	administration *fakeAdministration
+}
+
+func (g *fakeGophkeeper) Administration(_ context.Context, token gophkeeper.Token) (gophkeeper.Administration, error) {
+	switch token {
+	case "admin":
+		return g.administration, nil
+	case "user":
+		return nil, gophkeeper.ErrForbidden
+	default:
+		return nil, gophkeeper.ErrBadCredential
+	}
+}
+
+type fakeAdministration struct {
+	identities map[string]*gophkeeper.IdentityInfo
+	passwords  map[string]string
+	logouts    []string
+}
+
+func (a *fakeAdministration) Identities(context.Context) ([]gophkeeper.IdentityInfo, error) {
+	var identities []gophkeeper.IdentityInfo
+	for _, identity := range a.identities {
+		identities = append(identities, *identity)
+	}
+	return identities, nil
+}
+
+func (a *fakeAdministration) SetDisabled(_ context.Context, username string, disabled bool) error {
+	var identity, ok = a.identities[username]
+	if !ok {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	identity.Disabled = disabled
+	return nil
+}
+
+func (a *fakeAdministration) Logout(_ context.Context, username string) error {
+	if _, ok := a.identities[username]; !ok {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	a.logouts = append(a.logouts, username)
+	return nil
+}
+
+func (a *fakeAdministration) Delete(_ context.Context, username string) error {
+	if _, ok := a.identities[username]; !ok {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	delete(a.identities, username)
+	return nil
+}
+
+func (a *fakeAdministration) ResetCredential(_ context.Context, username, password string) error {
+	if _, ok := a.identities[username]; !ok {
+		return gophkeeper.ErrIdentityNotFound
+	}
+	a.passwords[username] = password
+	return nil
+}
+
+func TestEntry(t *testing.T) {
+	var (
+		created        = time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+		administration = &fakeAdministration{
+			identities: map[string]*gophkeeper.IdentityInfo{
+				"bob": {Username: "bob", CreatedAt: created, Pieces: 2, PieceBytes: 64},
+			},
+			passwords: map[string]string{},
+		}
+		entry = Entry{
+			Gophkeeper: &fakeGophkeeper{administration: administration},
+		}
+		router = chi.NewRouter()
+	)
+	router.Mount("/admin", entry.Route())
+	var server = httptest.NewServer(router)
+	defer server.Close()
+	var client = func(token gophkeeper.Token) gophkeeper.Administration {
+		return &gophkeeper.RestAdministration{
+			Client: *server.Client(),
+			Server: server.URL,
+			Token:  token,
+		}
+	}
+	var ctx = context.Background()
+
+	t.Run("Rejects non-admins", func(t *testing.T) {
+		var _, err = client("user").Identities(ctx)
+		assert.ErrorIs(t, err, gophkeeper.ErrForbidden)
+		_, err = client("bad").Identities(ctx)
+		assert.ErrorIs(t, err, gophkeeper.ErrBadCredential)
+	})
+	t.Run("Lists identities", func(t *testing.T) {
+		var identities, err = client("admin").Identities(ctx)
+		assert.Nil(t, err)
+		assert.Equal(
+			t,
+			[]gophkeeper.IdentityInfo{{Username: "bob", CreatedAt: created, Pieces: 2, PieceBytes: 64}},
+			identities,
+		)
+	})
+	t.Run("Manages identities", func(t *testing.T) {
+		var admin = client("admin")
+		assert.Nil(t, admin.SetDisabled(ctx, "bob", true))
+		assert.True(t, administration.identities["bob"].Disabled)
+		assert.Nil(t, admin.SetDisabled(ctx, "bob", false))
+		assert.False(t, administration.identities["bob"].Disabled)
+		assert.Nil(t, admin.Logout(ctx, "bob"))
+		assert.Equal(t, []string{"bob"}, administration.logouts)
+		assert.Nil(t, admin.ResetCredential(ctx, "bob", "new password"))
+		assert.Equal(t, "new password", administration.passwords["bob"])
+		assert.Nil(t, admin.Delete(ctx, "bob"))
+		assert.ErrorIs(t, admin.Delete(ctx, "bob"), gophkeeper.ErrIdentityNotFound)
+		assert.ErrorIs(t, admin.Logout(ctx, "nobody"), gophkeeper.ErrIdentityNotFound)
+	})
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryAfter is how long clients are asked to wait
+// before retrying while gophkeeper is unavailable.
+const retryAfter = 5 * time.Second
+
+// availability is implemented by gophkeepers that
+// can be temporarily unavailable.
+type availability interface {
+	Available() bool
+}
+
+// unavailable responds with 503 while the gophkeeper is unavailable
+// and adds Retry-After to every 503 response.
+func (e *Entry) unavailable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+		var writer = &retryAfterWriter{ResponseWriter: out}
+		if gophkeeper, ok := e.Gophkeeper.(availability); ok && !gophkeeper.Available() {
+			var status = http.StatusServiceUnavailable
+			http.Error(writer, http.StatusText(status), status)
+			return
+		}
+		next.ServeHTTP(writer, in)
+	})
+}
+
+type retryAfterWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *retryAfterWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", strconv.Itoa((int)(retryAfter.Seconds())))
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *retryAfterWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// drain tracks in-flight requests and rejects new ones once draining.
+type drain struct {
+	draining atomic.Bool
+	active   atomic.Int64
+}
+
+// middleware counts in-flight requests and responds with 503 while draining.
+func (d *drain) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+		if d.draining.Load() {
+			out.Header().Set("Connection", "close")
+			var status = http.StatusServiceUnavailable
+			http.Error(out, http.StatusText(status), status)
+			return
+		}
+		d.active.Add(1)
+		defer d.active.Add(-1)
+		next.ServeHTTP(out, in)
+	})
+}
+
+// wait starts draining and waits until in-flight requests complete.
+func (d *drain) wait(ctx context.Context) {
+	d.draining.Store(true)
+	var ticker = time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for d.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// serve runs server with listen until ctx is done, then drains it
+// for at most shutdownTimeout and shuts it down.
+func serve(ctx context.Context, server *http.Server, d *drain, shutdownTimeout time.Duration, listen func() error) error {
+	var serverErrorChannel = make(chan error, 1)
+	go func() {
+		serverErrorChannel <- listen()
+	}()
+	select {
+	case <-ctx.Done():
+		var shutdownCtx, cancel = context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		server.SetKeepAlivesEnabled(false)
+		if d != nil {
+			d.wait(shutdownCtx)
+		}
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return errors.Join(err, server.Close())
+		}
+		return nil
+	case err := <-serverErrorChannel:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrain(t *testing.T) {
+	t.Run("Rejects new requests while draining", func(t *testing.T) {
+		var d drain
+		d.draining.Store(true)
+		var handler = d.middleware(http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+			t.Error("handler must not be called while draining")
+		}))
+		var recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+	t.Run("Waits for in-flight requests", func(t *testing.T) {
+		var (
+			d        drain
+			started  = make(chan struct{})
+			release  = make(chan struct{})
+			finished = make(chan struct{})
+		)
+		var handler = d.middleware(http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+			close(started)
+			<-release
+		}))
+		go func() {
+			defer close(finished)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+		<-started
+		var waited = make(chan struct{})
+		go func() {
+			defer close(waited)
+			d.wait(context.Background())
+		}()
+		select {
+		case <-waited:
+			t.Fatal("wait returned with a request in flight")
+		case <-time.After(100 * time.Millisecond):
+		}
+		close(release)
+		<-finished
+		select {
+		case <-waited:
+		case <-time.After(time.Second):
+			t.Fatal("wait did not return after the request completed")
+		}
+	})
+	t.Run("Gives up when context is done", func(t *testing.T) {
+		var d drain
+		d.active.Add(1)
+		var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		d.wait(ctx)
+		assert.True(t, d.draining.Load())
+	})
+}
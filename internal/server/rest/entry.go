@@ -2,9 +2,13 @@ package rest
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/rest/administration"
+	"github.com/kerelape/gophkeeper/internal/server/rest/identity"
 	"github.com/kerelape/gophkeeper/internal/server/rest/login"
+	"github.com/kerelape/gophkeeper/internal/server/rest/monitoring"
 	"github.com/kerelape/gophkeeper/internal/server/rest/register"
 	"github.com/kerelape/gophkeeper/internal/server/rest/vault"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -13,6 +17,11 @@ import (
 // Entry is the REST api entry.
 type Entry struct {
 	Gophkeeper gophkeeper.Gophkeeper
+	Monitoring bool // Monitoring enables health and metrics endpoints.
+
+	BlobTransferTimeout time.Duration // BlobTransferTimeout replaces read and write timeouts for blob routes.
+
+	drain *drain
 }
 
 // Route routes Entry into an http.Handler.
@@ -25,12 +34,34 @@ func (e *Entry) Route() http.Handler {
 			Gophkeeper: e.Gophkeeper,
 		}
 		vault = vault.Entry{
+			Gophkeeper:          e.Gophkeeper,
+			BlobTransferTimeout: e.BlobTransferTimeout,
+		}
+		monitoring = monitoring.Entry{
+			Gophkeeper: e.Gophkeeper,
+		}
+		administration = administration.Entry{
+			Gophkeeper: e.Gophkeeper,
+		}
+		identity = identity.Entry{
 			Gophkeeper: e.Gophkeeper,
 		}
 	)
 	var router = chi.NewRouter()
-	router.Mount("/register", register.Route())
-	router.Mount("/login", login.Route())
-	router.Mount("/vault", vault.Route())
+	router.Use(accessLog, instrument)
+	if e.drain != nil {
+		router.Use(e.drain.middleware)
+	}
+	if e.Monitoring {
+		router.Mount("/", monitoring.Route())
+	}
+	router.Group(func(router chi.Router) {
+		router.Use(e.unavailable)
+		router.Mount("/register", register.Route())
+		router.Mount("/login", login.Route())
+		router.Mount("/vault", vault.Route())
+		router.Mount("/identity", identity.Route())
+		router.Mount("/admin", administration.Route())
+	})
 	return router
 }
@@ -0,0 +1,172 @@
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// Entry is identity entry.
+type Entry struct {
+	Gophkeeper gophkeeper.Gophkeeper
+}
+
+// Route routes identity entry.
+func (e *Entry) Route() http.Handler {
+	var router = chi.NewRouter()
+	router.Get("/", e.profile)
+	router.Delete("/", e.delete)
+	router.Post("/invites", e.invite)
+	return router
+}
+
+func (e *Entry) profile(out http.ResponseWriter, in *http.Request) {
+	var token = in.Header.Get("Authorization")
+	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
+	if identityError != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
+			status = http.StatusUnauthorized
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+
+	var profile, profileError = identity.Profile(in.Context())
+	if profileError != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(profileError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(profileError, gophkeeper.ErrBadCredential) {
+			status = http.StatusUnauthorized
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get profile", "error", profileError)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+
+	var lastLogin *time.Time
+	if !profile.LastLogin.IsZero() {
+		lastLogin = &profile.LastLogin
+	}
+	var response = map[string]any{
+		"username":   profile.Username,
+		"created_at": profile.CreatedAt,
+		"last_login": lastLogin,
+		"resources": map[string]any{
+			"pieces": profile.Resources[gophkeeper.ResourceTypePiece],
+			"blobs":  profile.Resources[gophkeeper.ResourceTypeBlob],
+		},
+		"piece_bytes": profile.PieceBytes,
+		"blob_bytes":  profile.BlobBytes,
+		"security": map[string]any{
+			"admin":          profile.Security.Admin,
+			"invites":        profile.Security.Invites,
+			"key_iterations": profile.Security.KeyIterations,
+		},
+	}
+	out.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(out).Encode(&response); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
+
+func (e *Entry) delete(out http.ResponseWriter, in *http.Request) {
+	var token = in.Header.Get("Authorization")
+	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
+	if identityError != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
+			status = http.StatusUnauthorized
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+
+	var password = in.Header.Get("X-Password")
+	if password == "" {
+		var status = http.StatusUnauthorized
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+	if err := identity.DeleteIdentity(in.Context(), password); err != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(err, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(err, gophkeeper.ErrBadCredential) {
+			status = http.StatusUnauthorized
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to delete identity", "error", err)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+
+	out.WriteHeader(http.StatusOK)
+}
+
+func (e *Entry) invite(out http.ResponseWriter, in *http.Request) {
+	var token = in.Header.Get("Authorization")
+	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
+	if identityError != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
+			status = http.StatusUnauthorized
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+
+	var invite, inviteError = identity.Invite(in.Context())
+	if inviteError != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(inviteError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(inviteError, gophkeeper.ErrForbidden) {
+			status = http.StatusForbidden
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to create invite", "error", inviteError)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+
+	var response = map[string]any{
+		"token":      invite.Token,
+		"expires_at": invite.ExpiresAt,
+	}
+	out.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(out).Encode(&response); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package identity
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGophkeeper struct {
+	gophkeeper.Gophkeeper
+	identity *fakeIdentity
+}
+
+func (g *fakeGophkeeper) Identity(_ context.Context, token gophkeeper.Token) (gophkeeper.Identity, error) {
+	if token != "token" || g.identity.deleted {
+		return nil, gophkeeper.ErrBadCredential
+	}
+	return g.identity, nil
+}
+
+type fakeIdentity struct {
+	gophkeeper.Identity
+	password string
+	deleted  bool
+	profile  gophkeeper.Profile
+}
+
+func (i *fakeIdentity) Profile(context.Context) (gophkeeper.Profile, error) {
+	return i.profile, nil
+}
+
+func (i *fakeIdentity) DeleteIdentity(_ context.Context, password string) error {
+	if password != i.password {
+		return gophkeeper.ErrBadCredential
+	}
+	i.deleted = true
+	return nil
+}
+
+func TestDelete(t *testing.T) {
+	var (
+		identity = &fakeIdentity{password: "password"}
+		entry    = Entry{
+			Gophkeeper: &fakeGophkeeper{identity: identity},
+		}
+		router = chi.NewRouter()
+	)
+	router.Mount("/identity", entry.Route())
+	var server = httptest.NewServer(router)
+	defer server.Close()
+	var keeper = gophkeeper.RestGophkeeper{
+		Client: *server.Client(),
+		Server: server.URL,
+	}
+	var ctx = context.Background()
+
+	var client, _ = keeper.Identity(ctx, "token")
+	assert.ErrorIs(t, client.DeleteIdentity(ctx, ""), gophkeeper.ErrBadCredential)
+	assert.ErrorIs(t, client.DeleteIdentity(ctx, "wrong"), gophkeeper.ErrBadCredential)
+	assert.False(t, identity.deleted)
+
+	assert.Nil(t, client.DeleteIdentity(ctx, "password"))
+	assert.True(t, identity.deleted)
+	assert.ErrorIs(t, client.DeleteIdentity(ctx, "password"), gophkeeper.ErrBadCredential)
+}
+
+func TestProfile(t *testing.T) {
+	var (
+		identity = &fakeIdentity{
+			profile: gophkeeper.Profile{
+				Username:  "alice",
+				CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Resources: map[gophkeeper.ResourceType]int{
+					gophkeeper.ResourceTypePiece: 3,
+					gophkeeper.ResourceTypeBlob:  1,
+				},
+				PieceBytes: 120,
+				BlobBytes:  4096,
+				Security:   gophkeeper.Security{Invites: true, KeyIterations: 4096},
+			},
+		}
+		entry = Entry{
+			Gophkeeper: &fakeGophkeeper{identity: identity},
+		}
+		router = chi.NewRouter()
+	)
+	router.Mount("/identity", entry.Route())
+	var server = httptest.NewServer(router)
+	defer server.Close()
+	var keeper = gophkeeper.RestGophkeeper{
+		Client: *server.Client(),
+		Server: server.URL,
+	}
+	var ctx = context.Background()
+
+	var client, _ = keeper.Identity(ctx, "token")
+	var profile, profileError = client.Profile(ctx)
+	assert.Nil(t, profileError)
+	assert.Equal(t, identity.profile, profile)
+
+	var stranger, _ = keeper.Identity(ctx, "stranger")
+	var _, strangerError = stranger.Profile(ctx)
+	assert.ErrorIs(t, strangerError, gophkeeper.ErrBadCredential)
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/metrics"
+)
+
+// instrument records request count and latency per route.
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+		var (
+			start  = time.Now()
+			writer = &statusWriter{ResponseWriter: out, status: http.StatusOK}
+		)
+		next.ServeHTTP(writer, in)
+		var route = routePattern(in)
+		metrics.Requests.Inc(route, in.Method, strconv.Itoa(writer.status))
+		metrics.RequestDuration.Observe(time.Since(start).Seconds(), route, in.Method)
+	})
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func routePattern(in *http.Request) string {
+	var route = chi.RouteContext(in.Context()).RoutePattern()
+	if route == "" {
+		return "unmatched"
+	}
+	return route
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
 
@@ -49,9 +50,15 @@ func (e *Entry) post(out http.ResponseWriter, in *http.Request) {
 	var token, authenticateError = e.Gophkeeper.Authenticate(in.Context(), credential)
 	if authenticateError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(authenticateError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(authenticateError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to authenticate", "error", authenticateError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
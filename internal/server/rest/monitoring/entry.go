@@ -0,0 +1,52 @@
+package monitoring
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/metrics"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+)
+
+// Entry is health, readiness and metrics entry.
+type Entry struct {
+	Gophkeeper gophkeeper.Gophkeeper
+}
+
+// readiness is implemented by gophkeepers that can tell
+// whether they are ready to serve requests.
+type readiness interface {
+	Ready(context.Context) error
+}
+
+// Route routes monitoring entry.
+func (e *Entry) Route() http.Handler {
+	var router = chi.NewRouter()
+	router.Get("/healthz", e.health)
+	router.Get("/readyz", e.ready)
+	router.Handle("/metrics", metrics.Default)
+	return router
+}
+
+func (e *Entry) health(out http.ResponseWriter, in *http.Request) {
+	out.WriteHeader(http.StatusOK)
+	if _, err := out.Write(([]byte)("ok\n")); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
+
+func (e *Entry) ready(out http.ResponseWriter, in *http.Request) {
+	if gophkeeper, ok := e.Gophkeeper.(readiness); ok {
+		if err := gophkeeper.Ready(in.Context()); err != nil {
+			var status = http.StatusServiceUnavailable
+			http.Error(out, err.Error(), status)
+			return
+		}
+	}
+	out.WriteHeader(http.StatusOK)
+	if _, err := out.Write(([]byte)("ok\n")); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
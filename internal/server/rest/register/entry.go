@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
 
@@ -18,6 +19,7 @@ type Entry struct {
 func (e *Entry) Route() http.Handler {
 	var router = chi.NewRouter()
 	router.Post("/", e.post)
+	router.Get("/policy", e.policy)
 	return router
 }
 
@@ -46,17 +48,73 @@ func (e *Entry) post(out http.ResponseWriter, in *http.Request) {
 		return
 	}
 
+	if val, ok := requestBody["invite"]; ok {
+		if invite, ok := val.(string); ok {
+			credential.Invite = invite
+		} else {
+			var status = http.StatusBadRequest
+			http.Error(out, http.StatusText(status), status)
+			return
+		}
+	}
+
 	if err := e.Gophkeeper.Register(in.Context(), credential); err != nil {
+		var policyError *gophkeeper.PasswordPolicyError
+		if errors.As(err, &policyError) {
+			weakPassword(out, in, policyError)
+			return
+		}
 		var status = http.StatusInternalServerError
+		if errors.Is(err, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(err, gophkeeper.ErrBadCredential) {
 			status = http.StatusBadRequest
 		}
 		if errors.Is(err, gophkeeper.ErrIdentityDuplicate) {
 			status = http.StatusConflict
 		}
+		if errors.Is(err, gophkeeper.ErrBadInvite) {
+			status = http.StatusUnauthorized
+		}
+		if errors.Is(err, gophkeeper.ErrRegistrationClosed) {
+			status = http.StatusForbidden
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to register", "error", err)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
 
 	out.WriteHeader(http.StatusCreated)
 }
+
+func (e *Entry) policy(out http.ResponseWriter, in *http.Request) {
+	var policy, policyError = e.Gophkeeper.PasswordPolicy(in.Context())
+	if policyError != nil {
+		var status = http.StatusInternalServerError
+		if errors.Is(policyError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get password policy", "error", policyError)
+		}
+		http.Error(out, http.StatusText(status), status)
+		return
+	}
+	out.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(out).Encode(policy); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
+
+// weakPassword responds with violations of the password policy.
+func weakPassword(out http.ResponseWriter, in *http.Request, err *gophkeeper.PasswordPolicyError) {
+	out.Header().Set("Content-Type", "application/json")
+	out.WriteHeader(http.StatusUnprocessableEntity)
+	var content = map[string]any{"violations": err.Violations}
+	if err := json.NewEncoder(out).Encode(content); err != nil {
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
+	}
+}
@@ -0,0 +1,104 @@
+package register
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGophkeeper struct {
+	gophkeeper.Gophkeeper
+	closed  bool
+	invites map[string]bool
+	policy  gophkeeper.PasswordPolicy
+}
+
+func (g *fakeGophkeeper) PasswordPolicy(context.Context) (gophkeeper.PasswordPolicy, error) {
+	return g.policy, nil
+}
+
+func (g *fakeGophkeeper) Register(_ context.Context, credential gophkeeper.Credential) error {
+	if g.closed {
+		return gophkeeper.ErrRegistrationClosed
+	}
+	if err := g.policy.Check(credential.Username, credential.Password); err != nil {
+		return err
+	}
+	if !g.invites[credential.Invite] {
+		return gophkeeper.ErrBadInvite
+	}
+	delete(g.invites, credential.Invite)
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	var (
+		keeper = &fakeGophkeeper{invites: map[string]bool{"invite": true}}
+		entry  = Entry{Gophkeeper: keeper}
+		router = chi.NewRouter()
+	)
+	router.Mount("/register", entry.Route())
+	var server = httptest.NewServer(router)
+	defer server.Close()
+	var client = gophkeeper.RestGophkeeper{
+		Client: *server.Client(),
+		Server: server.URL,
+	}
+	var ctx = context.Background()
+
+	var credential = gophkeeper.Credential{Username: "alice", Password: "password"}
+	assert.ErrorIs(t, client.Register(ctx, credential), gophkeeper.ErrBadInvite)
+
+	credential.Invite = "invite"
+	assert.Nil(t, client.Register(ctx, credential))
+	assert.ErrorIs(t, client.Register(ctx, credential), gophkeeper.ErrBadInvite)
+
+	keeper.closed = true
+	assert.ErrorIs(t, client.Register(ctx, credential), gophkeeper.ErrRegistrationClosed)
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	var (
+		keeper = &fakeGophkeeper{
+			invites: map[string]bool{"invite": true},
+			policy:  gophkeeper.PasswordPolicy{MinLength: 8, RequireDigit: true, NoUsername: true},
+		}
+		entry  = Entry{Gophkeeper: keeper}
+		router = chi.NewRouter()
+	)
+	router.Mount("/register", entry.Route())
+	var server = httptest.NewServer(router)
+	defer server.Close()
+	var client = gophkeeper.RestGophkeeper{
+		Client: *server.Client(),
+		Server: server.URL,
+	}
+	var ctx = context.Background()
+
+	var policy, policyError = client.PasswordPolicy(ctx)
+	assert.Nil(t, policyError)
+	assert.Equal(t, keeper.policy, policy)
+
+	var credential = gophkeeper.Credential{Username: "alice", Password: "alice", Invite: "invite"}
+	var registerError = client.Register(ctx, credential)
+	assert.ErrorIs(t, registerError, gophkeeper.ErrWeakPassword)
+	var violations *gophkeeper.PasswordPolicyError
+	if assert.ErrorAs(t, registerError, &violations) {
+		var rules []gophkeeper.PasswordRule
+		for _, violation := range violations.Violations {
+			rules = append(rules, violation.Rule)
+		}
+		assert.Equal(t, []gophkeeper.PasswordRule{
+			gophkeeper.PasswordRuleMinLength,
+			gophkeeper.PasswordRuleDigit,
+			gophkeeper.PasswordRuleUsername,
+		}, rules)
+	}
+
+	credential.Password = "correct horse 1"
+	assert.Nil(t, client.Register(ctx, credential))
+}
@@ -2,20 +2,28 @@ package rest
 
 import (
 	"context"
-	"errors"
-	"log"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 	"github.com/pior/runnable"
-	"golang.org/x/crypto/acme/autocert"
 )
 
 // Rest is gophkeeper's rest API.
 type Rest struct {
-	Address       string // Address is the address that REST api serves at.
-	UseTLS        bool
-	HostWhilelist []string
+	Address    string // Address is the address that REST api serves at.
+	UseTLS     bool
+	TLS        TLS
+	Monitoring bool // Monitoring serves health and metrics endpoints along with the api.
+
+	ReadHeaderTimeout   time.Duration
+	ReadTimeout         time.Duration
+	WriteTimeout        time.Duration
+	IdleTimeout         time.Duration
+	MaxHeaderBytes      int
+	BlobTransferTimeout time.Duration // BlobTransferTimeout replaces read and write timeouts for blob routes.
+	ShutdownTimeout     time.Duration // ShutdownTimeout is how long in-flight requests may take to complete on shutdown.
 
 	Gophkeeper gophkeeper.Gophkeeper
 }
@@ -25,36 +33,33 @@ var _ runnable.Runnable = (*Rest)(nil)
 // Run implement runnable.Runnable for Rest.
 func (r *Rest) Run(ctx context.Context) error {
 	var (
+		drain drain
 		entry = Entry{
-			Gophkeeper: r.Gophkeeper,
+			Gophkeeper:          r.Gophkeeper,
+			Monitoring:          r.Monitoring,
+			BlobTransferTimeout: r.BlobTransferTimeout,
+			drain:               &drain,
 		}
 		server = http.Server{
-			Addr:    r.Address,
-			Handler: entry.Route(),
+			Addr:              r.Address,
+			Handler:           entry.Route(),
+			ReadHeaderTimeout: r.ReadHeaderTimeout,
+			ReadTimeout:       r.ReadTimeout,
+			WriteTimeout:      r.WriteTimeout,
+			IdleTimeout:       r.IdleTimeout,
+			MaxHeaderBytes:    r.MaxHeaderBytes,
 		}
-		serverErrorChannel = make(chan error)
 	)
-	go func() {
+	return serve(ctx, &server, &drain, r.ShutdownTimeout, func() error {
 		if r.UseTLS {
-			var certmanager = autocert.Manager{
-				Cache:      autocert.DirCache("cache"),
-				Prompt:     autocert.AcceptTOS,
-				HostPolicy: autocert.HostWhitelist(r.HostWhilelist...),
+			var config, configError = r.TLS.Config()
+			if configError != nil {
+				return configError
 			}
-			server.TLSConfig = certmanager.TLSConfig()
-			serverErrorChannel <- server.ListenAndServeTLS("", "")
-		} else {
-			log.Printf("\033[31m!CONNECTION IS NOT SECURED!\033[0m")
-			serverErrorChannel <- server.ListenAndServe()
-		}
-	}()
-	select {
-	case <-ctx.Done():
-		return server.Shutdown(ctx)
-	case err := <-serverErrorChannel:
-		if !errors.Is(err, http.ErrServerClosed) {
-			return err
+			server.TLSConfig = config
+			return server.ListenAndServeTLS("", "")
 		}
-		return nil
-	}
+		slog.Warn("connection is not secured, TLS is disabled")
+		return server.ListenAndServe()
+	})
 }
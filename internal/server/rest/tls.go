@@ -0,0 +1,253 @@
+package rest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	// certificateCheckInterval is how often certificate files are checked for changes.
+	certificateCheckInterval = time.Second
+
+	// selfSignedLifespan is how long a self-signed certificate is valid.
+	selfSignedLifespan = 365 * 24 * time.Hour
+)
+
+// TLS is configuration of the REST api's TLS.
+//
+// With CertFile and KeyFile set the certificate is served from the files
+// and reloaded when they change; if SelfSigned is set and the files do not
+// exist, a self-signed certificate is written to them first. With SelfSigned
+// set and no files, a self-signed certificate is kept in memory only.
+// Otherwise, the certificate is obtained from Let's Encrypt.
+type TLS struct {
+	CertFile   string
+	KeyFile    string
+	SelfSigned bool
+
+	CacheDir      string // CacheDir is where Let's Encrypt certificates are cached.
+	HostWhilelist []string
+
+	ClientCAFile string // ClientCAFile enables client certificate authentication against the CA bundle.
+}
+
+// Config returns tls.Config for the configuration.
+func (t *TLS) Config() (*tls.Config, error) {
+	var config *tls.Config
+	switch {
+	case t.CertFile != "" || t.KeyFile != "":
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("both certificate and key files must be set")
+		}
+		if t.SelfSigned {
+			if err := bootstrapCertificate(t.CertFile, t.KeyFile, t.HostWhilelist); err != nil {
+				return nil, err
+			}
+		}
+		var reloader, reloaderError = newCertificateReloader(t.CertFile, t.KeyFile)
+		if reloaderError != nil {
+			return nil, reloaderError
+		}
+		config = &tls.Config{
+			GetCertificate: reloader.GetCertificate,
+		}
+	case t.SelfSigned:
+		var certificate, key, certificateError = selfSignedCertificate(t.HostWhilelist)
+		if certificateError != nil {
+			return nil, certificateError
+		}
+		var pair, pairError = tls.X509KeyPair(certificate, key)
+		if pairError != nil {
+			return nil, pairError
+		}
+		config = &tls.Config{
+			Certificates: []tls.Certificate{pair},
+		}
+	default:
+		var cacheDir = t.CacheDir
+		if cacheDir == "" {
+			cacheDir = "cache"
+		}
+		var certmanager = autocert.Manager{
+			Cache:      autocert.DirCache(cacheDir),
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(t.HostWhilelist...),
+		}
+		config = certmanager.TLSConfig()
+	}
+	config.MinVersion = tls.VersionTLS12
+	if t.ClientCAFile != "" {
+		var bundle, bundleError = os.ReadFile(t.ClientCAFile)
+		if bundleError != nil {
+			return nil, fmt.Errorf("read client CA bundle: %w", bundleError)
+		}
+		var pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in %s", t.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// certificateReloader serves a certificate from files
+// and reloads it when the files change.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex       sync.Mutex
+	certificate *tls.Certificate
+	modified    time.Time
+	checked     time.Time
+}
+
+func newCertificateReloader(certFile, keyFile string) (*certificateReloader, error) {
+	var reloader = &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	var modified, modifiedError = reloader.modification()
+	if modifiedError != nil {
+		return nil, modifiedError
+	}
+	if err := reloader.load(modified); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// GetCertificate implements tls.Config's GetCertificate.
+func (r *certificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if time.Since(r.checked) < certificateCheckInterval {
+		return r.certificate, nil
+	}
+	r.checked = time.Now()
+	var modified, modifiedError = r.modification()
+	if modifiedError != nil {
+		slog.Error("failed to check certificate files", "error", modifiedError)
+		return r.certificate, nil
+	}
+	if !modified.Equal(r.modified) {
+		if err := r.load(modified); err != nil {
+			slog.Error("failed to reload certificate, keeping the previous one", "error", err)
+		} else {
+			slog.Info("certificate reloaded", "file", r.certFile)
+		}
+	}
+	return r.certificate, nil
+}
+
+func (r *certificateReloader) load(modified time.Time) error {
+	var certificate, certificateError = tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if certificateError != nil {
+		return fmt.Errorf("load certificate: %w", certificateError)
+	}
+	r.certificate = &certificate
+	r.modified = modified
+	return nil
+}
+
+// modification returns the latest modification time of the files.
+func (r *certificateReloader) modification() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		var info, statError = os.Stat(file)
+		if statError != nil {
+			return time.Time{}, statError
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// bootstrapCertificate writes a self-signed certificate
+// to the files unless the certificate file exists.
+func bootstrapCertificate(certFile, keyFile string, hosts []string) error {
+	if _, err := os.Stat(certFile); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	var certificate, key, certificateError = selfSignedCertificate(hosts)
+	if certificateError != nil {
+		return certificateError
+	}
+	if err := os.WriteFile(keyFile, key, 0600); err != nil {
+		return fmt.Errorf("write key: %w", err)
+	}
+	if err := os.WriteFile(certFile, certificate, 0644); err != nil {
+		return fmt.Errorf("write certificate: %w", err)
+	}
+	slog.Warn("generated a self-signed certificate", "file", certFile)
+	return nil
+}
+
+// selfSignedCertificate returns PEM encoded self-signed certificate and its key
+// valid for the hosts and the loopback addresses.
+func selfSignedCertificate(hosts []string) ([]byte, []byte, error) {
+	var key, keyError = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if keyError != nil {
+		return nil, nil, keyError
+	}
+	var serial, serialError = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if serialError != nil {
+		return nil, nil, serialError
+	}
+	var now = time.Now()
+	var template = x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"gophkeeper"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedLifespan),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		if host == "" {
+			continue
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	var der, derError = x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if derError != nil {
+		return nil, nil, derError
+	}
+	var keyDER, keyDERError = x509.MarshalECPrivateKey(key)
+	if keyDERError != nil {
+		return nil, nil, keyDERError
+	}
+	var (
+		certificate = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+		keyPEM      = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	)
+	return certificate, keyPEM, nil
+}
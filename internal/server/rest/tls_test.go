@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLS(t *testing.T) {
+	t.Run("Self-signed bootstrap is trusted with custom CA", func(t *testing.T) {
+		var (
+			dir      = t.TempDir()
+			certFile = filepath.Join(dir, "cert.pem")
+			config   = TLS{
+				CertFile:   certFile,
+				KeyFile:    filepath.Join(dir, "key.pem"),
+				SelfSigned: true,
+			}
+		)
+		var server = serveTLS(t, config)
+
+		var client, clientError = gophkeeper.ClientTLS{CAFile: certFile}.Client()
+		assert.Nil(t, clientError)
+		var response, responseError = client.Get(server)
+		if assert.Nil(t, responseError) {
+			response.Body.Close()
+			assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		}
+
+		var untrusted, untrustedError = gophkeeper.ClientTLS{}.Client()
+		assert.Nil(t, untrustedError)
+		_, responseError = untrusted.Get(server)
+		assert.NotNil(t, responseError)
+	})
+	t.Run("Reloads changed certificate", func(t *testing.T) {
+		var (
+			dir      = t.TempDir()
+			certFile = filepath.Join(dir, "cert.pem")
+			keyFile  = filepath.Join(dir, "key.pem")
+		)
+		assert.Nil(t, bootstrapCertificate(certFile, keyFile, nil))
+		var reloader, reloaderError = newCertificateReloader(certFile, keyFile)
+		if !assert.Nil(t, reloaderError) {
+			return
+		}
+		var before, _ = reloader.GetCertificate(nil)
+
+		assert.Nil(t, os.Remove(certFile))
+		assert.Nil(t, bootstrapCertificate(certFile, keyFile, nil))
+		var later = time.Now().Add(time.Minute)
+		assert.Nil(t, os.Chtimes(certFile, later, later))
+		reloader.checked = time.Time{}
+
+		var after, _ = reloader.GetCertificate(nil)
+		assert.NotEqual(t, before.Certificate[0], after.Certificate[0])
+	})
+	t.Run("Keeps certificate when reload fails", func(t *testing.T) {
+		var (
+			dir      = t.TempDir()
+			certFile = filepath.Join(dir, "cert.pem")
+			keyFile  = filepath.Join(dir, "key.pem")
+		)
+		assert.Nil(t, bootstrapCertificate(certFile, keyFile, nil))
+		var reloader, reloaderError = newCertificateReloader(certFile, keyFile)
+		if !assert.Nil(t, reloaderError) {
+			return
+		}
+		var before, _ = reloader.GetCertificate(nil)
+
+		assert.Nil(t, os.WriteFile(certFile, []byte("garbage"), 0644))
+		var later = time.Now().Add(time.Minute)
+		assert.Nil(t, os.Chtimes(certFile, later, later))
+		reloader.checked = time.Time{}
+
+		var after, afterError = reloader.GetCertificate(nil)
+		assert.Nil(t, afterError)
+		assert.Same(t, before, after)
+	})
+	t.Run("Requires client certificate with client CA", func(t *testing.T) {
+		var (
+			dir      = t.TempDir()
+			certFile = filepath.Join(dir, "cert.pem")
+			config   = TLS{
+				CertFile:     certFile,
+				KeyFile:      filepath.Join(dir, "key.pem"),
+				SelfSigned:   true,
+				ClientCAFile: certFile,
+			}
+		)
+		var server = serveTLS(t, config)
+
+		var client, clientError = gophkeeper.ClientTLS{CAFile: certFile}.Client()
+		assert.Nil(t, clientError)
+		var _, responseError = client.Get(server)
+		assert.NotNil(t, responseError)
+	})
+}
+
+func serveTLS(t *testing.T, config TLS) string {
+	t.Helper()
+	var tlsConfig, configError = config.Config()
+	if !assert.Nil(t, configError) {
+		t.FailNow()
+	}
+	var listener, listenError = net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, listenError) {
+		t.FailNow()
+	}
+	var server = http.Server{
+		Handler: http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+			out.WriteHeader(http.StatusNoContent)
+		}),
+		TLSConfig: tlsConfig,
+		ErrorLog:  log.New(io.Discard, "", 0),
+	}
+	go server.ServeTLS(listener, "", "")
+	t.Cleanup(func() { server.Close() })
+	return "https://" + listener.Addr().String()
+}
@@ -4,35 +4,66 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
 
 // Entry is blob entry.
 type Entry struct {
 	Gophkeeper gophkeeper.Gophkeeper
+
+	// TransferTimeout replaces server-wide read and write timeouts,
+	// zero means blob transfers have no deadline.
+	TransferTimeout time.Duration
 }
 
 // Route routes blob entry.
 func (e *Entry) Route() http.Handler {
 	var router = chi.NewRouter()
+	router.Use(e.transfer)
 	router.Put("/", e.encrypt)
 	router.Get("/{rid}", e.decrypt)
 	return router
 }
 
+// transfer extends read and write deadlines of blob requests,
+// as blob uploads and downloads take longer than other requests.
+func (e *Entry) transfer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(out http.ResponseWriter, in *http.Request) {
+		var deadline time.Time
+		if e.TransferTimeout > 0 {
+			deadline = time.Now().Add(e.TransferTimeout)
+		}
+		var controller = http.NewResponseController(out)
+		if err := controller.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logging.Logger(in.Context()).Error("failed to set read deadline", "error", err)
+		}
+		if err := controller.SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			logging.Logger(in.Context()).Error("failed to set write deadline", "error", err)
+		}
+		next.ServeHTTP(out, in)
+	})
+}
+
 func (e *Entry) encrypt(out http.ResponseWriter, in *http.Request) {
 	var token = in.Header.Get("Authorization")
 	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
 	if identityError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -51,9 +82,15 @@ func (e *Entry) encrypt(out http.ResponseWriter, in *http.Request) {
 	rid, storeError := identity.StoreBlob(in.Context(), blob, password)
 	if storeError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(storeError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(storeError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to store blob", "error", storeError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -64,7 +101,7 @@ func (e *Entry) encrypt(out http.ResponseWriter, in *http.Request) {
 	}
 	response.RID = (int64)(rid)
 	if err := json.NewEncoder(out).Encode(response); err != nil {
-		log.Printf("Failed to write response: %s", err.Error())
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
 	}
 }
 
@@ -73,9 +110,15 @@ func (e *Entry) decrypt(out http.ResponseWriter, in *http.Request) {
 	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
 	if identityError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -97,9 +140,15 @@ func (e *Entry) decrypt(out http.ResponseWriter, in *http.Request) {
 	var blob, restoreError = identity.RestoreBlob(in.Context(), (gophkeeper.ResourceID)(rid), password)
 	if restoreError != nil {
 		var status = http.StatusInternalServerError
-		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
+		if errors.Is(restoreError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(restoreError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to restore blob", "error", restoreError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -112,9 +161,12 @@ func (e *Entry) decrypt(out http.ResponseWriter, in *http.Request) {
 
 	var output = bufio.NewWriter(out)
 	if _, err := output.ReadFrom(blob.Content); err != nil {
-		log.Printf("failed to write content: %s", err.Error())
+		logging.Logger(in.Context()).Error("failed to write content", "error", err)
+		// The status is already sent, so the response is aborted for the client
+		// to see a broken stream instead of complete content, which may be corrupted.
+		panic(http.ErrAbortHandler)
 	}
 	if err := output.Flush(); err != nil {
-		log.Printf("failed to flush content: %s", err.Error())
+		logging.Logger(in.Context()).Error("failed to flush content", "error", err)
 	}
 }
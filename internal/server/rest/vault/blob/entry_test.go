@@ -0,0 +1,72 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+var errCorrupted = errors.New("blob checksum mismatch")
+
+type fakeGophkeeper struct {
+	gophkeeper.Gophkeeper
+	identity *fakeIdentity
+}
+
+func (g *fakeGophkeeper) Identity(_ context.Context, token gophkeeper.Token) (gophkeeper.Identity, error) {
+	if token != "token" {
+		return nil, gophkeeper.ErrBadCredential
+	}
+	return g.identity, nil
+}
+
+type fakeIdentity struct {
+	gophkeeper.Identity
+	content string
+}
+
+func (i *fakeIdentity) RestoreBlob(context.Context, gophkeeper.ResourceID, string) (gophkeeper.Blob, error) {
+	// The checksum of a corrupted blob fails at EOF, after its content is read.
+	var content = io.MultiReader(strings.NewReader(i.content), &failingReader{err: errCorrupted})
+	return gophkeeper.Blob{Meta: "meta", Content: io.NopCloser(content)}, nil
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r *failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestDecrypt(t *testing.T) {
+	var (
+		entry = Entry{
+			Gophkeeper: &fakeGophkeeper{identity: &fakeIdentity{content: strings.Repeat("corrupted", 1024)}},
+		}
+		router = chi.NewRouter()
+	)
+	router.Mount("/vault/blob", entry.Route())
+	var server = httptest.NewServer(router)
+	defer server.Close()
+	var identity = gophkeeper.RestIdentity{
+		Client: *server.Client(),
+		Server: server.URL,
+		Token:  "token",
+	}
+
+	t.Run("Corrupted blob aborts the response", func(t *testing.T) {
+		var blob, err = identity.RestoreBlob(context.Background(), 1, "password")
+		assert.NoError(t, err)
+		defer blob.Content.Close()
+		var _, readError = io.ReadAll(blob.Content)
+		assert.Error(t, readError, "expected the client to see a broken stream")
+	})
+}
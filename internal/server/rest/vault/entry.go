@@ -3,11 +3,12 @@ package vault
 import (
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
 	"github.com/kerelape/gophkeeper/internal/server/rest/vault/blob"
 	"github.com/kerelape/gophkeeper/internal/server/rest/vault/piece"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
@@ -16,6 +17,8 @@ import (
 // Entry is vault entry.
 type Entry struct {
 	Gophkeeper gophkeeper.Gophkeeper
+
+	BlobTransferTimeout time.Duration
 }
 
 // Route routes vault entry.
@@ -25,7 +28,8 @@ func (e *Entry) Route() http.Handler {
 			Gophkeeper: e.Gophkeeper,
 		}
 		blob = blob.Entry{
-			Gophkeeper: e.Gophkeeper,
+			Gophkeeper:      e.Gophkeeper,
+			TransferTimeout: e.BlobTransferTimeout,
 		}
 	)
 	var router = chi.NewRouter()
@@ -41,9 +45,15 @@ func (e *Entry) get(out http.ResponseWriter, in *http.Request) {
 	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
 	if identityError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -51,6 +61,12 @@ func (e *Entry) get(out http.ResponseWriter, in *http.Request) {
 	var resources, resourcesError = identity.List(in.Context())
 	if resourcesError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(resourcesError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to list resources", "error", resourcesError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -69,7 +85,7 @@ func (e *Entry) get(out http.ResponseWriter, in *http.Request) {
 
 	out.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(out).Encode(&response); err != nil {
-		log.Printf("failed to write response: %s\n", err.Error())
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
 	}
 }
 
@@ -78,9 +94,15 @@ func (e *Entry) delete(out http.ResponseWriter, in *http.Request) {
 	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
 	if identityError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -94,9 +116,15 @@ func (e *Entry) delete(out http.ResponseWriter, in *http.Request) {
 
 	if err := identity.Delete(in.Context(), (gophkeeper.ResourceID)(rid)); err != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(err, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(err, gophkeeper.ErrResourceNotFound) {
 			status = http.StatusNotFound
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to delete resource", "error", err)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -5,11 +5,11 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/kerelape/gophkeeper/internal/server/logging"
 	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 )
 
@@ -31,9 +31,15 @@ func (e *Entry) encrypt(out http.ResponseWriter, in *http.Request) {
 	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
 	if identityError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -67,9 +73,15 @@ func (e *Entry) encrypt(out http.ResponseWriter, in *http.Request) {
 	var rid, storeError = identity.StorePiece(in.Context(), piece, password)
 	if storeError != nil {
 		var status = http.StatusInternalServerError
-		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
+		if errors.Is(storeError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(storeError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to store piece", "error", storeError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -80,7 +92,7 @@ func (e *Entry) encrypt(out http.ResponseWriter, in *http.Request) {
 	}
 	response.RID = (int64)(rid)
 	if err := json.NewEncoder(out).Encode(&response); err != nil {
-		log.Printf("Failed to write response: %s", err.Error())
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
 	}
 }
 
@@ -89,9 +101,15 @@ func (e *Entry) decrypt(out http.ResponseWriter, in *http.Request) {
 	var identity, identityError = e.Gophkeeper.Identity(in.Context(), (gophkeeper.Token)(token))
 	if identityError != nil {
 		var status = http.StatusInternalServerError
+		if errors.Is(identityError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
 		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to get identity", "error", identityError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -112,9 +130,18 @@ func (e *Entry) decrypt(out http.ResponseWriter, in *http.Request) {
 	var piece, restoreError = identity.RestorePiece(in.Context(), (gophkeeper.ResourceID)(rid), password)
 	if restoreError != nil {
 		var status = http.StatusInternalServerError
-		if errors.Is(identityError, gophkeeper.ErrBadCredential) {
+		if errors.Is(restoreError, gophkeeper.ErrUnavailable) {
+			status = http.StatusServiceUnavailable
+		}
+		if errors.Is(restoreError, gophkeeper.ErrBadCredential) {
 			status = http.StatusUnauthorized
 		}
+		if errors.Is(restoreError, gophkeeper.ErrResourceCorrupted) {
+			status = http.StatusUnprocessableEntity
+		}
+		if status == http.StatusInternalServerError {
+			logging.Logger(in.Context()).Error("failed to restore piece", "error", restoreError)
+		}
 		http.Error(out, http.StatusText(status), status)
 		return
 	}
@@ -133,6 +160,6 @@ func (e *Entry) decrypt(out http.ResponseWriter, in *http.Request) {
 	)
 	out.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(out).Encode(response); err != nil {
-		log.Printf("Failed to write response: %s", err.Error())
+		logging.Logger(in.Context()).Error("failed to write response", "error", err)
 	}
 }
@@ -3,10 +3,12 @@ package server
 import (
 	"context"
 	"encoding/base64"
+	"regexp"
 	"time"
 
 	"github.com/kerelape/gophkeeper/internal/server/postgres"
 	"github.com/kerelape/gophkeeper/internal/server/rest"
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
 	"github.com/pior/runnable"
 )
 
@@ -15,16 +17,50 @@ type Server struct {
 	RestAddress       string // the address that REST api serves at.
 	RestUseTLS        bool
 	RestHostWhilelist []string
+	RestTLSCertFile   string
+	RestTLSKeyFile    string
+	RestTLSSelfSigned bool
+	RestTLSCacheDir   string // where Let's Encrypt certificates are cached.
+	RestTLSClientCA   string // CA bundle to verify client certificates against, empty disables mTLS.
+
+	RestReadHeaderTimeout   time.Duration
+	RestReadTimeout         time.Duration
+	RestWriteTimeout        time.Duration
+	RestIdleTimeout         time.Duration
+	RestMaxHeaderBytes      int
+	RestBlobTransferTimeout time.Duration
+	RestShutdownTimeout     time.Duration // how long in-flight requests may take to complete on shutdown.
+
+	AdminAddress string // the address of a separate health and metrics listener, empty to serve them with REST api.
+
+	DatabaseDSN                    string
+	DatabaseMaxConnections         int32
+	DatabaseStatementCacheCapacity int
+
+	BlobsDir           string
+	BlobsGCInterval    time.Duration
+	BlobsGCGracePeriod time.Duration
+	BlobsGCRemove      bool
 
-	DatabaseDSN   string
-	BlobsDir      string
 	TokenSecret   []byte
 	TokenLifespan time.Duration
 
+	BcryptCost    int
+	KeyIterations int
+
 	UsernameMinLength uint
-	PasswordMinLength uint
+	PasswordPolicy    gophkeeper.PasswordPolicy
+
+	RegistrationMode            postgres.RegistrationMode
+	RegistrationUsernamePattern *regexp.Regexp
+	RegistrationInviteLifespan  time.Duration
+	RegistrationUsersInvite     bool
 }
 
+// shutdownMargin is the time given to components to stop
+// after the REST api has been drained.
+const shutdownMargin = 5 * time.Second
+
 var _ runnable.Runnable = (*Server)(nil)
 
 // Run runs Server.
@@ -33,25 +69,76 @@ func (s *Server) Run(ctx context.Context) error {
 		gophkeeper = postgres.Gophkeeper{
 			PasswordEncoding: base64.RawStdEncoding,
 
-			DSN:      s.DatabaseDSN,
-			BlobsDir: s.BlobsDir,
+			DSN:                    s.DatabaseDSN,
+			MaxConnections:         s.DatabaseMaxConnections,
+			StatementCacheCapacity: s.DatabaseStatementCacheCapacity,
+			BlobsDir:               s.BlobsDir,
 
 			TokenSecret:   s.TokenSecret,
 			TokenLifespan: s.TokenLifespan,
 
+			BcryptCost:    s.BcryptCost,
+			KeyIterations: s.KeyIterations,
+
 			UsernameMinLength: s.UsernameMinLength,
-			PasswordMinLength: s.PasswordMinLength,
+			Passwords:         s.PasswordPolicy,
+			UsernamePattern:   s.RegistrationUsernamePattern,
+
+			Registration:   s.RegistrationMode,
+			InviteLifespan: s.RegistrationInviteLifespan,
+			UsersInvite:    s.RegistrationUsersInvite,
 		}
 		restDaemon = rest.Rest{
-			Address:       s.RestAddress,
-			Gophkeeper:    &gophkeeper,
-			UseTLS:        s.RestUseTLS,
-			HostWhilelist: s.RestHostWhilelist,
+			Address:    s.RestAddress,
+			Gophkeeper: &gophkeeper,
+			UseTLS:     s.RestUseTLS,
+			TLS: rest.TLS{
+				CertFile:      s.RestTLSCertFile,
+				KeyFile:       s.RestTLSKeyFile,
+				SelfSigned:    s.RestTLSSelfSigned,
+				CacheDir:      s.RestTLSCacheDir,
+				HostWhilelist: s.RestHostWhilelist,
+				ClientCAFile:  s.RestTLSClientCA,
+			},
+			Monitoring: s.AdminAddress == "",
+
+			ReadHeaderTimeout:   s.RestReadHeaderTimeout,
+			ReadTimeout:         s.RestReadTimeout,
+			WriteTimeout:        s.RestWriteTimeout,
+			IdleTimeout:         s.RestIdleTimeout,
+			MaxHeaderBytes:      s.RestMaxHeaderBytes,
+			BlobTransferTimeout: s.RestBlobTransferTimeout,
+			ShutdownTimeout:     s.RestShutdownTimeout,
 		}
 	)
 
-	var manager = runnable.NewManager()
+	// The database is a dependency of the listeners,
+	// so it stays open until in-flight requests are drained.
+	var manager = runnable.NewManager(
+		runnable.ManagerShutdownTimeout(s.RestShutdownTimeout + shutdownMargin),
+	)
 	manager.Add(&gophkeeper)
-	manager.Add(&restDaemon)
+	manager.Add(&restDaemon, &gophkeeper)
+	if s.AdminAddress != "" {
+		manager.Add(
+			&rest.Admin{
+				Address:           s.AdminAddress,
+				ReadHeaderTimeout: s.RestReadHeaderTimeout,
+				ShutdownTimeout:   s.RestShutdownTimeout,
+				Gophkeeper:        &gophkeeper,
+			},
+			&gophkeeper,
+		)
+	}
+	if s.BlobsGCInterval > 0 {
+		manager.Add(
+			&postgres.BlobCollector{
+				Gophkeeper:  &gophkeeper,
+				Interval:    s.BlobsGCInterval,
+				GracePeriod: s.BlobsGCGracePeriod,
+				Remove:      s.BlobsGCRemove,
+			},
+		)
+	}
 	return manager.Build().Run(ctx)
 }
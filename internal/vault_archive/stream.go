@@ -0,0 +1,141 @@
+package vaultarchive
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// chunkSize is the size of plaintext sealed at once.
+const chunkSize = 64 * 1024
+
+// sealer encrypts a stream in chunks, each authenticated with the header,
+// its position and whether it is the last one, so chunks can be neither
+// reordered nor cut off.
+type sealer struct {
+	aead    cipher.AEAD
+	out     io.Writer
+	header  []byte
+	buffer  []byte
+	counter uint64
+}
+
+func newSealer(aead cipher.AEAD, out io.Writer, header []byte) *sealer {
+	return &sealer{
+		aead:   aead,
+		out:    out,
+		header: header,
+		buffer: make([]byte, 0, chunkSize),
+	}
+}
+
+// Write implements io.Writer.
+func (s *sealer) Write(p []byte) (int, error) {
+	var written = 0
+	for len(p) > 0 {
+		// A full chunk is kept until more data comes,
+		// because the last chunk must be sealed as such.
+		if len(s.buffer) == chunkSize {
+			if err := s.seal(false); err != nil {
+				return written, err
+			}
+		}
+		var n = copy(s.buffer[len(s.buffer):chunkSize], p)
+		s.buffer = s.buffer[:len(s.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals the last chunk, it does not close the underlying writer.
+func (s *sealer) Close() error {
+	return s.seal(true)
+}
+
+func (s *sealer) seal(last bool) error {
+	var sealed = s.aead.Seal(nil, nonce(s.counter), s.buffer, additionalData(s.header, last))
+	s.counter++
+	s.buffer = s.buffer[:0]
+	_, err := s.out.Write(sealed)
+	return err
+}
+
+// opener decrypts a stream sealed by sealer.
+type opener struct {
+	aead    cipher.AEAD
+	in      *bufio.Reader
+	header  []byte
+	chunk   []byte
+	sealed  []byte
+	counter uint64
+	done    bool
+}
+
+func newOpener(aead cipher.AEAD, in io.Reader, header []byte) *opener {
+	return &opener{
+		aead:   aead,
+		in:     bufio.NewReader(in),
+		header: header,
+		sealed: make([]byte, chunkSize+aead.Overhead()),
+	}
+}
+
+// Read implements io.Reader.
+func (o *opener) Read(p []byte) (int, error) {
+	for len(o.chunk) == 0 {
+		if o.done {
+			return 0, io.EOF
+		}
+		if err := o.open(); err != nil {
+			return 0, err
+		}
+	}
+	var n = copy(p, o.chunk)
+	o.chunk = o.chunk[n:]
+	return n, nil
+}
+
+func (o *opener) open() error {
+	var n, readError = io.ReadFull(o.in, o.sealed)
+	var last bool
+	switch {
+	case readError == nil:
+		var _, peekError = o.in.Peek(1)
+		if peekError != nil && !errors.Is(peekError, io.EOF) {
+			return peekError
+		}
+		last = peekError != nil
+	case errors.Is(readError, io.ErrUnexpectedEOF):
+		last = true
+	case errors.Is(readError, io.EOF):
+		return ErrCorrupted
+	default:
+		return readError
+	}
+	var chunk, openError = o.aead.Open(o.sealed[:0], nonce(o.counter), o.sealed[:n], additionalData(o.header, last))
+	if openError != nil {
+		return ErrCorrupted
+	}
+	o.counter++
+	o.chunk = chunk
+	o.done = last
+	return nil
+}
+
+func nonce(counter uint64) []byte {
+	var nonce = make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], counter)
+	return nonce
+}
+
+func additionalData(header []byte, last bool) []byte {
+	var data = make([]byte, len(header)+1)
+	copy(data, header)
+	if last {
+		data[len(header)] = 1
+	}
+	return data
+}
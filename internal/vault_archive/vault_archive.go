@@ -0,0 +1,341 @@
+// Package vaultarchive reads and writes encrypted vault archives.
+//
+// An archive starts with a plain header: magic, format version, PBKDF2
+// iterations and salt. The rest is sealed with AES-256-GCM in chunks
+// with a key derived from the passphrase and holds the manifest followed
+// by the content of each manifest entry in order. Content is written in
+// frames, so blobs are streamed, and ends with its SHA-256 digest and size.
+package vaultarchive
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Version is the format version written.
+const Version = 1
+
+// KeyIterations is the number of PBKDF2 iterations deriving the key of new archives.
+const KeyIterations = 600_000
+
+const (
+	magic            = "GKVAULT\x00"
+	saltLen          = 16
+	keyLen           = 32
+	headerLen        = len(magic) + 2 + 4 + saltLen
+	minKeyIterations = 1000
+	// maxKeyIterations bounds the work a crafted header can cause.
+	maxKeyIterations = 16 * KeyIterations
+	maxManifestSize  = 64 << 20
+	frameSize        = 32 * 1024
+	trailerLen       = sha256.Size + 8
+)
+
+var (
+	// ErrNotArchive is returned when the input is not a vault archive.
+	ErrNotArchive = errors.New("not a vault archive")
+
+	// ErrUnsupportedVersion is returned when the archive was written
+	// with a newer format version.
+	ErrUnsupportedVersion = errors.New("unsupported vault archive version")
+
+	// ErrCorrupted is returned when the archive was modified or cut off,
+	// or the passphrase is wrong.
+	ErrCorrupted = errors.New("vault archive is corrupted or the passphrase is wrong")
+)
+
+// IsArchive tells whether the content begins like an archive.
+func IsArchive(head []byte) bool {
+	return bytes.HasPrefix(head, ([]byte)(magic))
+}
+
+// Manifest describes the archive.
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Username  string    `json:"username"` // Username is the identity the vault was exported from.
+	Entries   []Entry   `json:"entries"`
+}
+
+// Entry is metadata of an archived resource.
+type Entry struct {
+	ID   gophkeeper.ResourceID   `json:"id"` // ID is the resource's ID on the exporting server.
+	Type gophkeeper.ResourceType `json:"type"`
+	Meta string                  `json:"meta"`
+}
+
+// Writer writes an archive.
+type Writer struct {
+	sealer   *sealer
+	manifest Manifest
+	written  int
+}
+
+// NewWriter writes the header and the manifest of an archive to out,
+// the content of every manifest entry must be written next.
+func NewWriter(out io.Writer, passphrase string, manifest Manifest) (*Writer, error) {
+	var header = make([]byte, headerLen)
+	copy(header, magic)
+	binary.BigEndian.PutUint16(header[len(magic):], Version)
+	binary.BigEndian.PutUint32(header[len(magic)+2:], KeyIterations)
+	if _, err := rand.Read(header[len(magic)+6:]); err != nil {
+		return nil, err
+	}
+	var aead, aeadError = newAEAD(passphrase, header)
+	if aeadError != nil {
+		return nil, aeadError
+	}
+	if _, err := out.Write(header); err != nil {
+		return nil, err
+	}
+
+	var content, marshalError = json.Marshal(manifest)
+	if marshalError != nil {
+		return nil, marshalError
+	}
+	var w = &Writer{
+		sealer:   newSealer(aead, out, header),
+		manifest: manifest,
+	}
+	if err := w.frame(content); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// WriteEntry writes content of the next manifest entry.
+func (w *Writer) WriteEntry(content io.Reader) error {
+	if w.written >= len(w.manifest.Entries) {
+		return errors.New("all manifest entries are written")
+	}
+	var (
+		digest = sha256.New()
+		buffer = make([]byte, frameSize)
+		size   uint64
+	)
+	for {
+		var n, readError = content.Read(buffer)
+		if n > 0 {
+			digest.Write(buffer[:n])
+			size += (uint64)(n)
+			if err := w.frame(buffer[:n]); err != nil {
+				return err
+			}
+		}
+		if errors.Is(readError, io.EOF) {
+			break
+		}
+		if readError != nil {
+			return readError
+		}
+	}
+	if err := w.frame(nil); err != nil {
+		return err
+	}
+	var trailer = digest.Sum(nil)
+	trailer = binary.BigEndian.AppendUint64(trailer, size)
+	if _, err := w.sealer.Write(trailer); err != nil {
+		return err
+	}
+	w.written++
+	return nil
+}
+
+// Close finishes the archive, it does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.written != len(w.manifest.Entries) {
+		return fmt.Errorf("%d of %d manifest entries are written", w.written, len(w.manifest.Entries))
+	}
+	return w.sealer.Close()
+}
+
+func (w *Writer) frame(content []byte) error {
+	var length = binary.BigEndian.AppendUint32(nil, (uint32)(len(content)))
+	if _, err := w.sealer.Write(length); err != nil {
+		return err
+	}
+	_, err := w.sealer.Write(content)
+	return err
+}
+
+// Reader reads an archive.
+type Reader struct {
+	opener   *opener
+	manifest Manifest
+	next     int
+	content  *Content
+}
+
+// NewReader reads the header and the manifest of an archive from in.
+func NewReader(in io.Reader, passphrase string) (*Reader, error) {
+	var header = make([]byte, headerLen)
+	if _, err := io.ReadFull(in, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNotArchive
+		}
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(magic)], ([]byte)(magic)) {
+		return nil, ErrNotArchive
+	}
+	if version := binary.BigEndian.Uint16(header[len(magic):]); version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	if iterations := binary.BigEndian.Uint32(header[len(magic)+2:]); iterations < minKeyIterations || iterations > maxKeyIterations {
+		return nil, ErrCorrupted
+	}
+	var aead, aeadError = newAEAD(passphrase, header)
+	if aeadError != nil {
+		return nil, aeadError
+	}
+
+	var r = &Reader{opener: newOpener(aead, in, header)}
+	var length, lengthError = r.frameLength()
+	if lengthError != nil {
+		return nil, lengthError
+	}
+	if length > maxManifestSize {
+		return nil, ErrCorrupted
+	}
+	var content = make([]byte, length)
+	if _, err := io.ReadFull(r.opener, content); err != nil {
+		return nil, corrupted(err)
+	}
+	if err := json.Unmarshal(content, &r.manifest); err != nil {
+		return nil, ErrCorrupted
+	}
+	return r, nil
+}
+
+// Manifest returns the manifest of the archive.
+func (r *Reader) Manifest() Manifest {
+	return r.manifest
+}
+
+// Next returns the next entry and its content, skipping the rest
+// of the previous content, and io.EOF after the last entry.
+func (r *Reader) Next() (Entry, *Content, error) {
+	if r.content != nil {
+		if _, err := io.Copy(io.Discard, r.content); err != nil {
+			return Entry{}, nil, err
+		}
+	}
+	if r.next >= len(r.manifest.Entries) {
+		var extra, extraError = r.opener.Read(make([]byte, 1))
+		if extra > 0 || !errors.Is(extraError, io.EOF) {
+			return Entry{}, nil, corrupted(extraError)
+		}
+		return Entry{}, nil, io.EOF
+	}
+	var entry = r.manifest.Entries[r.next]
+	r.next++
+	r.content = &Content{reader: r, digest: sha256.New()}
+	return entry, r.content, nil
+}
+
+func (r *Reader) frameLength() (uint32, error) {
+	var length = make([]byte, 4)
+	if _, err := io.ReadFull(r.opener, length); err != nil {
+		return 0, corrupted(err)
+	}
+	return binary.BigEndian.Uint32(length), nil
+}
+
+// Content is content of an archive entry, its digest
+// and size are verified when it is read to the end.
+type Content struct {
+	reader *Reader
+	digest hash.Hash
+	size   uint64
+	frame  uint32
+	done   bool
+}
+
+var _ io.Reader = (*Content)(nil)
+
+// Read implements io.Reader.
+func (c *Content) Read(p []byte) (int, error) {
+	for c.frame == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		var length, lengthError = c.reader.frameLength()
+		if lengthError != nil {
+			return 0, lengthError
+		}
+		if length > frameSize {
+			return 0, ErrCorrupted
+		}
+		if length == 0 {
+			if err := c.verify(); err != nil {
+				return 0, err
+			}
+			c.done = true
+			return 0, io.EOF
+		}
+		c.frame = length
+	}
+	if (uint32)(len(p)) > c.frame {
+		p = p[:c.frame]
+	}
+	var n, readError = c.reader.opener.Read(p)
+	c.digest.Write(p[:n])
+	c.size += (uint64)(n)
+	c.frame -= (uint32)(n)
+	if errors.Is(readError, io.EOF) {
+		return n, ErrCorrupted
+	}
+	return n, readError
+}
+
+// Sum returns the SHA-256 digest of the content read.
+func (c *Content) Sum() []byte {
+	return c.digest.Sum(nil)
+}
+
+func (c *Content) verify() error {
+	var trailer = make([]byte, trailerLen)
+	if _, err := io.ReadFull(c.reader.opener, trailer); err != nil {
+		return corrupted(err)
+	}
+	if !bytes.Equal(trailer[:sha256.Size], c.digest.Sum(nil)) {
+		return ErrCorrupted
+	}
+	if binary.BigEndian.Uint64(trailer[sha256.Size:]) != c.size {
+		return ErrCorrupted
+	}
+	return nil
+}
+
+func newAEAD(passphrase string, header []byte) (cipher.AEAD, error) {
+	var (
+		iterations = binary.BigEndian.Uint32(header[len(magic)+2:])
+		salt       = header[len(magic)+6:]
+		key        = pbkdf2.Key(([]byte)(passphrase), salt, (int)(iterations), keyLen, sha256.New)
+	)
+	var block, blockError = aes.NewCipher(key)
+	if blockError != nil {
+		return nil, blockError
+	}
+	return cipher.NewGCM(block)
+}
+
+// corrupted turns an unexpected end of the archive into ErrCorrupted.
+func corrupted(err error) error {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrCorrupted
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package vaultarchive
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/kerelape/gophkeeper/pkg/gophkeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchive(t *testing.T) {
+	var (
+		manifest = Manifest{
+			CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Username:  "alice",
+			Entries: []Entry{
+				{ID: 1, Type: gophkeeper.ResourceTypePiece, Meta: `{"type":0}`},
+				{ID: 2, Type: gophkeeper.ResourceTypeBlob, Meta: `{"type":2}`},
+				{ID: 3, Type: gophkeeper.ResourceTypePiece, Meta: `{"type":1}`},
+			},
+		}
+		contents = [][]byte{
+			([]byte)("secret"),
+			bytes.Repeat(([]byte)("blob"), chunkSize),
+			{},
+		}
+	)
+	var archive bytes.Buffer
+	var writer, writerError = NewWriter(&archive, "passphrase", manifest)
+	assert.Nil(t, writerError)
+	for _, content := range contents {
+		assert.Nil(t, writer.WriteEntry(bytes.NewReader(content)))
+	}
+	assert.Nil(t, writer.Close())
+	assert.False(t, bytes.Contains(archive.Bytes(), ([]byte)("secret")))
+	assert.False(t, bytes.Contains(archive.Bytes(), ([]byte)("alice")))
+
+	t.Run("Read", func(t *testing.T) {
+		var reader, readerError = NewReader(bytes.NewReader(archive.Bytes()), "passphrase")
+		assert.Nil(t, readerError)
+		assert.Equal(t, manifest, reader.Manifest())
+		for i := range contents {
+			var entry, content, nextError = reader.Next()
+			assert.Nil(t, nextError)
+			assert.Equal(t, manifest.Entries[i], entry)
+			var data, readError = io.ReadAll(content)
+			assert.Nil(t, readError)
+			assert.Equal(t, contents[i], data)
+			var digest = sha256.Sum256(contents[i])
+			assert.Equal(t, digest[:], content.Sum())
+		}
+		var _, _, nextError = reader.Next()
+		assert.ErrorIs(t, nextError, io.EOF)
+	})
+	t.Run("Skip", func(t *testing.T) {
+		var reader, readerError = NewReader(bytes.NewReader(archive.Bytes()), "passphrase")
+		assert.Nil(t, readerError)
+		var count = 0
+		for {
+			var _, _, nextError = reader.Next()
+			if errors.Is(nextError, io.EOF) {
+				break
+			}
+			assert.Nil(t, nextError)
+			count++
+		}
+		assert.Equal(t, len(contents), count)
+	})
+	t.Run("WrongPassphrase", func(t *testing.T) {
+		var _, readerError = NewReader(bytes.NewReader(archive.Bytes()), "wrong")
+		assert.ErrorIs(t, readerError, ErrCorrupted)
+	})
+	t.Run("Tampered", func(t *testing.T) {
+		var tampered = bytes.Clone(archive.Bytes())
+		tampered[len(tampered)/2] ^= 1
+		assert.ErrorIs(t, readAll(tampered), ErrCorrupted)
+	})
+	t.Run("Truncated", func(t *testing.T) {
+		assert.ErrorIs(t, readAll(archive.Bytes()[:headerLen+chunkSize+16]), ErrCorrupted)
+		assert.ErrorIs(t, readAll(archive.Bytes()[:archive.Len()-1]), ErrCorrupted)
+	})
+	t.Run("Iterations", func(t *testing.T) {
+		for _, iterations := range []uint32{minKeyIterations - 1, maxKeyIterations + 1, math.MaxUint32} {
+			var crafted = bytes.Clone(archive.Bytes())
+			binary.BigEndian.PutUint32(crafted[len(magic)+2:], iterations)
+			var _, readerError = NewReader(bytes.NewReader(crafted), "passphrase")
+			assert.ErrorIs(t, readerError, ErrCorrupted)
+		}
+	})
+	t.Run("NotArchive", func(t *testing.T) {
+		var _, readerError = NewReader(bytes.NewReader(([]byte)("username,password\n")), "passphrase")
+		assert.ErrorIs(t, readerError, ErrNotArchive)
+	})
+	t.Run("Incomplete", func(t *testing.T) {
+		var writer, writerError = NewWriter(io.Discard, "passphrase", manifest)
+		assert.Nil(t, writerError)
+		assert.NotNil(t, writer.Close())
+	})
+}
+
+func readAll(archive []byte) error {
+	var reader, readerError = NewReader(bytes.NewReader(archive), "passphrase")
+	if readerError != nil {
+		return readerError
+	}
+	for {
+		var _, content, nextError = reader.Next()
+		if errors.Is(nextError, io.EOF) {
+			return nil
+		}
+		if nextError != nil {
+			return nextError
+		}
+		if _, err := io.Copy(io.Discard, content); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package gophkeeper
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrForbidden indicates that the identity is not allowed to do that.
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrIdentityNotFound indicates that there is no such identity.
+	ErrIdentityNotFound = errors.New("identity not found")
+)
+
+// IdentityInfo is information about an identity and its usage.
+type IdentityInfo struct {
+	Username  string
+	Admin     bool
+	Disabled  bool
+	CreatedAt time.Time
+	LastLogin time.Time // LastLogin is zero if the identity has never logged in.
+
+	Pieces     int
+	Blobs      int
+	PieceBytes int64 // PieceBytes is the size of encrypted pieces.
+	BlobBytes  int64 // BlobBytes is the size of blobs, except ones stored before it was recorded.
+}
+
+// Administration is administration of gophkeeper's identities.
+type Administration interface {
+	// Identities returns all identities.
+	Identities(context.Context) ([]IdentityInfo, error)
+
+	// SetDisabled disables or enables the identity,
+	// a disabled identity can neither log in nor use its tokens.
+	SetDisabled(ctx context.Context, username string, disabled bool) error
+
+	// Logout invalidates all tokens of the identity.
+	Logout(ctx context.Context, username string) error
+
+	// Delete deletes the identity and all of its resources.
+	Delete(ctx context.Context, username string) error
+
+	// ResetCredential sets a new password of the identity and logs it out.
+	// Resources are encrypted with the password, so they are deleted.
+	ResetCredential(ctx context.Context, username, password string) error
+}
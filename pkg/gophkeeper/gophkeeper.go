@@ -3,6 +3,8 @@ package gophkeeper
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
@@ -14,8 +16,36 @@ var (
 
 	// ErrIdentityDuplicate indecates that there is already such an identity.
 	ErrIdentityDuplicate = errors.New("identity already exists")
+
+	// ErrRegistrationClosed indicates that gophkeeper does not register new identities.
+	ErrRegistrationClosed = errors.New("registration is closed")
+
+	// ErrBadInvite indicates that the invite is missing, expired or already used.
+	ErrBadInvite = errors.New("bad invite")
+
+	// ErrUnavailable indicates that gophkeeper is temporarily unavailable
+	// and the request may be retried later.
+	ErrUnavailable = errors.New("gophkeeper is temporarily unavailable")
 )
 
+// UnavailableError is ErrUnavailable with a hint on when to retry.
+type UnavailableError struct {
+	RetryAfter time.Duration
+}
+
+// Error implements error.
+func (e *UnavailableError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s, retry after %s", ErrUnavailable.Error(), e.RetryAfter)
+	}
+	return ErrUnavailable.Error()
+}
+
+// Is makes UnavailableError match ErrUnavailable.
+func (e *UnavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
 type (
 	// Token is a JWT token.
 	Token string
@@ -24,6 +54,13 @@ type (
 	Credential struct {
 		Username string
 		Password string
+		Invite   string // Invite is an invite token, required by invite-only registration.
+	}
+
+	// Invite is a single-use invite to register a new identity.
+	Invite struct {
+		Token     string
+		ExpiresAt time.Time
 	}
 )
 
@@ -37,4 +74,12 @@ type Gophkeeper interface {
 
 	// Identity returns the identity associated with the token.
 	Identity(context.Context, Token) (Identity, error)
+
+	// PasswordPolicy returns the policy of identities' passwords,
+	// its denylist is not shared.
+	PasswordPolicy(context.Context) (PasswordPolicy, error)
+
+	// Administration returns administration for the token
+	// of an admin identity, ErrForbidden otherwise.
+	Administration(context.Context, Token) (Administration, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"time"
 )
 
 type (
@@ -43,10 +44,34 @@ type Resource struct {
 	Meta string
 }
 
+// Profile is information about an identity and its usage.
+type Profile struct {
+	Username  string
+	CreatedAt time.Time
+	LastLogin time.Time // LastLogin is zero if the identity has never logged in.
+
+	Resources  map[ResourceType]int // Resources is the number of resources of each type.
+	PieceBytes int64                // PieceBytes is the size of encrypted pieces.
+	BlobBytes  int64                // BlobBytes is the size of blobs, except ones stored before it was recorded.
+
+	Security Security
+}
+
+// Security is security features enabled for an identity.
+type Security struct {
+	Admin         bool // Admin tells whether the identity has the admin role.
+	Invites       bool // Invites tells whether the identity may create invites.
+	KeyIterations int  // KeyIterations is the number of PBKDF2 iterations for new resources.
+}
+
 var (
 	// ErrResourceNotFound is returned when there is no
 	// resource with the ResourceID (or it's owned by another identity).
 	ErrResourceNotFound = errors.New("resource not found")
+
+	// ErrResourceCorrupted is returned when the resource fails
+	// authentication on decryption, its stored data is damaged.
+	ErrResourceCorrupted = errors.New("resource cannot be decrypted")
 )
 
 // Identity is a gophkeeper's identity.
@@ -68,4 +93,15 @@ type Identity interface {
 
 	// List returns list of all stored resources.
 	List(context.Context) ([]Resource, error)
+
+	// DeleteIdentity deletes the identity with all of its resources
+	// and invalidates its tokens, the password must be confirmed.
+	DeleteIdentity(ctx context.Context, password string) error
+
+	// Profile returns information about the identity and its usage.
+	Profile(context.Context) (Profile, error)
+
+	// Invite creates an invite to register a new identity,
+	// ErrForbidden if the identity is not allowed to invite.
+	Invite(context.Context) (Invite, error)
 }
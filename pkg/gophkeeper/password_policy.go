@@ -0,0 +1,223 @@
+package gophkeeper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrWeakPassword indicates that the password violates the password policy.
+var ErrWeakPassword = errors.New("password violates the password policy")
+
+// MaxPasswordBytes is the longest password that can be hashed,
+// it is enforced regardless of the policy.
+const MaxPasswordBytes = 72
+
+// PasswordRule is a rule of PasswordPolicy.
+type PasswordRule string
+
+const (
+	// PasswordRuleMinLength requires PasswordPolicy.MinLength characters.
+	PasswordRuleMinLength PasswordRule = "min_length"
+	// PasswordRuleMaxLength allows at most PasswordPolicy.MaxLength characters.
+	PasswordRuleMaxLength PasswordRule = "max_length"
+	// PasswordRuleMinEntropy requires PasswordPolicy.MinEntropy bits of estimated entropy.
+	PasswordRuleMinEntropy PasswordRule = "min_entropy"
+	// PasswordRuleLower requires a lowercase letter.
+	PasswordRuleLower PasswordRule = "lower"
+	// PasswordRuleUpper requires an uppercase letter.
+	PasswordRuleUpper PasswordRule = "upper"
+	// PasswordRuleDigit requires a digit.
+	PasswordRuleDigit PasswordRule = "digit"
+	// PasswordRuleSymbol requires a character that is neither a letter nor a digit.
+	PasswordRuleSymbol PasswordRule = "symbol"
+	// PasswordRuleUsername rejects passwords containing the username.
+	PasswordRuleUsername PasswordRule = "username"
+	// PasswordRuleDenylist rejects commonly used passwords.
+	PasswordRuleDenylist PasswordRule = "denylist"
+)
+
+// PasswordViolation is a password rule that is not satisfied.
+type PasswordViolation struct {
+	Rule    PasswordRule `json:"rule"`
+	Message string       `json:"message"`
+}
+
+// PasswordPolicyError lists violations of the password policy.
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+// Error implements error.
+func (e *PasswordPolicyError) Error() string {
+	var messages = make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrWeakPassword.Error(), strings.Join(messages, "; "))
+}
+
+// Is makes PasswordPolicyError match ErrWeakPassword.
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// PasswordPolicy is requirements for identities' passwords.
+//
+// Zero PasswordPolicy accepts any password up to MaxPasswordBytes.
+type PasswordPolicy struct {
+	MinLength     int     `json:"min_length"`
+	MaxLength     int     `json:"max_length"`  // MaxLength is zero for no limit.
+	MinEntropy    float64 `json:"min_entropy"` // MinEntropy is in bits, see PasswordEntropy.
+	RequireLower  bool    `json:"require_lower"`
+	RequireUpper  bool    `json:"require_upper"`
+	RequireDigit  bool    `json:"require_digit"`
+	RequireSymbol bool    `json:"require_symbol"`
+	NoUsername    bool    `json:"no_username"`
+
+	// Denylist is lowercase common passwords, it is not shared with clients.
+	Denylist map[string]struct{} `json:"-"`
+}
+
+// Check checks the password of the username, returning
+// *PasswordPolicyError with every violated rule.
+func (p *PasswordPolicy) Check(username, password string) error {
+	var violations []PasswordViolation
+	var violate = func(rule PasswordRule, format string, args ...any) {
+		violations = append(violations, PasswordViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	var length = utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violate(PasswordRuleMinLength, "must be at least %d characters long", p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violate(PasswordRuleMaxLength, "must be at most %d characters long", p.MaxLength)
+	} else if len(password) > MaxPasswordBytes {
+		violate(PasswordRuleMaxLength, "must be at most %d bytes long", MaxPasswordBytes)
+	}
+	if entropy := PasswordEntropy(password); entropy < p.MinEntropy {
+		violate(PasswordRuleMinEntropy, "is too predictable (%.0f of %.0f bits)", entropy, p.MinEntropy)
+	}
+
+	var classes = passwordClasses(password)
+	if p.RequireLower && !classes.lower {
+		violate(PasswordRuleLower, "must contain a lowercase letter")
+	}
+	if p.RequireUpper && !classes.upper {
+		violate(PasswordRuleUpper, "must contain an uppercase letter")
+	}
+	if p.RequireDigit && !classes.digit {
+		violate(PasswordRuleDigit, "must contain a digit")
+	}
+	if p.RequireSymbol && !classes.symbol {
+		violate(PasswordRuleSymbol, "must contain a symbol")
+	}
+
+	if p.NoUsername && username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		violate(PasswordRuleUsername, "must not contain the username")
+	}
+	if _, ok := p.Denylist[strings.ToLower(password)]; ok {
+		violate(PasswordRuleDenylist, "is too common")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// PasswordEntropy returns a rough estimate of the password's entropy in bits:
+// the length times log2 of the size of the character classes used,
+// with repeated characters counted as half a character.
+func PasswordEntropy(password string) float64 {
+	if password == "" {
+		return 0
+	}
+	var (
+		classes = passwordClasses(password)
+		pool    float64
+	)
+	if classes.lower {
+		pool += 26
+	}
+	if classes.upper {
+		pool += 26
+	}
+	if classes.digit {
+		pool += 10
+	}
+	if classes.symbol {
+		pool += 33
+	}
+	if classes.other {
+		pool += 100
+	}
+	var (
+		seen   = make(map[rune]struct{})
+		length float64
+	)
+	for _, r := range password {
+		if _, ok := seen[r]; ok {
+			length += 0.5
+			continue
+		}
+		seen[r] = struct{}{}
+		length++
+	}
+	return length * math.Log2(pool)
+}
+
+type characterClasses struct {
+	lower, upper, digit, symbol, other bool
+}
+
+func passwordClasses(password string) characterClasses {
+	var classes characterClasses
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			classes.lower = true
+		case r >= 'A' && r <= 'Z':
+			classes.upper = true
+		case r >= '0' && r <= '9':
+			classes.digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			classes.symbol = true
+		case unicode.IsLower(r):
+			classes.lower, classes.other = true, true
+		case unicode.IsUpper(r):
+			classes.upper, classes.other = true, true
+		case unicode.IsDigit(r):
+			classes.digit, classes.other = true, true
+		default:
+			classes.symbol, classes.other = true, true
+		}
+	}
+	return classes
+}
+
+// ReadPasswordDenylist reads a denylist of one password per line,
+// ignoring empty lines and lines starting with #.
+func ReadPasswordDenylist(in io.Reader) (map[string]struct{}, error) {
+	var (
+		denylist = make(map[string]struct{})
+		scanner  = bufio.NewScanner(in)
+	)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		denylist[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return denylist, nil
+}
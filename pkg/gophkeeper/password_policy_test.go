@@ -0,0 +1,65 @@
+package gophkeeper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy(t *testing.T) {
+	var rules = func(err error) []PasswordRule {
+		var policyError *PasswordPolicyError
+		if !errors.As(err, &policyError) {
+			return nil
+		}
+		var rules []PasswordRule
+		for _, violation := range policyError.Violations {
+			rules = append(rules, violation.Rule)
+		}
+		return rules
+	}
+
+	t.Run("Zero", func(t *testing.T) {
+		var policy PasswordPolicy
+		assert.Nil(t, policy.Check("alice", ""))
+		assert.Equal(t,
+			[]PasswordRule{PasswordRuleMaxLength},
+			rules(policy.Check("alice", strings.Repeat("a", MaxPasswordBytes+1))),
+		)
+	})
+	t.Run("Length", func(t *testing.T) {
+		var policy = PasswordPolicy{MinLength: 4, MaxLength: 6}
+		assert.Equal(t, []PasswordRule{PasswordRuleMinLength}, rules(policy.Check("", "abc")))
+		assert.Nil(t, policy.Check("", "пароль"))
+		assert.Equal(t, []PasswordRule{PasswordRuleMaxLength}, rules(policy.Check("", "abcdefg")))
+	})
+	t.Run("Classes", func(t *testing.T) {
+		var policy = PasswordPolicy{RequireLower: true, RequireUpper: true, RequireDigit: true, RequireSymbol: true}
+		assert.Equal(t,
+			[]PasswordRule{PasswordRuleUpper, PasswordRuleDigit, PasswordRuleSymbol},
+			rules(policy.Check("", "password")),
+		)
+		assert.Nil(t, policy.Check("", "Pa55-word"))
+	})
+	t.Run("Username", func(t *testing.T) {
+		var policy = PasswordPolicy{NoUsername: true}
+		assert.Equal(t, []PasswordRule{PasswordRuleUsername}, rules(policy.Check("Alice", "xaliCE1")))
+		assert.Nil(t, policy.Check("alice", "bob"))
+	})
+	t.Run("Denylist", func(t *testing.T) {
+		var denylist, readError = ReadPasswordDenylist(strings.NewReader("# common\nPassword\n\nqwerty\n"))
+		assert.Nil(t, readError)
+		var policy = PasswordPolicy{Denylist: denylist}
+		assert.ErrorIs(t, policy.Check("", "PASSWORD"), ErrWeakPassword)
+		assert.Nil(t, policy.Check("", "# common"))
+	})
+	t.Run("Entropy", func(t *testing.T) {
+		var policy = PasswordPolicy{MinEntropy: 40}
+		assert.Equal(t, []PasswordRule{PasswordRuleMinEntropy}, rules(policy.Check("", "aaaaaaaaaa")))
+		assert.Nil(t, policy.Check("", "kX9#mQ2!vL"))
+		assert.Less(t, PasswordEntropy("aaaa"), PasswordEntropy("abcd"))
+		assert.Zero(t, PasswordEntropy(""))
+	})
+}
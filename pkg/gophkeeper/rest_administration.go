@@ -0,0 +1,153 @@
+package gophkeeper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RestAdministration is rest administration.
+type RestAdministration struct {
+	Client http.Client
+	Server string
+	Token  Token
+}
+
+var _ Administration = (*RestAdministration)(nil)
+
+// Identities implements Administration.
+func (a *RestAdministration) Identities(ctx context.Context) ([]IdentityInfo, error) {
+	var response, responseError = a.do(ctx, http.MethodGet, "/admin/identities", nil)
+	if responseError != nil {
+		return nil, responseError
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, administrationError(response)
+	}
+	var content []struct {
+		Username   string     `json:"username"`
+		Admin      bool       `json:"admin"`
+		Disabled   bool       `json:"disabled"`
+		CreatedAt  time.Time  `json:"created_at"`
+		LastLogin  *time.Time `json:"last_login"`
+		Pieces     int        `json:"pieces"`
+		Blobs      int        `json:"blobs"`
+		PieceBytes int64      `json:"piece_bytes"`
+		BlobBytes  int64      `json:"blob_bytes"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&content); err != nil {
+		return nil, errors.Join(
+			fmt.Errorf("parse response: %w", err),
+			ErrIncompatibleAPI,
+		)
+	}
+	var identities = make([]IdentityInfo, 0, len(content))
+	for _, c := range content {
+		var identity = IdentityInfo{
+			Username:   c.Username,
+			Admin:      c.Admin,
+			Disabled:   c.Disabled,
+			CreatedAt:  c.CreatedAt,
+			Pieces:     c.Pieces,
+			Blobs:      c.Blobs,
+			PieceBytes: c.PieceBytes,
+			BlobBytes:  c.BlobBytes,
+		}
+		if c.LastLogin != nil {
+			identity.LastLogin = *c.LastLogin
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
+
+// SetDisabled implements Administration.
+func (a *RestAdministration) SetDisabled(ctx context.Context, username string, disabled bool) error {
+	var action = "enable"
+	if disabled {
+		action = "disable"
+	}
+	return a.act(ctx, http.MethodPost, identityPath(username)+"/"+action, nil)
+}
+
+// Logout implements Administration.
+func (a *RestAdministration) Logout(ctx context.Context, username string) error {
+	return a.act(ctx, http.MethodPost, identityPath(username)+"/logout", nil)
+}
+
+// Delete implements Administration.
+func (a *RestAdministration) Delete(ctx context.Context, username string) error {
+	return a.act(ctx, http.MethodDelete, identityPath(username), nil)
+}
+
+// ResetCredential implements Administration.
+func (a *RestAdministration) ResetCredential(ctx context.Context, username, password string) error {
+	var content = map[string]any{
+		"password": password,
+	}
+	return a.act(ctx, http.MethodPut, identityPath(username)+"/password", content)
+}
+
+// act sends a request that has no response content.
+func (a *RestAdministration) act(ctx context.Context, method, path string, content any) error {
+	var response, responseError = a.do(ctx, method, path, content)
+	if responseError != nil {
+		return responseError
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return administrationError(response)
+	}
+	return nil
+}
+
+func (a *RestAdministration) do(ctx context.Context, method, path string, content any) (*http.Response, error) {
+	var body io.Reader
+	if content != nil {
+		var encoded, marshalError = json.Marshal(content)
+		if marshalError != nil {
+			return nil, marshalError
+		}
+		body = bytes.NewReader(encoded)
+	}
+	var request, requestError = http.NewRequestWithContext(ctx, method, a.Server+path, body)
+	if requestError != nil {
+		return nil, requestError
+	}
+	request.Header.Set("Authorization", (string)(a.Token))
+	return a.Client.Do(request)
+}
+
+func identityPath(username string) string {
+	return "/admin/identities/" + url.PathEscape(username)
+}
+
+// administrationError returns the error of an unsuccessful response.
+func administrationError(response *http.Response) error {
+	switch response.StatusCode {
+	case http.StatusBadRequest, http.StatusUnauthorized:
+		return ErrBadCredential
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrIdentityNotFound
+	case http.StatusUnprocessableEntity:
+		return passwordPolicyError(response)
+	case http.StatusServiceUnavailable:
+		return unavailable(response)
+	case http.StatusInternalServerError:
+		return serverError(response)
+	default:
+		return errors.Join(
+			fmt.Errorf("unexpected response status: %d", response.StatusCode),
+			ErrIncompatibleAPI,
+		)
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // ErrIncompatibleAPI is returns when API is not compatible with implementation.
@@ -23,12 +25,14 @@ var _ Gophkeeper = (*RestGophkeeper)(nil)
 // Register implements Gophkeeper.
 func (g *RestGophkeeper) Register(ctx context.Context, credential Credential) error {
 	var endpoint = fmt.Sprintf("%s/register", g.Server)
-	var content, marshalError = json.Marshal(
-		map[string]any{
-			"username": credential.Username,
-			"password": credential.Password,
-		},
-	)
+	var body = map[string]any{
+		"username": credential.Username,
+		"password": credential.Password,
+	}
+	if credential.Invite != "" {
+		body["invite"] = credential.Invite
+	}
+	var content, marshalError = json.Marshal(body)
 	if marshalError != nil {
 		return marshalError
 	}
@@ -48,6 +52,18 @@ func (g *RestGophkeeper) Register(ctx context.Context, credential Credential) er
 	switch response.StatusCode {
 	case http.StatusConflict:
 		return ErrIdentityDuplicate
+	case http.StatusBadRequest:
+		return ErrBadCredential
+	case http.StatusUnprocessableEntity:
+		return passwordPolicyError(response)
+	case http.StatusUnauthorized:
+		return ErrBadInvite
+	case http.StatusForbidden:
+		return ErrRegistrationClosed
+	case http.StatusServiceUnavailable:
+		return unavailable(response)
+	case http.StatusInternalServerError:
+		return serverError(response)
 	case http.StatusCreated:
 		return nil
 	default:
@@ -86,6 +102,8 @@ func (g *RestGophkeeper) Authenticate(ctx context.Context, credential Credential
 	switch response.StatusCode {
 	case http.StatusUnauthorized:
 		return (Token)(""), ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return (Token)(""), unavailable(response)
 	case http.StatusOK:
 		var token = response.Header.Get("Authorization")
 		return (Token)(token), nil
@@ -106,3 +124,74 @@ func (g *RestGophkeeper) Identity(_ context.Context, token Token) (Identity, err
 	}
 	return identity, nil
 }
+
+// PasswordPolicy implements Gophkeeper.
+func (g *RestGophkeeper) PasswordPolicy(ctx context.Context) (PasswordPolicy, error) {
+	var endpoint = fmt.Sprintf("%s/register/policy", g.Server)
+	var request, requestError = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet, endpoint,
+		nil,
+	)
+	if requestError != nil {
+		return PasswordPolicy{}, requestError
+	}
+	var response, getError = g.Client.Do(request)
+	if getError != nil {
+		return PasswordPolicy{}, getError
+	}
+	defer response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		var policy PasswordPolicy
+		if err := json.NewDecoder(response.Body).Decode(&policy); err != nil {
+			return PasswordPolicy{}, errors.Join(
+				fmt.Errorf("parse response: %w", err),
+				ErrIncompatibleAPI,
+			)
+		}
+		return policy, nil
+	case http.StatusServiceUnavailable:
+		return PasswordPolicy{}, unavailable(response)
+	case http.StatusInternalServerError:
+		return PasswordPolicy{}, serverError(response)
+	default:
+		return PasswordPolicy{}, errors.Join(
+			fmt.Errorf("unexpected response status: %d", response.StatusCode),
+			ErrIncompatibleAPI,
+		)
+	}
+}
+
+// passwordPolicyError returns PasswordPolicyError of the response.
+func passwordPolicyError(response *http.Response) error {
+	var content struct {
+		Violations []PasswordViolation `json:"violations"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&content); err != nil {
+		return errors.Join(
+			fmt.Errorf("parse response: %w", err),
+			ErrIncompatibleAPI,
+		)
+	}
+	return &PasswordPolicyError{Violations: content.Violations}
+}
+
+// Administration implements Gophkeeper.
+func (g *RestGophkeeper) Administration(_ context.Context, token Token) (Administration, error) {
+	var administration = &RestAdministration{
+		Client: g.Client,
+		Server: g.Server,
+		Token:  token,
+	}
+	return administration, nil
+}
+
+// unavailable returns UnavailableError with the response's Retry-After.
+func unavailable(response *http.Response) error {
+	var err = &UnavailableError{}
+	if seconds, parseError := strconv.Atoi(response.Header.Get("Retry-After")); parseError == nil {
+		err.RetryAfter = (time.Duration)(seconds) * time.Second
+	}
+	return err
+}
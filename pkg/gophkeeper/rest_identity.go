@@ -8,11 +8,21 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // ErrServerIsDown is returns when server returned an internal server error.
 var ErrServerIsDown = errors.New("server is down")
 
+// serverError returns ErrServerIsDown with the request ID of the response,
+// so that the failure can be found in the server's logs.
+func serverError(response *http.Response) error {
+	if id := response.Header.Get("X-Request-ID"); id != "" {
+		return errors.Join(ErrServerIsDown, fmt.Errorf("request id: %s", id))
+	}
+	return ErrServerIsDown
+}
+
 // RestIdentity is rest identity.
 type RestIdentity struct {
 	Client http.Client
@@ -63,8 +73,10 @@ func (i *RestIdentity) StorePiece(ctx context.Context, piece Piece, password str
 		return content.RID, nil
 	case http.StatusUnauthorized:
 		return -1, ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return -1, unavailable(response)
 	case http.StatusInternalServerError:
-		return -1, ErrServerIsDown
+		return -1, serverError(response)
 	default:
 		return -1, errors.Join(
 			fmt.Errorf("unexpected response status: %d", response.StatusCode),
@@ -127,8 +139,12 @@ func (i *RestIdentity) RestorePiece(ctx context.Context, rid ResourceID, passwor
 		return piece, nil
 	case http.StatusUnauthorized:
 		return Piece{}, ErrBadCredential
+	case http.StatusUnprocessableEntity:
+		return Piece{}, ErrResourceCorrupted
+	case http.StatusServiceUnavailable:
+		return Piece{}, unavailable(response)
 	case http.StatusInternalServerError:
-		return Piece{}, ErrServerIsDown
+		return Piece{}, serverError(response)
 	default:
 		return Piece{}, errors.Join(
 			fmt.Errorf("unexpected response status: %d", response.StatusCode),
@@ -168,8 +184,10 @@ func (i *RestIdentity) StoreBlob(ctx context.Context, blob Blob, password string
 		return content.RID, nil
 	case http.StatusUnauthorized:
 		return -1, ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return -1, unavailable(response)
 	case http.StatusInternalServerError:
-		return -1, ErrServerIsDown
+		return -1, serverError(response)
 	default:
 		return -1, errors.Join(
 			fmt.Errorf("unexpected response status: %d", response.StatusCode),
@@ -205,8 +223,10 @@ func (i *RestIdentity) RestoreBlob(ctx context.Context, rid ResourceID, password
 		return blob, nil
 	case http.StatusUnauthorized:
 		return Blob{}, ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return Blob{}, unavailable(response)
 	case http.StatusInternalServerError:
-		return Blob{}, ErrServerIsDown
+		return Blob{}, serverError(response)
 	default:
 		return Blob{}, errors.Join(
 			fmt.Errorf("unexpected response status: %d", response.StatusCode),
@@ -236,8 +256,10 @@ func (i *RestIdentity) Delete(ctx context.Context, rid ResourceID) error {
 	switch response.StatusCode {
 	case http.StatusOK:
 		return nil
+	case http.StatusServiceUnavailable:
+		return unavailable(response)
 	case http.StatusInternalServerError:
-		return ErrServerIsDown
+		return serverError(response)
 	case http.StatusNotFound:
 		return ErrResourceNotFound
 	default:
@@ -248,6 +270,167 @@ func (i *RestIdentity) Delete(ctx context.Context, rid ResourceID) error {
 	}
 }
 
+// DeleteIdentity implements Identity.
+func (i *RestIdentity) DeleteIdentity(ctx context.Context, password string) error {
+	var endpoint = fmt.Sprintf("%s/identity", i.Server)
+	var request, requestError = http.NewRequestWithContext(
+		ctx,
+		http.MethodDelete, endpoint,
+		nil,
+	)
+	if requestError != nil {
+		return requestError
+	}
+	request.Header.Set("Authorization", (string)(i.Token))
+	request.Header.Set("X-Password", password)
+
+	response, responseError := i.Client.Do(request)
+	if responseError != nil {
+		return responseError
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized:
+		return ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return unavailable(response)
+	case http.StatusInternalServerError:
+		return serverError(response)
+	default:
+		return errors.Join(
+			fmt.Errorf("unexpected response code: %d", response.StatusCode),
+			ErrIncompatibleAPI,
+		)
+	}
+}
+
+// Profile implements Identity.
+func (i *RestIdentity) Profile(ctx context.Context) (Profile, error) {
+	var endpoint = fmt.Sprintf("%s/identity", i.Server)
+	var request, requestError = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet, endpoint,
+		nil,
+	)
+	if requestError != nil {
+		return Profile{}, requestError
+	}
+	request.Header.Set("Authorization", (string)(i.Token))
+
+	response, responseError := i.Client.Do(request)
+	if responseError != nil {
+		return Profile{}, responseError
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		var content struct {
+			Username  string     `json:"username"`
+			CreatedAt time.Time  `json:"created_at"`
+			LastLogin *time.Time `json:"last_login"`
+			Resources struct {
+				Pieces int `json:"pieces"`
+				Blobs  int `json:"blobs"`
+			} `json:"resources"`
+			PieceBytes int64 `json:"piece_bytes"`
+			BlobBytes  int64 `json:"blob_bytes"`
+			Security   struct {
+				Admin         bool `json:"admin"`
+				Invites       bool `json:"invites"`
+				KeyIterations int  `json:"key_iterations"`
+			} `json:"security"`
+		}
+		if err := json.NewDecoder(response.Body).Decode(&content); err != nil {
+			return Profile{}, errors.Join(
+				fmt.Errorf("parse response: %w", err),
+				ErrIncompatibleAPI,
+			)
+		}
+		var profile = Profile{
+			Username:  content.Username,
+			CreatedAt: content.CreatedAt,
+			Resources: map[ResourceType]int{
+				ResourceTypePiece: content.Resources.Pieces,
+				ResourceTypeBlob:  content.Resources.Blobs,
+			},
+			PieceBytes: content.PieceBytes,
+			BlobBytes:  content.BlobBytes,
+			Security: Security{
+				Admin:         content.Security.Admin,
+				Invites:       content.Security.Invites,
+				KeyIterations: content.Security.KeyIterations,
+			},
+		}
+		if content.LastLogin != nil {
+			profile.LastLogin = *content.LastLogin
+		}
+		return profile, nil
+	case http.StatusUnauthorized:
+		return Profile{}, ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return Profile{}, unavailable(response)
+	case http.StatusInternalServerError:
+		return Profile{}, serverError(response)
+	default:
+		return Profile{}, errors.Join(
+			fmt.Errorf("unexpected response code: %d", response.StatusCode),
+			ErrIncompatibleAPI,
+		)
+	}
+}
+
+// Invite implements Identity.
+func (i *RestIdentity) Invite(ctx context.Context) (Invite, error) {
+	var endpoint = fmt.Sprintf("%s/identity/invites", i.Server)
+	var request, requestError = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost, endpoint,
+		nil,
+	)
+	if requestError != nil {
+		return Invite{}, requestError
+	}
+	request.Header.Set("Authorization", (string)(i.Token))
+
+	response, responseError := i.Client.Do(request)
+	if responseError != nil {
+		return Invite{}, responseError
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusCreated:
+		var content struct {
+			Token     string    `json:"token"`
+			ExpiresAt time.Time `json:"expires_at"`
+		}
+		if err := json.NewDecoder(response.Body).Decode(&content); err != nil {
+			return Invite{}, errors.Join(
+				fmt.Errorf("parse response: %w", err),
+				ErrIncompatibleAPI,
+			)
+		}
+		return Invite{Token: content.Token, ExpiresAt: content.ExpiresAt}, nil
+	case http.StatusUnauthorized:
+		return Invite{}, ErrBadCredential
+	case http.StatusForbidden:
+		return Invite{}, ErrForbidden
+	case http.StatusServiceUnavailable:
+		return Invite{}, unavailable(response)
+	case http.StatusInternalServerError:
+		return Invite{}, serverError(response)
+	default:
+		return Invite{}, errors.Join(
+			fmt.Errorf("unexpected response code: %d", response.StatusCode),
+			ErrIncompatibleAPI,
+		)
+	}
+}
+
 // List implements Identity.
 func (i *RestIdentity) List(ctx context.Context) ([]Resource, error) {
 	var endpoint = fmt.Sprintf("%s/vault", i.Server)
@@ -294,8 +477,10 @@ func (i *RestIdentity) List(ctx context.Context) ([]Resource, error) {
 		return resources, nil
 	case http.StatusUnauthorized:
 		return nil, ErrBadCredential
+	case http.StatusServiceUnavailable:
+		return nil, unavailable(response)
 	case http.StatusInternalServerError:
-		return nil, ErrServerIsDown
+		return nil, serverError(response)
 	default:
 		return nil, errors.Join(
 			fmt.Errorf("unexpected response code: %d", response.StatusCode),
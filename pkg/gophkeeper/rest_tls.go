@@ -0,0 +1,60 @@
+package gophkeeper
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// ClientTLS is TLS configuration of RestGophkeeper's client.
+type ClientTLS struct {
+	CAFile   string // CAFile is a PEM CA bundle trusted in addition to the system roots.
+	CertFile string // CertFile is a PEM client certificate for servers requiring mTLS.
+	KeyFile  string // KeyFile is a PEM private key of the client certificate.
+}
+
+// Config returns tls.Config for the configuration.
+func (c ClientTLS) Config() (*tls.Config, error) {
+	var config = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		var bundle, bundleError = os.ReadFile(c.CAFile)
+		if bundleError != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", bundleError)
+		}
+		var pool, poolError = x509.SystemCertPool()
+		if poolError != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("both client certificate and key files must be set")
+		}
+		var certificate, certificateError = tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if certificateError != nil {
+			return nil, fmt.Errorf("load client certificate: %w", certificateError)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
+
+// Client returns http.Client using the configuration.
+func (c ClientTLS) Client() (http.Client, error) {
+	var config, configError = c.Config()
+	if configError != nil {
+		return http.Client{}, configError
+	}
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config
+	return http.Client{Transport: transport}, nil
+}